@@ -0,0 +1,178 @@
+// avldecode decodes a packet captured off the wire, pasted from a log or a ticket, without a device
+// or a receiver. It prints each frame's protocol level struct, the statuses and responses the
+// receiver would make of it, its checksum and what's left over after the last frame.
+//
+//	avldecode [-protocol auto] [-in auto] 000F333536333037303432343431303133
+//	avldecode -file packet.bin
+//	echo AAAAAAAAADYIAQAAAWN... | avldecode -in base64
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/404minds/avl-receiver/internal/decode"
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	devices "github.com/404minds/avl-receiver/internal/protocols"
+	"github.com/404minds/avl-receiver/internal/types"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// parseInput turns the input into the packet's bytes. auto tries hex, then base64, then takes the
+// input as it is, which is what the text protocols need.
+func parseInput(input []byte, format string) ([]byte, error) {
+	switch format {
+	case "binary":
+		return input, nil
+	case "hex":
+		return hex.DecodeString(compactHex(input))
+	case "base64":
+		return base64.StdEncoding.DecodeString(string(bytes.Join(bytes.Fields(input), nil)))
+	case "auto":
+		if data, err := hex.DecodeString(compactHex(input)); err == nil && len(data) > 0 {
+			return data, nil
+		}
+		if data, err := base64.StdEncoding.DecodeString(string(bytes.Join(bytes.Fields(input), nil))); err == nil && len(data) > 0 {
+			return data, nil
+		}
+		return textInput(input), nil
+	}
+	return nil, fmt.Errorf("unknown input format %s", format)
+}
+
+// compactHex drops the whitespace, 0x prefixes and separators of hex dumps like 00 0f, 0x00,0x0f or 00:0f
+func compactHex(input []byte) string {
+	s := strings.NewReplacer("0x", "", "0X", "", ",", "", ":", "", "-", "").Replace(string(input))
+	return strings.Join(strings.Fields(s), "")
+}
+
+// textInput ends a pasted line with the newline the text protocols wait for
+func textInput(input []byte) []byte {
+	if len(input) > 0 && input[len(input)-1] != '\n' {
+		return append(input, '\n')
+	}
+	return input
+}
+
+// protocolFor is the named protocol, or the detected one for auto
+func protocolFor(name string, data []byte) (types.DeviceProtocolType, error) {
+	if name == "auto" {
+		protocolType, ok := devices.Detect(data)
+		if !ok {
+			return 0, errors.New("can't detect the protocol, pass -protocol")
+		}
+		return protocolType, nil
+	}
+	value, ok := types.DeviceProtocolType_value[strings.ToUpper(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown protocol %s", name)
+	}
+	return types.DeviceProtocolType(value), nil
+}
+
+// printFrame prints a frame and tells if its checksum is valid
+func printFrame(w io.Writer, n int, frame *decode.Frame) bool {
+	valid := true
+	fmt.Fprintf(w, "frame %d: %s, %d bytes", n, frame.Kind, frame.Size)
+	if c := frame.Checksum; c != nil {
+		if valid = c.Valid(); valid {
+			fmt.Fprintf(w, ", %s ok (%#x)", c.Name, c.Sent)
+		} else {
+			fmt.Fprintf(w, ", %s BAD (sent %#x, calculated %#x)", c.Name, c.Sent, c.Calculated)
+		}
+	}
+	fmt.Fprintln(w)
+
+	if frame.Packet != nil {
+		data, err := json.MarshalIndent(frame.Packet, "", "  ")
+		if err != nil {
+			fmt.Fprintf(w, "packet not printable: %v\n", err)
+		} else {
+			fmt.Fprintf(w, "packet %T: %s\n", frame.Packet, data)
+		}
+	}
+	for _, status := range frame.Statuses {
+		printMessage(w, "status", status)
+	}
+	for _, response := range frame.Responses {
+		printMessage(w, "response", response)
+	}
+	return valid
+}
+
+func printMessage(w io.Writer, name string, m proto.Message) {
+	data, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(m)
+	if err != nil {
+		// e.g. a timestamp out of range, which is likely what's being looked for
+		fmt.Fprintf(w, "%s not printable: %v\n", name, err)
+		return
+	}
+	fmt.Fprintf(w, "%s: %s\n", name, data)
+}
+
+func main() {
+	protocolName := flag.String("protocol", "auto", "Protocol of the packet, e.g. FM1200 or GT06, or auto to detect it")
+	inputFormat := flag.String("in", "auto", "Format of the input - one of auto, hex, base64 or binary")
+	file := flag.String("file", "", "File to read the packet from, the arguments or stdin if empty")
+	logLevel := flag.String("logLevel", "fatal", "Log level of the protocols - one of debug, info, warn or error, their errors are printed after the frames anyway")
+	flag.Parse()
+
+	if err := configuredLogger.Configure(configuredLogger.Config{Level: *logLevel, Encoding: "console"}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var input []byte
+	var err error
+	switch {
+	case *file != "":
+		input, err = os.ReadFile(*file)
+	case flag.NArg() > 0:
+		input = []byte(strings.Join(flag.Args(), " "))
+	default:
+		input, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	data, err := parseInput(input, *inputFormat)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "bad %s input: %v\n", *inputFormat, err)
+		os.Exit(2)
+	}
+	protocolType, err := protocolFor(*protocolName, data)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	fmt.Printf("protocol %s, %d bytes\n", protocolType, len(data))
+	frames, rest, err := devices.DecodeFrames(protocolType, data)
+	failed := false
+	for i, frame := range frames {
+		if !printFrame(os.Stdout, i+1, frame) {
+			failed = true
+		}
+	}
+	if len(rest) > 0 {
+		fmt.Printf("leftover %d bytes: %x\n", len(rest), rest)
+		failed = true
+	}
+	if err != nil {
+		fmt.Printf("error: %v\n", err)
+		failed = true
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
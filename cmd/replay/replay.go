@@ -0,0 +1,360 @@
+// replay feeds captures taken with the receiver's -captureDir back through the device's protocol
+// and prints the decoded statuses and responses as json lines, or diffs them against a golden file.
+//
+//	replay [-protocol GT06] [-golden] [-update] [-ignore timestamp] capture.avlcap...
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/404minds/avl-receiver/internal/capture"
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	devices "github.com/404minds/avl-receiver/internal/protocols"
+	"github.com/404minds/avl-receiver/internal/types"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// line is one line of the replay's output, exactly one of the fields is set
+type line struct {
+	Status   json.RawMessage `json:"status,omitempty"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Error    string          `json:"error,omitempty"` // how the stream ended if not cleanly, or a message that can't be printed
+}
+
+// collectingStore keeps what the protocol decodes in the order it was decoded
+type collectingStore struct {
+	processChan       chan *types.DeviceStatus
+	responseChan      chan *types.DeviceResponse
+	closeChan         chan bool
+	closeResponseChan chan bool
+
+	messages []proto.Message
+	done     chan struct{}
+}
+
+func newCollectingStore() *collectingStore {
+	s := &collectingStore{
+		// unbuffered so the order across the two channels is the order of the sends
+		processChan:       make(chan *types.DeviceStatus),
+		responseChan:      make(chan *types.DeviceResponse),
+		closeChan:         make(chan bool, 1),
+		closeResponseChan: make(chan bool, 1),
+		done:              make(chan struct{}),
+	}
+	go s.collect()
+	return s
+}
+
+func (s *collectingStore) collect() {
+	defer close(s.done)
+	for {
+		select {
+		case status := <-s.processChan:
+			s.messages = append(s.messages, status)
+		case response := <-s.responseChan:
+			s.messages = append(s.messages, response)
+		case <-s.closeChan:
+			return
+		}
+	}
+}
+
+// stop returns the collected messages once the protocol is done sending
+func (s *collectingStore) stop() []proto.Message {
+	s.closeChan <- true
+	<-s.done
+	return s.messages
+}
+
+func (s *collectingStore) Process(ctx context.Context)                 {}
+func (s *collectingStore) Response(ctx context.Context)                {}
+func (s *collectingStore) GetProcessChan() chan *types.DeviceStatus    { return s.processChan }
+func (s *collectingStore) GetResponseChan() chan *types.DeviceResponse { return s.responseChan }
+func (s *collectingStore) GetCloseChan() chan bool                     { return s.closeChan }
+func (s *collectingStore) GetCloseResponseChan() chan bool             { return s.closeResponseChan }
+
+type result struct {
+	messages []proto.Message
+	err      error  // the login or stream error, nil if the stream ended cleanly
+	acks     []byte // what the protocol wrote back to the device
+}
+
+// replay runs the inbound traffic of a capture through a fresh protocol the way the tcp handler does,
+// minus verifying the device
+func replay(c *capture.Capture, protocolType types.DeviceProtocolType) (res result) {
+	protocol := devices.MakeProtocolForType(protocolType)
+	if protocol == nil {
+		res.err = fmt.Errorf("unsupported protocol %s", protocolType)
+		return res
+	}
+	dataStore := newCollectingStore()
+	var acks bytes.Buffer
+	defer func() {
+		// a panic is reported like an error, after what was decoded before it
+		if r := recover(); r != nil {
+			res.err = fmt.Errorf("panic: %v", r)
+		}
+		res.messages = dataStore.stop()
+		res.acks = acks.Bytes()
+	}()
+
+	reader := bufio.NewReader(bytes.NewReader(c.Inbound()))
+	ack, bytesToSkip, err := protocol.Login(reader)
+	if err != nil {
+		res.err = fmt.Errorf("login: %w", err)
+		return res
+	}
+	acks.Write(ack)
+	if _, err := reader.Discard(bytesToSkip); err != nil {
+		res.err = fmt.Errorf("login: %w", err)
+		return res
+	}
+
+	err = protocol.ConsumeStream(reader, &acks, dataStore)
+	if err != nil && !errors.Is(err, io.EOF) {
+		res.err = err
+	}
+	return res
+}
+
+func (r result) lines() []line {
+	var lines []line
+	for _, m := range r.messages {
+		data, err := protojson.Marshal(m)
+		if err != nil {
+			// e.g. a timestamp out of range, which is what's being looked for
+			lines = append(lines, line{Error: fmt.Sprintf("%s not marshalable: %v", m.ProtoReflect().Descriptor().Name(), err)})
+			continue
+		}
+		switch m.(type) {
+		case *types.DeviceStatus:
+			lines = append(lines, line{Status: data})
+		case *types.DeviceResponse:
+			lines = append(lines, line{Response: data})
+		}
+	}
+	if r.err != nil {
+		lines = append(lines, line{Error: r.err.Error()})
+	}
+	return lines
+}
+
+func writeLines(w io.Writer, lines []line) error {
+	enc := json.NewEncoder(w)
+	for _, l := range lines {
+		if err := enc.Encode(l); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readLines(path string) ([]line, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var lines []line
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var l line
+		if err := json.Unmarshal(scanner.Bytes(), &l); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		lines = append(lines, l)
+	}
+	return lines, scanner.Err()
+}
+
+// sameLine compares the decoded messages rather than the json, so golden files survive field
+// reordering, with the ignored fields cleared on both sides
+func sameLine(want, got line, ignore map[string]bool) bool {
+	if want.Error != got.Error {
+		return false
+	}
+	switch {
+	case want.Status != nil && got.Status != nil:
+		return sameMessage(want.Status, got.Status, &types.DeviceStatus{}, &types.DeviceStatus{}, ignore)
+	case want.Response != nil && got.Response != nil:
+		return sameMessage(want.Response, got.Response, &types.DeviceResponse{}, &types.DeviceResponse{}, ignore)
+	}
+	return want.Status == nil && got.Status == nil && want.Response == nil && got.Response == nil
+}
+
+func sameMessage(wantJson, gotJson []byte, want, got proto.Message, ignore map[string]bool) bool {
+	if protojson.Unmarshal(wantJson, want) != nil || protojson.Unmarshal(gotJson, got) != nil {
+		return false
+	}
+	clearFields(want.ProtoReflect(), ignore)
+	clearFields(got.ProtoReflect(), ignore)
+	return proto.Equal(want, got)
+}
+
+// clearFields clears the fields with the given names in the message and the messages in it, e.g.
+// the timestamps the gt06 and tr06 protocols take from the clock for some message types
+func clearFields(m protoreflect.Message, ignore map[string]bool) {
+	if len(ignore) == 0 {
+		return
+	}
+	var cleared []protoreflect.FieldDescriptor
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if ignore[string(fd.Name())] || ignore[fd.JSONName()] {
+			// cleared after ranging, the message can't change during it
+			cleared = append(cleared, fd)
+			return true
+		}
+		switch {
+		case fd.IsList() && fd.Message() != nil:
+			list := v.List()
+			for i := 0; i < list.Len(); i++ {
+				clearFields(list.Get(i).Message(), ignore)
+			}
+		case fd.IsMap() && fd.MapValue().Message() != nil:
+			v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+				clearFields(mv.Message(), ignore)
+				return true
+			})
+		case !fd.IsList() && !fd.IsMap() && fd.Message() != nil:
+			clearFields(v.Message(), ignore)
+		}
+		return true
+	})
+	for _, fd := range cleared {
+		m.Clear(fd)
+	}
+}
+
+// diff prints the lines that differ and tells if there were any
+func diff(w io.Writer, name string, want, got []line, ignore map[string]bool) bool {
+	differs := false
+	for i := 0; i < len(want) || i < len(got); i++ {
+		switch {
+		case i >= len(got):
+			fmt.Fprintf(w, "%s:%d missing\n  want %s\n", name, i+1, show(want[i]))
+		case i >= len(want):
+			fmt.Fprintf(w, "%s:%d unexpected\n  got  %s\n", name, i+1, show(got[i]))
+		case !sameLine(want[i], got[i], ignore):
+			fmt.Fprintf(w, "%s:%d differs\n  want %s\n  got  %s\n", name, i+1, show(want[i]), show(got[i]))
+		default:
+			continue
+		}
+		differs = true
+	}
+	return differs
+}
+
+func show(l line) string {
+	data, _ := json.Marshal(l)
+	return string(data)
+}
+
+func goldenPath(capturePath string) string {
+	return strings.TrimSuffix(capturePath, capture.Extension) + ".golden.jsonl"
+}
+
+func main() {
+	protocolName := flag.String("protocol", "", "Protocol to replay the captures with, the one recorded in each capture if empty")
+	golden := flag.Bool("golden", false, "Diff the output against <capture>.golden.jsonl instead of printing it, exits 1 if any differ")
+	update := flag.Bool("update", false, "Write the output to <capture>.golden.jsonl")
+	ignoreFields := flag.String("ignore", "", "Comma separated fields left out of the diff, e.g. timestamp")
+	logLevel := flag.String("logLevel", "warn", "Log level of the protocols - one of debug, info, warn or error")
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: replay [flags] capture.avlcap...")
+		flag.PrintDefaults()
+		os.Exit(2)
+	}
+	if err := configuredLogger.Configure(configuredLogger.Config{Level: *logLevel, Encoding: "console"}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var protocolOverride *types.DeviceProtocolType
+	if *protocolName != "" {
+		value, ok := types.DeviceProtocolType_value[strings.ToUpper(*protocolName)]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "unknown protocol %s\n", *protocolName)
+			os.Exit(2)
+		}
+		protocolType := types.DeviceProtocolType(value)
+		protocolOverride = &protocolType
+	}
+	ignore := make(map[string]bool)
+	for _, field := range strings.Split(*ignoreFields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			ignore[field] = true
+		}
+	}
+
+	failed := false
+	for _, path := range flag.Args() {
+		c, err := capture.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		protocolType := c.Protocol
+		if protocolOverride != nil {
+			protocolType = *protocolOverride
+		}
+
+		res := replay(c, protocolType)
+		got := res.lines()
+		if !bytes.Equal(res.acks, c.Outbound()) {
+			// commands sent over the api are in the capture's outbound traffic too, so only a hint
+			fmt.Fprintf(os.Stderr, "%s: acks written differ from the capture's outbound traffic (%d bytes, captured %d)\n", path, len(res.acks), len(c.Outbound()))
+		}
+
+		switch {
+		case *update:
+			f, err := os.Create(goldenPath(path))
+			if err == nil {
+				err = writeLines(f, got)
+				if closeErr := f.Close(); err == nil {
+					err = closeErr
+				}
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+				failed = true
+			}
+		case *golden:
+			want, err := readLines(goldenPath(path))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "%s: %v\n", path, err)
+				failed = true
+				continue
+			}
+			if diff(os.Stdout, path, want, got, ignore) {
+				failed = true
+			}
+		default:
+			if err := writeLines(os.Stdout, got); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
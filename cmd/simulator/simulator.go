@@ -0,0 +1,107 @@
+// simulator connects simulated devices to a receiver and drives them along a route, speaking every
+// protocol the receiver supports and checking its acks. It is meant for end to end checks and load.
+//
+//	simulator -addr localhost:21000 -protocols codec8,gt06 -connections 100 -duration 5m
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	"github.com/404minds/avl-receiver/internal/simulator"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:21000", "Receiver address to connect to")
+	protocols := flag.String("protocols", "all", "Comma separated protocols given to the connections round robin, all or some of "+strings.Join(simulator.Protocols(), ", "))
+	connections := flag.Int("connections", 1, "Number of simulated devices, each on its own connection")
+	imeiBase := flag.Uint64("imeiBase", 861234567800000, "Imei of the first device, the others count up from it")
+	routeFile := flag.String("route", "", "File of lat,lon[,speed km/h] lines to drive, a loop around Bengaluru if empty")
+	interval := flag.Duration("interval", 10*time.Second, "Time between position reports of a device")
+	batch := flag.Int("batch", 1, "Fixes per position report")
+	heartbeatEvery := flag.Int("heartbeatEvery", 6, "Send a heartbeat after every so many reports, 0 for never")
+	alarmEvery := flag.Int("alarmEvery", 0, "Send an alarm after every so many reports, 0 for never")
+	reports := flag.Int("reports", 0, "Reports each device sends before disconnecting, 0 for no limit")
+	duration := flag.Duration("duration", 0, "Stop after this long, 0 for no limit")
+	ramp := flag.Duration("ramp", 0, "Spread the connects over this long")
+	ackTimeout := flag.Duration("ackTimeout", 10*time.Second, "Time the receiver has to ack a frame")
+	report := flag.Duration("report", 10*time.Second, "Interval of the stats printed while running, 0 to only print them at the end")
+	logLevel := flag.String("logLevel", "warn", "Log level - one of debug, info, warn or error")
+	flag.Parse()
+
+	if err := configuredLogger.Configure(configuredLogger.Config{Level: *logLevel, Encoding: "console"}); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	cfg := simulator.Config{
+		Addr:           *addr,
+		Connections:    *connections,
+		ImeiBase:       *imeiBase,
+		Interval:       *interval,
+		Batch:          *batch,
+		HeartbeatEvery: *heartbeatEvery,
+		AlarmEvery:     *alarmEvery,
+		Reports:        *reports,
+		Ramp:           *ramp,
+		AckTimeout:     *ackTimeout,
+	}
+	if *protocols == "all" {
+		cfg.Protocols = simulator.Protocols()
+	} else {
+		for _, protocol := range strings.Split(*protocols, ",") {
+			if protocol = strings.TrimSpace(protocol); protocol != "" {
+				cfg.Protocols = append(cfg.Protocols, protocol)
+			}
+		}
+	}
+	if *routeFile != "" {
+		route, err := simulator.LoadRoute(*routeFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		cfg.Route = route
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	var stats simulator.Stats
+	if *report > 0 {
+		go func() {
+			ticker := time.NewTicker(*report)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					fmt.Println(stats.String())
+				}
+			}
+		}()
+	}
+
+	start := time.Now()
+	if err := simulator.Run(ctx, cfg, &stats); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	fmt.Printf("%s elapsed=%s\n", stats.String(), time.Since(start).Round(time.Millisecond))
+	if stats.Failed.Load() > 0 || stats.AckErrors.Load() > 0 {
+		os.Exit(1)
+	}
+}
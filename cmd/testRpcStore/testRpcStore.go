@@ -39,8 +39,64 @@ func (s *server) SaveDeviceStatus(ctx context.Context, req *types.DeviceStatus)
 	return &emptypb.Empty{}, nil
 }
 
+func (s *server) SavedeviceResponse(ctx context.Context, req *types.DeviceResponse) (*emptypb.Empty, error) {
+	logger.Sugar().Infoln("response", req.String())
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) FetchDeviceModel(ctx context.Context, req *store.FetchDeviceModelRequest) (*store.FetchDeviceModelResponse, error) {
+	return &store.FetchDeviceModelResponse{}, nil
+}
+
+func (s *server) SaveCommandDelivery(ctx context.Context, req *store.CommandDelivery) (*emptypb.Empty, error) {
+	logger.Sugar().Infoln("command delivery", req.String())
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) SaveDeviceLimited(ctx context.Context, req *store.DeviceLimited) (*emptypb.Empty, error) {
+	logger.Sugar().Infoln("device limited", req.String())
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) SaveDeviceSession(ctx context.Context, req *store.DeviceSessionEvent) (*emptypb.Empty, error) {
+	logger.Sugar().Infoln("device session", req.String())
+	return &emptypb.Empty{}, nil
+}
+
+// unary serves a method of the receiver's data store client with the server's method for it
+func unary[Req any, Resp any](handle func(s *server, ctx context.Context, req *Req) (Resp, error)) func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+		req := new(Req)
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		return handle(srv.(*server), ctx, req)
+	}
+}
+
+// insertServiceDesc is the data store under the method names the receiver calls, its
+// -grpcServiceName is the service name
+func insertServiceDesc(serviceName string) *grpc.ServiceDesc {
+	return &grpc.ServiceDesc{
+		ServiceName: serviceName,
+		HandlerType: (*any)(nil),
+		Methods: []grpc.MethodDesc{
+			{MethodName: "VerifyDevice", Handler: unary((*server).VerifyDevice)},
+			{MethodName: "InsertAVL", Handler: unary((*server).SaveDeviceStatus)},
+			{MethodName: "InsertDeviceResponse", Handler: unary((*server).SavedeviceResponse)},
+			{MethodName: "FetchDeviceModel", Handler: unary(func(s *server, ctx context.Context, req *types.FetchDeviceModelRequest) (*store.FetchDeviceModelResponse, error) {
+				return s.FetchDeviceModel(ctx, &store.FetchDeviceModelRequest{Imei: req.Imei})
+			})},
+			{MethodName: "InsertCommandDelivery", Handler: unary((*server).SaveCommandDelivery)},
+			{MethodName: "InsertDeviceLimited", Handler: unary((*server).SaveDeviceLimited)},
+			{MethodName: "InsertDeviceSession", Handler: unary((*server).SaveDeviceSession)},
+		},
+	}
+}
+
 func main() {
 	port := flag.Int("port", 0, "port for this server")
+	serviceName := flag.String("serviceName", "AVLService", "Service name the receiver's -grpcServiceName points at, without the leading slash")
 	flag.Parse()
 
 	if port == nil || *port == 0 {
@@ -58,6 +114,7 @@ func main() {
 	s := grpc.NewServer()
 	server := &server{}
 	store.RegisterAvlDataStoreServer(s, server)
+	s.RegisterService(insertServiceDesc(*serviceName), server)
 	if err := s.Serve(lis); err != nil {
 		logger.Sugar().Fatalf("failed to serve: %v", err)
 	}
@@ -0,0 +1,304 @@
+// Package capture records the raw traffic of device connections so a misbehaving parser can be
+// reproduced with cmd/replay.
+//
+// A capture file starts with the magic "AVLCAP", a version byte and the start time as unix nanos
+// (uvarint). Then come records of a kind byte, the time since the previous record in microseconds
+// (uvarint), the payload length (uvarint) and the payload. Inbound and outbound records hold the
+// bytes as read from and written to the socket, the device record "<imei>\x00<protocol>\x00<remote addr>".
+package capture
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	"github.com/404minds/avl-receiver/internal/types"
+	"go.uber.org/zap"
+)
+
+var logger = configuredLogger.Logger
+
+const (
+	magic   = "AVLCAP"
+	version = 1
+
+	// extension of capture files
+	Extension = ".avlcap"
+)
+
+type Kind byte
+
+const (
+	Inbound  Kind = 'I'
+	Outbound Kind = 'O'
+	Device   Kind = 'D'
+)
+
+var ErrBadCapture = errors.New("not a capture file")
+
+// bytes kept in memory while waiting for the login, past it the connection isn't captured
+const maxPendingBytes = 64 * 1024
+
+// Config picks the connections to capture
+type Config struct {
+	Dir   string
+	Imeis []string // every device if empty
+}
+
+type Record struct {
+	Kind Kind
+	Time time.Time
+	Data []byte
+}
+
+// Recorder captures one connection. Records are held in memory until the device logged in and is
+// known to be one of the captured ones, then written to a file named after its imei. All methods
+// are no-ops on a nil Recorder.
+type Recorder struct {
+	config     Config
+	remoteAddr string
+	start      time.Time
+
+	mu           sync.Mutex
+	last         time.Time
+	pending      []Record
+	pendingBytes int
+	file         *os.File
+	w            *bufio.Writer
+	done         bool // not captured, or closed
+}
+
+// Start begins capturing a connection, nil if capturing is disabled
+func Start(config *Config, remoteAddr string) *Recorder {
+	if config == nil || config.Dir == "" {
+		return nil
+	}
+	now := time.Now()
+	return &Recorder{config: *config, remoteAddr: remoteAddr, start: now, last: now}
+}
+
+func (r *Recorder) Inbound(data []byte) {
+	r.record(Inbound, data)
+}
+
+func (r *Recorder) Outbound(data []byte) {
+	r.record(Outbound, data)
+}
+
+// SetDevice is called after the login, the capture is written from here on if the device is wanted
+func (r *Recorder) SetDevice(imei string, protocol types.DeviceProtocolType) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done {
+		return
+	}
+	if len(r.config.Imeis) > 0 && !contains(r.config.Imeis, imei) {
+		r.done = true
+		r.pending = nil
+		return
+	}
+
+	name := fmt.Sprintf("%s-%s%s", imei, r.start.UTC().Format("20060102T150405.000000000Z"), Extension)
+	file, err := os.OpenFile(filepath.Join(r.config.Dir, name), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		logger.Error("failed to create capture file", zap.String("imei", imei), zap.Error(err))
+		r.done = true
+		r.pending = nil
+		return
+	}
+	r.file = file
+	r.w = bufio.NewWriter(file)
+
+	header := append([]byte(magic), version)
+	header = binary.AppendUvarint(header, uint64(r.start.UnixNano()))
+	_, _ = r.w.Write(header)
+
+	// the device record goes first, at the connection's start
+	pending := r.pending
+	r.pending = nil
+	r.last = r.start
+	r.writeRecord(Record{Kind: Device, Time: r.start, Data: []byte(imei + "\x00" + protocol.String() + "\x00" + r.remoteAddr)})
+	for _, rec := range pending {
+		r.writeRecord(rec)
+	}
+	logger.Info("capturing device traffic", zap.String("imei", imei), zap.String("file", file.Name()))
+}
+
+func (r *Recorder) record(kind Kind, data []byte) {
+	if r == nil || len(data) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done {
+		return
+	}
+	rec := Record{Kind: kind, Time: time.Now(), Data: bytes.Clone(data)}
+	if r.w == nil {
+		r.pendingBytes += len(data)
+		if r.pendingBytes > maxPendingBytes {
+			r.done = true
+			r.pending = nil
+			return
+		}
+		r.pending = append(r.pending, rec)
+		return
+	}
+	r.writeRecord(rec)
+}
+
+func (r *Recorder) writeRecord(rec Record) {
+	elapsed := rec.Time.Sub(r.last)
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	r.last = r.last.Add(elapsed.Truncate(time.Microsecond))
+
+	buf := make([]byte, 0, 1+2*binary.MaxVarintLen64+len(rec.Data))
+	buf = append(buf, byte(rec.Kind))
+	buf = binary.AppendUvarint(buf, uint64(elapsed.Microseconds()))
+	buf = binary.AppendUvarint(buf, uint64(len(rec.Data)))
+	buf = append(buf, rec.Data...)
+	if _, err := r.w.Write(buf); err != nil {
+		logger.Error("failed to write capture, giving up on it", zap.String("file", r.file.Name()), zap.Error(err))
+		r.closeFile()
+	}
+}
+
+func (r *Recorder) Close() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closeFile()
+}
+
+func (r *Recorder) closeFile() {
+	r.done = true
+	r.pending = nil
+	if r.file == nil {
+		return
+	}
+	if err := r.w.Flush(); err != nil {
+		logger.Error("failed to flush capture", zap.String("file", r.file.Name()), zap.Error(err))
+	}
+	if err := r.file.Close(); err != nil {
+		logger.Error("failed to close capture", zap.String("file", r.file.Name()), zap.Error(err))
+	}
+	r.file = nil
+	r.w = nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Capture is a capture file read back
+type Capture struct {
+	Start      time.Time
+	Imei       string
+	Protocol   types.DeviceProtocolType
+	RemoteAddr string
+	Records    []Record // inbound and outbound
+}
+
+// Inbound is everything the device sent, in order
+func (c *Capture) Inbound() []byte {
+	return c.joined(Inbound)
+}
+
+// Outbound is everything the receiver sent to the device, in order
+func (c *Capture) Outbound() []byte {
+	return c.joined(Outbound)
+}
+
+func (c *Capture) joined(kind Kind) []byte {
+	var b bytes.Buffer
+	for _, rec := range c.Records {
+		if rec.Kind == kind {
+			b.Write(rec.Data)
+		}
+	}
+	return b.Bytes()
+}
+
+func ReadFile(path string) (*Capture, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(bufio.NewReader(f))
+}
+
+// Read parses a capture, a capture cut short e.g. by a crash is returned up to its last full record
+func Read(r *bufio.Reader) (*Capture, error) {
+	header := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(r, header); err != nil || string(header[:len(magic)]) != magic {
+		return nil, ErrBadCapture
+	}
+	if header[len(magic)] != version {
+		return nil, fmt.Errorf("unsupported capture version %d", header[len(magic)])
+	}
+	startNanos, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, ErrBadCapture
+	}
+
+	c := &Capture{Start: time.Unix(0, int64(startNanos))}
+	at := c.Start
+	for {
+		kind, err := r.ReadByte()
+		if err == io.EOF {
+			return c, nil
+		} else if err != nil {
+			return nil, err
+		}
+		micros, err := binary.ReadUvarint(r)
+		if err != nil {
+			return c, nil
+		}
+		size, err := binary.ReadUvarint(r)
+		if err != nil || size > 16*1024*1024 {
+			return c, nil
+		}
+		data := make([]byte, size)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return c, nil
+		}
+		at = at.Add(time.Duration(micros) * time.Microsecond)
+
+		switch Kind(kind) {
+		case Device:
+			fields := strings.SplitN(string(data), "\x00", 3)
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("%w: bad device record", ErrBadCapture)
+			}
+			c.Imei = fields[0]
+			c.Protocol = types.DeviceProtocolType(types.DeviceProtocolType_value[fields[1]])
+			c.RemoteAddr = fields[2]
+		case Inbound, Outbound:
+			c.Records = append(c.Records, Record{Kind: Kind(kind), Time: at, Data: data})
+		default:
+			return nil, fmt.Errorf("%w: unknown record kind %q", ErrBadCapture, kind)
+		}
+	}
+}
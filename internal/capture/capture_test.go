@@ -0,0 +1,102 @@
+package capture
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorderRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	r := Start(&Config{Dir: dir}, "10.0.0.1:5000")
+
+	// traffic before the login is kept until the device is known
+	r.Inbound([]byte("login"))
+	r.SetDevice("861234567890123", types.DeviceProtocolType_GT06)
+	r.Outbound([]byte{0x01})
+	r.Inbound([]byte("status 1"))
+	r.Inbound([]byte("status 2"))
+	r.Close()
+	r.Inbound([]byte("after close"))
+
+	files, err := filepath.Glob(filepath.Join(dir, "861234567890123-*"+Extension))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	c, err := ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, "861234567890123", c.Imei)
+	assert.Equal(t, types.DeviceProtocolType_GT06, c.Protocol)
+	assert.Equal(t, "10.0.0.1:5000", c.RemoteAddr)
+	assert.Equal(t, "loginstatus 1status 2", string(c.Inbound()))
+	assert.Equal(t, []byte{0x01}, c.Outbound())
+	require.Len(t, c.Records, 4)
+	assert.Equal(t, Inbound, c.Records[0].Kind)
+	assert.Equal(t, Outbound, c.Records[1].Kind)
+	for i := 1; i < len(c.Records); i++ {
+		assert.False(t, c.Records[i].Time.Before(c.Records[i-1].Time))
+	}
+	assert.False(t, c.Records[0].Time.Before(c.Start))
+}
+
+func TestRecorderSkipsOtherDevices(t *testing.T) {
+	dir := t.TempDir()
+	r := Start(&Config{Dir: dir, Imeis: []string{"861234567890123"}}, "10.0.0.1:5000")
+	r.Inbound([]byte("login"))
+	r.SetDevice("869999999999999", types.DeviceProtocolType_GT06)
+	r.Inbound([]byte("status"))
+	r.Close()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestRecorderGivesUpWithoutLogin(t *testing.T) {
+	dir := t.TempDir()
+	r := Start(&Config{Dir: dir}, "10.0.0.1:5000")
+	r.Inbound(make([]byte, maxPendingBytes+1))
+	r.SetDevice("861234567890123", types.DeviceProtocolType_GT06)
+	r.Close()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestNilRecorder(t *testing.T) {
+	r := Start(nil, "10.0.0.1:5000")
+	assert.Nil(t, r)
+	r.Inbound([]byte("login"))
+	r.SetDevice("861234567890123", types.DeviceProtocolType_GT06)
+	r.Outbound([]byte{0x01})
+	r.Close()
+}
+
+func TestReadTruncatedCapture(t *testing.T) {
+	dir := t.TempDir()
+	r := Start(&Config{Dir: dir}, "10.0.0.1:5000")
+	r.SetDevice("861234567890123", types.DeviceProtocolType_FM1200)
+	r.Inbound([]byte("first"))
+	r.Inbound([]byte("second"))
+	r.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"+Extension))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	data, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(files[0], data[:len(data)-3], 0o600))
+
+	c, err := ReadFile(files[0])
+	require.NoError(t, err)
+	assert.Equal(t, "first", string(c.Inbound()))
+
+	require.NoError(t, os.WriteFile(files[0], []byte("something else"), 0o600))
+	_, err = ReadFile(files[0])
+	assert.ErrorIs(t, err, ErrBadCapture)
+}
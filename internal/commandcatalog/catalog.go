@@ -0,0 +1,170 @@
+// Package commandcatalog turns brand independent device commands into the text each protocol expects,
+// so callers can immobilize a vehicle without knowing if it carries a teltonika or a concox tracker.
+//
+// Only teltonika and concox/wanway devices have typed commands. The aquila (OBDII2G) and intellitrac
+// command sets aren't in any document we have, so those devices still take raw commands and Encode
+// turns their typed commands away with ErrUnsupportedCommand, see Supported.
+package commandcatalog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/404minds/avl-receiver/internal/types"
+)
+
+var (
+	ErrUnsupportedCommand = errors.New("command not supported by the device protocol")
+	ErrInvalidCommand     = errors.New("invalid command")
+)
+
+type Kind string
+
+const (
+	EngineCut             Kind = "engine_cut"
+	EngineRestore         Kind = "engine_restore"
+	SetReportingInterval  Kind = "set_reporting_interval"
+	Reboot                Kind = "reboot"
+	RequestPosition       Kind = "request_position"
+	SetApn                Kind = "set_apn"
+	SetOverspeedThreshold Kind = "set_overspeed_threshold"
+)
+
+var kinds = []Kind{EngineCut, EngineRestore, SetReportingInterval, Reboot, RequestPosition, SetApn, SetOverspeedThreshold}
+
+// Command is a typed device command, only the fields of its kind are used
+type Command struct {
+	Kind            Kind   `json:"kind"`
+	IntervalSeconds uint32 `json:"interval_seconds,omitempty"` // SetReportingInterval
+	Apn             string `json:"apn,omitempty"`              // SetApn
+	ApnUser         string `json:"apn_user,omitempty"`
+	ApnPassword     string `json:"apn_password,omitempty"`
+	SpeedKmh        uint32 `json:"speed_kmh,omitempty"` // SetOverspeedThreshold
+}
+
+// String is a readable form of the command for logs and delivery reports, it leaves out the apn password
+func (c Command) String() string {
+	switch c.Kind {
+	case SetReportingInterval:
+		return fmt.Sprintf("%s(%ds)", c.Kind, c.IntervalSeconds)
+	case SetApn:
+		return fmt.Sprintf("%s(%s)", c.Kind, c.Apn)
+	case SetOverspeedThreshold:
+		return fmt.Sprintf("%s(%dkm/h)", c.Kind, c.SpeedKmh)
+	default:
+		return string(c.Kind)
+	}
+}
+
+func (c Command) Validate() error {
+	switch c.Kind {
+	case EngineCut, EngineRestore, Reboot, RequestPosition:
+	case SetReportingInterval:
+		if c.IntervalSeconds == 0 {
+			return fmt.Errorf("%w: interval_seconds is required", ErrInvalidCommand)
+		}
+	case SetApn:
+		if c.Apn == "" {
+			return fmt.Errorf("%w: apn is required", ErrInvalidCommand)
+		}
+		// the apn settings end up inside comma/semicolon separated device commands
+		for _, v := range []string{c.Apn, c.ApnUser, c.ApnPassword} {
+			if strings.ContainsAny(v, ",;#:\r\n") {
+				return fmt.Errorf("%w: apn settings can't contain , ; # : or newlines", ErrInvalidCommand)
+			}
+		}
+	case SetOverspeedThreshold:
+		if c.SpeedKmh == 0 {
+			return fmt.Errorf("%w: speed_kmh is required", ErrInvalidCommand)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind %q", ErrInvalidCommand, c.Kind)
+	}
+	return nil
+}
+
+// Encode returns the raw command to pass to the protocol's SendCommandToDevice
+func Encode(protocol types.DeviceProtocolType, c Command) (string, error) {
+	if err := c.Validate(); err != nil {
+		return "", err
+	}
+
+	encoded := encode(protocol, c)
+	if encoded == "" {
+		if len(Supported(protocol)) == 0 {
+			return "", fmt.Errorf("%w: %s only takes raw commands", ErrUnsupportedCommand, protocol)
+		}
+		return "", fmt.Errorf("%w: %s on %s", ErrUnsupportedCommand, c.Kind, protocol)
+	}
+	return encoded, nil
+}
+
+// Supported lists the kinds the protocol has typed commands for, nothing for the protocols that only
+// take raw commands
+func Supported(protocol types.DeviceProtocolType) []Kind {
+	var supported []Kind
+	for _, kind := range kinds {
+		// the encoders don't look at the fields, so a bare command of the kind is enough
+		if encode(protocol, Command{Kind: kind}) != "" {
+			supported = append(supported, kind)
+		}
+	}
+	return supported
+}
+
+func encode(protocol types.DeviceProtocolType, c Command) string {
+	switch protocol {
+	case types.DeviceProtocolType_FM1200:
+		return encodeTeltonika(c)
+	case types.DeviceProtocolType_GT06, types.DeviceProtocolType_TR06:
+		return encodeConcox(c)
+	}
+	return ""
+}
+
+// teltonika sms/gprs commands, sent in a codec 12 frame. The setparam ids are the fmb series ones,
+// 10050 is the min period while moving on the home network and 11104 the overspeeding max speed.
+func encodeTeltonika(c Command) string {
+	switch c.Kind {
+	case EngineCut:
+		return "setdigout 1"
+	case EngineRestore:
+		return "setdigout 0"
+	case SetReportingInterval:
+		return fmt.Sprintf("setparam 10050:%d", c.IntervalSeconds)
+	case Reboot:
+		return "cpureset"
+	case RequestPosition:
+		return "getgps"
+	case SetApn:
+		return fmt.Sprintf("setparam 2001:%s;2002:%s;2003:%s", c.Apn, c.ApnUser, c.ApnPassword)
+	case SetOverspeedThreshold:
+		return fmt.Sprintf("setparam 11104:%d", c.SpeedKmh)
+	}
+	return ""
+}
+
+// concox/wanway online commands, sent in a 0x80 packet
+func encodeConcox(c Command) string {
+	switch c.Kind {
+	case EngineCut:
+		return "RELAY,1#"
+	case EngineRestore:
+		return "RELAY,0#"
+	case SetReportingInterval:
+		return fmt.Sprintf("TIMER,%d#", c.IntervalSeconds)
+	case Reboot:
+		return "RESET#"
+	case RequestPosition:
+		return "WHERE#"
+	case SetApn:
+		if c.ApnUser == "" && c.ApnPassword == "" {
+			return fmt.Sprintf("APN,%s#", c.Apn)
+		}
+		return fmt.Sprintf("APN,%s,%s,%s#", c.Apn, c.ApnUser, c.ApnPassword)
+	case SetOverspeedThreshold:
+		return fmt.Sprintf("SPEED,ON,20,%d,1#", c.SpeedKmh) // alarm after 20s above the threshold
+	}
+	return ""
+}
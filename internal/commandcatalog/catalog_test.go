@@ -0,0 +1,84 @@
+package commandcatalog
+
+import (
+	"testing"
+
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodePerProtocol(t *testing.T) {
+	cases := []struct {
+		protocol types.DeviceProtocolType
+		command  Command
+		expected string
+	}{
+		{types.DeviceProtocolType_FM1200, Command{Kind: EngineCut}, "setdigout 1"},
+		{types.DeviceProtocolType_FM1200, Command{Kind: Reboot}, "cpureset"},
+		{types.DeviceProtocolType_FM1200, Command{Kind: SetReportingInterval, IntervalSeconds: 30}, "setparam 10050:30"},
+		{types.DeviceProtocolType_FM1200, Command{Kind: SetApn, Apn: "internet", ApnUser: "user"}, "setparam 2001:internet;2002:user;2003:"},
+		{types.DeviceProtocolType_FM1200, Command{Kind: SetOverspeedThreshold, SpeedKmh: 90}, "setparam 11104:90"},
+		{types.DeviceProtocolType_GT06, Command{Kind: EngineCut}, "RELAY,1#"},
+		{types.DeviceProtocolType_TR06, Command{Kind: EngineRestore}, "RELAY,0#"},
+		{types.DeviceProtocolType_GT06, Command{Kind: SetApn, Apn: "internet"}, "APN,internet#"},
+		{types.DeviceProtocolType_TR06, Command{Kind: SetReportingInterval, IntervalSeconds: 30}, "TIMER,30#"},
+		{types.DeviceProtocolType_GT06, Command{Kind: SetOverspeedThreshold, SpeedKmh: 90}, "SPEED,ON,20,90,1#"},
+	}
+	for _, c := range cases {
+		encoded, err := Encode(c.protocol, c.command)
+		require.NoError(t, err, "%s %s", c.protocol, c.command)
+		assert.Equal(t, c.expected, encoded, "%s %s", c.protocol, c.command)
+	}
+}
+
+var everyKind = []Command{
+	{Kind: EngineCut}, {Kind: EngineRestore}, {Kind: Reboot}, {Kind: RequestPosition},
+	{Kind: SetReportingInterval, IntervalSeconds: 60},
+	{Kind: SetApn, Apn: "internet"},
+	{Kind: SetOverspeedThreshold, SpeedKmh: 80},
+}
+
+func TestEveryKindEncodesForTeltonikaAndConcox(t *testing.T) {
+	for _, protocol := range []types.DeviceProtocolType{types.DeviceProtocolType_FM1200, types.DeviceProtocolType_GT06, types.DeviceProtocolType_TR06} {
+		assert.Len(t, Supported(protocol), len(everyKind), "%s", protocol)
+		for _, c := range everyKind {
+			encoded, err := Encode(protocol, c)
+			assert.NoError(t, err, "%s %s", protocol, c)
+			assert.NotEmpty(t, encoded)
+		}
+	}
+}
+
+func TestRawOnlyProtocols(t *testing.T) {
+	for _, protocol := range []types.DeviceProtocolType{types.DeviceProtocolType_OBDII2G, types.DeviceProtocolType_INTELLITRAC_A} {
+		assert.Empty(t, Supported(protocol), "%s", protocol)
+		for _, c := range everyKind {
+			_, err := Encode(protocol, c)
+			assert.ErrorIs(t, err, ErrUnsupportedCommand, "%s %s", protocol, c)
+			assert.ErrorContains(t, err, "only takes raw commands")
+		}
+	}
+}
+
+func TestEncodeRejects(t *testing.T) {
+	_, err := Encode(types.DeviceProtocolType_HOWENWS, Command{Kind: EngineCut})
+	assert.ErrorIs(t, err, ErrUnsupportedCommand)
+
+	invalid := []Command{
+		{Kind: "self_destruct"},
+		{Kind: SetReportingInterval},
+		{Kind: SetOverspeedThreshold},
+		{Kind: SetApn},
+		{Kind: SetApn, Apn: "internet#RESET"},
+	}
+	for _, c := range invalid {
+		_, err := Encode(types.DeviceProtocolType_GT06, c)
+		assert.ErrorIs(t, err, ErrInvalidCommand, c.String())
+	}
+}
+
+func TestCommandStringHidesApnPassword(t *testing.T) {
+	c := Command{Kind: SetApn, Apn: "internet", ApnUser: "user", ApnPassword: "secret"}
+	assert.Equal(t, "set_apn(internet)", c.String())
+}
@@ -0,0 +1,296 @@
+// Package commandqueue keeps the commands for devices that are offline until they log in again.
+package commandqueue
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/commandcatalog"
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	"go.uber.org/zap"
+)
+
+var logger = configuredLogger.Logger
+
+var (
+	ErrCommandNotFound = errors.New("queued command not found")
+	ErrCommandInFlight = errors.New("queued command is being delivered")
+)
+
+type Command struct {
+	Id         string                  `json:"id"`
+	Imei       string                  `json:"imei"`
+	Command    string                  `json:"command"`
+	Typed      *commandcatalog.Command `json:"typed,omitempty"` // encoded for the device's protocol on delivery
+	Priority   int32                   `json:"priority"`        // higher goes first
+	EnqueuedAt time.Time               `json:"enqueued_at"`
+	ExpiresAt  time.Time               `json:"expires_at,omitempty"` // zero never expires
+	Attempts   int32                   `json:"attempts"`
+
+	claimed bool
+}
+
+func (c *Command) expired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt)
+}
+
+// Queue holds the pending commands of every device. With a path every change is written
+// to the file before returning, so queued commands survive restarts.
+type Queue struct {
+	path string
+	now  func() time.Time
+
+	mu       sync.Mutex
+	commands map[string]*Command
+}
+
+// Open loads the queue from path, an empty path keeps the queue in memory only
+func Open(path string) (*Queue, error) {
+	q := &Queue{path: path, now: time.Now, commands: make(map[string]*Command)}
+	if path == "" {
+		return q, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return q, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var commands []*Command
+	if err := json.Unmarshal(b, &commands); err != nil {
+		return nil, err
+	}
+	for _, c := range commands {
+		q.commands[c.Id] = c
+	}
+	return q, nil
+}
+
+// save writes the queue to a temp file and renames it over the old one. Called with mu held.
+func (q *Queue) save() error {
+	if q.path == "" {
+		return nil
+	}
+
+	commands := make([]*Command, 0, len(q.commands))
+	for _, c := range q.commands {
+		commands = append(commands, c)
+	}
+	sortCommands(commands)
+	b, err := json.MarshalIndent(commands, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(q.path), 0755); err != nil {
+		return err
+	}
+	tmp := q.path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(b)
+	if err == nil {
+		err = f.Sync()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, q.path)
+}
+
+func sortCommands(commands []*Command) {
+	sort.Slice(commands, func(i, j int) bool {
+		if commands[i].Imei != commands[j].Imei {
+			return commands[i].Imei < commands[j].Imei
+		}
+		if commands[i].Priority != commands[j].Priority {
+			return commands[i].Priority > commands[j].Priority
+		}
+		if !commands[i].EnqueuedAt.Equal(commands[j].EnqueuedAt) {
+			return commands[i].EnqueuedAt.Before(commands[j].EnqueuedAt)
+		}
+		return commands[i].Id < commands[j].Id
+	})
+}
+
+func newId() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Enqueue adds a command for the device, a ttl of 0 keeps it until it is delivered or cancelled
+func (q *Queue) Enqueue(imei string, command string, priority int32, ttl time.Duration) (Command, error) {
+	return q.enqueue(&Command{Imei: imei, Command: command, Priority: priority}, ttl)
+}
+
+// EnqueueTyped queues a typed command, Command holds its readable form
+func (q *Queue) EnqueueTyped(imei string, typed commandcatalog.Command, priority int32, ttl time.Duration) (Command, error) {
+	return q.enqueue(&Command{Imei: imei, Command: typed.String(), Typed: &typed, Priority: priority}, ttl)
+}
+
+func (q *Queue) enqueue(c *Command, ttl time.Duration) (Command, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	c.Id = newId()
+	c.EnqueuedAt = now
+	if ttl > 0 {
+		c.ExpiresAt = now.Add(ttl)
+	}
+	q.commands[c.Id] = c
+	if err := q.save(); err != nil {
+		delete(q.commands, c.Id)
+		return Command{}, err
+	}
+	return *c, nil
+}
+
+// List returns the device's pending commands in delivery order, or every device's when imei is empty
+func (q *Queue) List(imei string) []Command {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	var commands []*Command
+	for _, c := range q.commands {
+		if (imei == "" || c.Imei == imei) && !c.expired(now) {
+			commands = append(commands, c)
+		}
+	}
+	sortCommands(commands)
+
+	result := make([]Command, len(commands))
+	for i, c := range commands {
+		result[i] = *c
+	}
+	return result
+}
+
+// Cancel removes a command that isn't being delivered, the device may already have a claimed one
+func (q *Queue) Cancel(id string) (Command, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// checked under the same lock as the delete so a Claim can't slip in between
+	if c, ok := q.commands[id]; ok && c.claimed {
+		return *c, ErrCommandInFlight
+	}
+	return q.remove(id)
+}
+
+// Remove takes the command out of the queue, after it was delivered or cancelled
+func (q *Queue) Remove(id string) (Command, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.remove(id)
+}
+
+// remove is Remove with mu held
+func (q *Queue) remove(id string) (Command, error) {
+	c, ok := q.commands[id]
+	if !ok {
+		return Command{}, ErrCommandNotFound
+	}
+	delete(q.commands, id)
+	if err := q.save(); err != nil {
+		q.commands[id] = c
+		return Command{}, err
+	}
+	return *c, nil
+}
+
+// Claim hands out the device's next command and counts the attempt, the command stays queued
+// until it is removed or released so it isn't lost if the receiver stops mid delivery.
+// Commands that expired in the meantime are dropped and returned.
+func (q *Queue) Claim(imei string) (next Command, ok bool, expired []Command) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	var candidates []*Command
+	for id, c := range q.commands {
+		if c.Imei != imei {
+			continue
+		}
+		if c.expired(now) && !c.claimed {
+			expired = append(expired, *c)
+			delete(q.commands, id)
+			continue
+		}
+		if !c.claimed {
+			candidates = append(candidates, c)
+		}
+	}
+
+	if len(candidates) == 0 {
+		// the expired ones are gone from memory either way, the file catches up on the next save
+		if len(expired) > 0 {
+			if err := q.save(); err != nil {
+				logger.Error("failed to save the command queue", zap.String("imei", imei), zap.Error(err))
+			}
+		}
+		return Command{}, false, expired
+	}
+
+	sortCommands(candidates)
+	c := candidates[0]
+	c.claimed = true
+	c.Attempts++
+	if err := q.save(); err != nil {
+		logger.Error("failed to save the command queue, not claiming", zap.String("imei", imei), zap.String("id", c.Id), zap.Error(err))
+		c.claimed = false
+		c.Attempts--
+		return Command{}, false, expired
+	}
+	return *c, true, expired
+}
+
+// Expire drops every device's expired commands and returns them, claimed ones are left to their delivery
+func (q *Queue) Expire() []Command {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := q.now()
+	var expired []Command
+	for id, c := range q.commands {
+		if c.expired(now) && !c.claimed {
+			expired = append(expired, *c)
+			delete(q.commands, id)
+		}
+	}
+	if len(expired) > 0 {
+		if err := q.save(); err != nil {
+			for _, c := range expired {
+				q.commands[c.Id] = &c
+			}
+			return nil
+		}
+	}
+	return expired
+}
+
+// Release puts a claimed command back for the next delivery attempt
+func (q *Queue) Release(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if c, ok := q.commands[id]; ok {
+		c.claimed = false
+	}
+}
@@ -0,0 +1,195 @@
+package commandqueue
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/commandcatalog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueueOrdersByPriorityThenAge(t *testing.T) {
+	q, err := Open("")
+	require.NoError(t, err)
+
+	low, _ := q.Enqueue("861234567890123", "getinfo", 0, 0)
+	high, _ := q.Enqueue("861234567890123", "engine cut", 10, 0)
+	low2, _ := q.Enqueue("861234567890123", "reboot", 0, 0)
+	q.Enqueue("350424063817363", "other device", 100, 0)
+
+	var ids []string
+	for _, c := range q.List("861234567890123") {
+		ids = append(ids, c.Id)
+	}
+	assert.Equal(t, []string{high.Id, low.Id, low2.Id}, ids)
+	assert.Len(t, q.List(""), 4)
+}
+
+func TestQueuePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q, err := Open(path)
+	require.NoError(t, err)
+
+	queued, err := q.Enqueue("861234567890123", "getinfo", 1, time.Hour)
+	require.NoError(t, err)
+	cancelled, err := q.Enqueue("861234567890123", "reboot", 0, 0)
+	require.NoError(t, err)
+	_, err = q.Cancel(cancelled.Id)
+	require.NoError(t, err)
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	commands := reopened.List("861234567890123")
+	require.Len(t, commands, 1)
+	assert.Equal(t, queued.Id, commands[0].Id)
+	assert.Equal(t, "getinfo", commands[0].Command)
+	assert.True(t, queued.ExpiresAt.Equal(commands[0].ExpiresAt))
+}
+
+func TestQueueClaimSkipsClaimedAndDropsExpired(t *testing.T) {
+	q, err := Open("")
+	require.NoError(t, err)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	q.now = func() time.Time { return now }
+
+	shortLived, _ := q.Enqueue("861234567890123", "getinfo", 5, time.Minute)
+	first, _ := q.Enqueue("861234567890123", "reboot", 1, 0)
+	second, _ := q.Enqueue("861234567890123", "getgps", 0, 0)
+
+	now = now.Add(2 * time.Minute)
+	next, ok, expired := q.Claim("861234567890123")
+	require.True(t, ok)
+	assert.Equal(t, first.Id, next.Id)
+	assert.Equal(t, int32(1), next.Attempts)
+	require.Len(t, expired, 1)
+	assert.Equal(t, shortLived.Id, expired[0].Id)
+
+	next, ok, _ = q.Claim("861234567890123")
+	require.True(t, ok)
+	assert.Equal(t, second.Id, next.Id, "claimed commands are skipped")
+
+	_, ok, _ = q.Claim("861234567890123")
+	assert.False(t, ok)
+
+	q.Release(first.Id)
+	next, ok, _ = q.Claim("861234567890123")
+	require.True(t, ok)
+	assert.Equal(t, first.Id, next.Id)
+	assert.Equal(t, int32(2), next.Attempts)
+}
+
+func TestQueueCancelUnknown(t *testing.T) {
+	q, err := Open("")
+	require.NoError(t, err)
+	_, err = q.Cancel("nope")
+	assert.ErrorIs(t, err, ErrCommandNotFound)
+}
+
+func TestQueueCancelInFlight(t *testing.T) {
+	q, err := Open("")
+	require.NoError(t, err)
+	queued, _ := q.Enqueue("861234567890123", "reboot", 0, 0)
+	_, ok, _ := q.Claim("861234567890123")
+	require.True(t, ok)
+
+	_, err = q.Cancel(queued.Id)
+	assert.ErrorIs(t, err, ErrCommandInFlight)
+	q.Release(queued.Id)
+	_, err = q.Cancel(queued.Id)
+	assert.NoError(t, err)
+}
+
+func TestQueueCancelRacingClaim(t *testing.T) {
+	q, err := Open("")
+	require.NoError(t, err)
+	for i := 0; i < 200; i++ {
+		queued, _ := q.Enqueue("861234567890123", "reboot", 0, 0)
+
+		var wg sync.WaitGroup
+		var claimed bool
+		var cancelErr error
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, claimed, _ = q.Claim("861234567890123")
+		}()
+		go func() {
+			defer wg.Done()
+			_, cancelErr = q.Cancel(queued.Id)
+		}()
+		wg.Wait()
+
+		if claimed {
+			require.ErrorIs(t, cancelErr, ErrCommandInFlight, "a claimed command can't be cancelled")
+			_, err := q.Remove(queued.Id)
+			require.NoError(t, err)
+		} else {
+			require.NoError(t, cancelErr)
+		}
+		require.Empty(t, q.List(""))
+	}
+}
+
+func TestQueueClaimKeepsCommandWhenSaveFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q, err := Open(path)
+	require.NoError(t, err)
+	queued, err := q.Enqueue("861234567890123", "reboot", 0, 0)
+	require.NoError(t, err)
+
+	// a directory where the temp file goes makes every save fail
+	require.NoError(t, os.Mkdir(path+".tmp", 0755))
+	_, ok, _ := q.Claim("861234567890123")
+	assert.False(t, ok)
+	require.NoError(t, os.Remove(path+".tmp"))
+
+	next, ok, _ := q.Claim("861234567890123")
+	require.True(t, ok, "the command wasn't left claimed")
+	assert.Equal(t, queued.Id, next.Id)
+	assert.Equal(t, int32(1), next.Attempts)
+}
+
+func TestQueueExpire(t *testing.T) {
+	q, err := Open(filepath.Join(t.TempDir(), "queue.json"))
+	require.NoError(t, err)
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	q.now = func() time.Time { return now }
+
+	offline, _ := q.Enqueue("861234567890123", "getinfo", 0, time.Minute)
+	inFlight, _ := q.Enqueue("350424063817363", "reboot", 1, time.Minute)
+	kept, _ := q.Enqueue("350424063817363", "getgps", 0, time.Hour)
+	_, ok, _ := q.Claim("350424063817363")
+	require.True(t, ok)
+
+	now = now.Add(2 * time.Minute)
+	expired := q.Expire()
+	require.Len(t, expired, 1)
+	assert.Equal(t, offline.Id, expired[0].Id)
+	assert.Empty(t, q.Expire())
+
+	q.mu.Lock()
+	assert.Contains(t, q.commands, inFlight.Id, "claimed commands are left to their delivery")
+	assert.Contains(t, q.commands, kept.Id)
+	q.mu.Unlock()
+}
+
+func TestQueuePersistsTypedCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q, err := Open(path)
+	require.NoError(t, err)
+
+	typed := commandcatalog.Command{Kind: commandcatalog.SetReportingInterval, IntervalSeconds: 60}
+	_, err = q.EnqueueTyped("861234567890123", typed, 0, 0)
+	require.NoError(t, err)
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	commands := reopened.List("861234567890123")
+	require.Len(t, commands, 1)
+	assert.Equal(t, "set_reporting_interval(60s)", commands[0].Command)
+	assert.Equal(t, &typed, commands[0].Typed)
+}
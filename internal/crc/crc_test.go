@@ -54,3 +54,14 @@ func TestTeltonikaCrc(t *testing.T) {
 		assert.Equal(t, testcase.expected, crc, "crc should match")
 	}
 }
+
+func TestWanwayCommandPacket(t *testing.T) {
+	// example from the protocol document, "sos#" with serial 1
+	expected, _ := hex.DecodeString("78780e800800000000736f7323" + "0001" + "6d6a" + "0d0a")
+	packet, err := wanwayCommandPacket("sos#", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, packet)
+
+	_, err = wanwayCommandPacket(strings.Repeat("x", 246), 1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,35 @@
+package crc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sync/atomic"
+)
+
+var wanwayCommandSerial atomic.Uint32
+
+// WanwayCommandPacket frames the command as an online command (0x80) packet for the gt06/tr06
+// devices, each with its own serial
+func WanwayCommandPacket(command string) ([]byte, error) {
+	return wanwayCommandPacket(command, uint16(wanwayCommandSerial.Add(1)))
+}
+
+func wanwayCommandPacket(command string, serial uint16) ([]byte, error) {
+	// protocol number, command length, server flag, serial and crc around the command
+	if len(command) > 0xff-10 {
+		return nil, fmt.Errorf("command too long: %d bytes", len(command))
+	}
+
+	var b bytes.Buffer
+	b.Write([]byte{0x78, 0x78})
+	b.WriteByte(byte(10 + len(command)))
+	b.WriteByte(0x80)
+	b.WriteByte(byte(4 + len(command)))
+	_ = binary.Write(&b, binary.BigEndian, uint32(0)) // server flag
+	b.WriteString(command)
+	_ = binary.Write(&b, binary.BigEndian, serial)
+	_ = binary.Write(&b, binary.BigEndian, CrcWanway(b.Bytes()[2:]))
+	b.Write([]byte{0x0d, 0x0a})
+	return b.Bytes(), nil
+}
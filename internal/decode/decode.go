@@ -0,0 +1,37 @@
+// Package decode holds what the protocols decode from a single frame outside of a connection, for
+// looking at device dumps offline.
+package decode
+
+import (
+	"errors"
+
+	"github.com/404minds/avl-receiver/internal/types"
+)
+
+// ErrIncomplete is a frame cut short, more bytes are needed to decode it
+var ErrIncomplete = errors.New("incomplete frame")
+
+// Frame is one decoded frame
+type Frame struct {
+	// Kind of frame, e.g. "login" or "codec 8E avl data"
+	Kind string
+	// Packet is the protocol's own struct, e.g. *fm1200.AvlDataPacket or *gt06.Packet
+	Packet    interface{}
+	Statuses  []*types.DeviceStatus
+	Responses []*types.DeviceResponse
+	// Checksum is nil for frames without one
+	Checksum *Checksum
+	// Size is the number of bytes the frame took
+	Size int
+}
+
+// Checksum of a frame, the crc or whatever the protocol uses in its place
+type Checksum struct {
+	Name       string // e.g. "crc16" or "xor"
+	Sent       uint32
+	Calculated uint32
+}
+
+func (c *Checksum) Valid() bool {
+	return c.Sent == c.Calculated
+}
@@ -11,6 +11,10 @@ var ErrUnauthorizedDevice = errors.New("unauthorized device")
 var ErrBadCrc = errors.New("bad crc")
 var ErrBadPacket = errors.New("bad data packet")
 var ErrSendingResponse = errors.New("error while sending response packet")
+var ErrStoreUnavailable = errors.New("data store unavailable")
+var ErrDeviceBanned = errors.New("device banned")
+var ErrParserPanic = errors.New("parser panic") // a bug a parser recovered from, not a bad packet
+var ErrFrameTooLarge = fmt.Errorf("frame too large: %w", ErrBadPacket)
 
 var ErrFM1200BadDataPacket = fmt.Errorf("bad fm1200 data packet: %w", ErrBadPacket)
 var ErrTR06BadDataPacket = errors.New("invalid tr06 data packet")
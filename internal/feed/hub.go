@@ -0,0 +1,123 @@
+package feed
+
+import (
+	"errors"
+	"sync"
+
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	"github.com/404minds/avl-receiver/internal/types"
+)
+
+var logger = configuredLogger.Logger
+
+const (
+	DefaultBufferSize = 256
+	MaxBufferSize     = 4096
+)
+
+var ErrSlowConsumer = errors.New("subscriber fell behind the live feed and was dropped")
+
+// Filter picks the statuses a subscriber wants, an empty list matches everything
+type Filter struct {
+	Imeis        []string
+	DeviceTypes  []types.DeviceType
+	MessageTypes []string
+}
+
+func (f Filter) matches(status *types.DeviceStatus) bool {
+	if len(f.Imeis) > 0 && !contains(f.Imeis, status.Imei) {
+		return false
+	}
+	if len(f.DeviceTypes) > 0 && !contains(f.DeviceTypes, status.DeviceType) {
+		return false
+	}
+	if len(f.MessageTypes) > 0 && !contains(f.MessageTypes, status.MessageType) {
+		return false
+	}
+	return true
+}
+
+func contains[T comparable](list []T, v T) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscription receives matching statuses on C until it is unsubscribed or evicted.
+// A subscriber whose buffer is full when a status comes in is evicted rather than
+// slowing down the device connections publishing to the hub
+type Subscription struct {
+	C       <-chan *types.DeviceStatus
+	c       chan *types.DeviceStatus
+	filter  Filter
+	evicted chan struct{}
+}
+
+// Evicted is closed when the subscriber was dropped for not keeping up
+func (s *Subscription) Evicted() <-chan struct{} {
+	return s.evicted
+}
+
+// Hub fans decoded device statuses out to live subscribers
+type Hub struct {
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subscribers: make(map[*Subscription]struct{})}
+}
+
+func (h *Hub) Subscribe(filter Filter, bufferSize int) *Subscription {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	if bufferSize > MaxBufferSize {
+		bufferSize = MaxBufferSize
+	}
+
+	c := make(chan *types.DeviceStatus, bufferSize)
+	sub := &Subscription{C: c, c: c, filter: filter, evicted: make(chan struct{})}
+
+	h.mu.Lock()
+	h.subscribers[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	delete(h.subscribers, sub)
+	h.mu.Unlock()
+}
+
+// Publish never blocks, subscribers that can't take the status right away are evicted
+func (h *Hub) Publish(status *types.DeviceStatus) {
+	if status == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subscribers {
+		if !sub.filter.matches(status) {
+			continue
+		}
+		select {
+		case sub.c <- status:
+		default:
+			delete(h.subscribers, sub)
+			close(sub.evicted)
+			logger.Sugar().Warnf("evicted slow position subscriber, %d statuses buffered", len(sub.c))
+		}
+	}
+}
+
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers)
+}
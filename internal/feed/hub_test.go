@@ -0,0 +1,76 @@
+package feed
+
+import (
+	"testing"
+
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func status(imei string, deviceType types.DeviceType, messageType string) *types.DeviceStatus {
+	return &types.DeviceStatus{Imei: imei, DeviceType: deviceType, MessageType: messageType}
+}
+
+func drain(sub *Subscription) []*types.DeviceStatus {
+	var got []*types.DeviceStatus
+	for {
+		select {
+		case s := <-sub.C:
+			got = append(got, s)
+		default:
+			return got
+		}
+	}
+}
+
+func TestHubFilters(t *testing.T) {
+	hub := NewHub()
+	all := hub.Subscribe(Filter{}, 10)
+	byImei := hub.Subscribe(Filter{Imeis: []string{"861234567890123"}}, 10)
+	byType := hub.Subscribe(Filter{DeviceTypes: []types.DeviceType{types.DeviceType_CONCOX}, MessageTypes: []string{"location"}}, 10)
+
+	first := status("861234567890123", types.DeviceType_AQUILA, "location")
+	second := status("350424063817363", types.DeviceType_CONCOX, "location")
+	third := status("350424063817363", types.DeviceType_CONCOX, "heartbeat")
+	for _, s := range []*types.DeviceStatus{first, second, third} {
+		hub.Publish(s)
+	}
+
+	assert.Equal(t, []*types.DeviceStatus{first, second, third}, drain(all))
+	assert.Equal(t, []*types.DeviceStatus{first}, drain(byImei))
+	assert.Equal(t, []*types.DeviceStatus{second}, drain(byType))
+}
+
+func TestHubEvictsSlowSubscriber(t *testing.T) {
+	hub := NewHub()
+	slow := hub.Subscribe(Filter{}, 2)
+	fast := hub.Subscribe(Filter{}, 10)
+
+	for i := 0; i < 3; i++ {
+		hub.Publish(status("861234567890123", types.DeviceType_AQUILA, "location"))
+	}
+
+	select {
+	case <-slow.Evicted():
+	default:
+		require.Fail(t, "slow subscriber not evicted")
+	}
+	assert.Len(t, drain(slow), 2, "statuses buffered before the eviction are kept")
+	assert.Len(t, drain(fast), 3)
+	assert.Equal(t, 1, hub.Subscribers())
+
+	hub.Publish(status("861234567890123", types.DeviceType_AQUILA, "location"))
+	assert.Empty(t, drain(slow))
+}
+
+func TestHubUnsubscribe(t *testing.T) {
+	hub := NewHub()
+	sub := hub.Subscribe(Filter{}, 0)
+	assert.Equal(t, DefaultBufferSize, cap(sub.C))
+
+	hub.Unsubscribe(sub)
+	hub.Publish(status("861234567890123", types.DeviceType_AQUILA, "location"))
+	assert.Empty(t, drain(sub))
+	assert.Zero(t, hub.Subscribers())
+}
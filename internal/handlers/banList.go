@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Ban keeps a device out until it expires, by its imei or by the ip it connects from
+type Ban struct {
+	Imei      string // one of Imei or IP
+	IP        string
+	Reason    string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// key is the imei or the ip, an imei's digits can't be mistaken for an ip
+func (b Ban) key() string {
+	if b.Imei != "" {
+		return b.Imei
+	}
+	return b.IP
+}
+
+type banList struct {
+	now func() time.Time
+
+	mu   sync.Mutex
+	bans map[string]Ban
+}
+
+func newBanList() *banList {
+	return &banList{now: time.Now, bans: make(map[string]Ban)}
+}
+
+func (l *banList) add(ban Ban) Ban {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ban.CreatedAt = l.now()
+	l.bans[ban.key()] = ban
+	return ban
+}
+
+func (l *banList) remove(key string) (Ban, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ban, ok := l.bans[key]
+	delete(l.bans, key)
+	return ban, ok && l.now().Before(ban.ExpiresAt)
+}
+
+// banned is the imei's or ip's ban if it hasn't expired yet
+func (l *banList) banned(key string) (Ban, bool) {
+	if key == "" {
+		return Ban{}, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	ban, ok := l.bans[key]
+	if !ok {
+		return Ban{}, false
+	}
+	if !l.now().Before(ban.ExpiresAt) {
+		delete(l.bans, key)
+		return Ban{}, false
+	}
+	return ban, true
+}
+
+// list is the bans that haven't expired, the ones expiring first first
+func (l *banList) list() []Ban {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	bans := make([]Ban, 0, len(l.bans))
+	for key, ban := range l.bans {
+		if !now.Before(ban.ExpiresAt) {
+			delete(l.bans, key)
+			continue
+		}
+		bans = append(bans, ban)
+	}
+	sort.Slice(bans, func(i, j int) bool { return bans[i].ExpiresAt.Before(bans[j].ExpiresAt) })
+	return bans
+}
+
+// AddBan turns away the ban's imei or ip until it expires and closes their connections, a ban of
+// the same imei or ip is replaced
+func (t *TcpHandler) AddBan(ban Ban) Ban {
+	ban = t.bans.add(ban)
+	logger.Warn("banned device", zap.String("imei", ban.Imei), zap.String("ip", ban.IP),
+		zap.String("reason", ban.Reason), zap.Time("expiresAt", ban.ExpiresAt))
+
+	t.mu.RLock()
+	var sessions []*deviceSession
+	for _, session := range t.sessions {
+		if session.imei == ban.key() || remoteIP(session.conn) == ban.key() {
+			sessions = append(sessions, session)
+		}
+	}
+	t.mu.RUnlock()
+	for _, session := range sessions {
+		session.close()
+	}
+	return ban
+}
+
+// RemoveBan lifts the imei's or ip's ban, false if there was none
+func (t *TcpHandler) RemoveBan(imeiOrIP string) (Ban, bool) {
+	ban, ok := t.bans.remove(imeiOrIP)
+	if ok {
+		logger.Info("lifted ban", zap.String("imei", ban.Imei), zap.String("ip", ban.IP))
+	}
+	return ban, ok
+}
+
+// Bans are the bans in force, the ones expiring first first
+func (t *TcpHandler) Bans() []Ban {
+	return t.bans.list()
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBanListExpiry(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	bans := newBanList()
+	bans.now = func() time.Time { return now }
+
+	bans.add(Ban{Imei: "356307043721579", Reason: "flooding", ExpiresAt: now.Add(time.Hour)})
+	bans.add(Ban{IP: "10.0.0.1", ExpiresAt: now.Add(time.Minute)})
+
+	ban, ok := bans.banned("356307043721579")
+	require.True(t, ok)
+	assert.Equal(t, "flooding", ban.Reason)
+	assert.Equal(t, now, ban.CreatedAt)
+	_, ok = bans.banned("")
+	assert.False(t, ok)
+
+	list := bans.list()
+	require.Len(t, list, 2)
+	assert.Equal(t, "10.0.0.1", list[0].IP, "the one expiring first comes first")
+
+	now = now.Add(2 * time.Minute)
+	_, ok = bans.banned("10.0.0.1")
+	assert.False(t, ok, "expired")
+	assert.Len(t, bans.list(), 1)
+
+	_, ok = bans.remove("356307043721579")
+	assert.True(t, ok)
+	_, ok = bans.remove("356307043721579")
+	assert.False(t, ok)
+	_, ok = bans.banned("356307043721579")
+	assert.False(t, ok)
+}
+
+func TestAddBanClosesSessions(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{Local: store.RotationConfig{Dir: t.TempDir()}})
+	login, _ := hex.DecodeString("000F333536333037303433373231353739")
+	device, done := serveGuarded(&handler)
+	defer device.Close()
+	_, err := device.Write(login)
+	require.NoError(t, err)
+	_, err = io.ReadFull(device, make([]byte, 1))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return handler.IsConnected("356307043721579") }, time.Second, 5*time.Millisecond)
+
+	handler.AddBan(Ban{Imei: "356307043721579", ExpiresAt: time.Now().Add(time.Hour)})
+	closedWithin(t, done, time.Second)
+
+	// and it can't log in again
+	before := testutil.ToFloat64(loginsTotal.WithLabelValues("failed", "banned"))
+	device, done = serveGuarded(&handler)
+	defer device.Close()
+	_, err = device.Write(login)
+	require.NoError(t, err)
+	closedWithin(t, done, time.Second)
+	assert.Equal(t, before+1, testutil.ToFloat64(loginsTotal.WithLabelValues("failed", "banned")))
+}
+
+func TestBannedIPIsRejected(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	server, _ := net.Pipe()
+	handler.AddBan(Ban{IP: remoteIP(server), ExpiresAt: time.Now().Add(time.Hour)})
+
+	ok, reason := handler.admit(server)
+	assert.False(t, ok)
+	assert.Equal(t, "banned", reason)
+
+	handler.RemoveBan(remoteIP(server))
+	ok, _ = handler.admit(server)
+	assert.True(t, ok)
+}
@@ -0,0 +1,168 @@
+package handlers
+
+import (
+	"context"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/commandcatalog"
+	"github.com/404minds/avl-receiver/internal/commandqueue"
+	"github.com/404minds/avl-receiver/internal/store"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// a queued command that couldn't be written this many times is given up on
+	maxQueuedCommandAttempts = 5
+	queuedCommandTimeout     = 30 * time.Second
+	DefaultQueuedCommandTTL  = 24 * time.Hour
+	queuedCommandSweep       = time.Minute
+)
+
+// UseCommandQueue replaces the in-memory queue with a persistent one
+func (t *TcpHandler) UseCommandQueue(queue *commandqueue.Queue) {
+	t.commandQueue = queue
+}
+
+func (t *TcpHandler) EnqueueCommand(imei string, command string, priority int32, ttl time.Duration) (commandqueue.Command, error) {
+	queued, err := t.commandQueue.Enqueue(imei, command, priority, ttl)
+	if err != nil {
+		return queued, err
+	}
+	return t.afterEnqueue(queued), nil
+}
+
+// EnqueueTypedCommand queues a typed command, it's encoded once the device's protocol is known at delivery
+func (t *TcpHandler) EnqueueTypedCommand(imei string, command commandcatalog.Command, priority int32, ttl time.Duration) (commandqueue.Command, error) {
+	if err := command.Validate(); err != nil {
+		return commandqueue.Command{}, err
+	}
+	queued, err := t.commandQueue.EnqueueTyped(imei, command, priority, ttl)
+	if err != nil {
+		return queued, err
+	}
+	return t.afterEnqueue(queued), nil
+}
+
+func (t *TcpHandler) afterEnqueue(queued commandqueue.Command) commandqueue.Command {
+	logger.Info("queued command", zap.String("imei", queued.Imei), zap.String("commandId", queued.Id))
+
+	// the device may be online already
+	if t.IsConnected(queued.Imei) {
+		go t.deliverQueuedCommands(queued.Imei)
+	}
+	return queued
+}
+
+func (t *TcpHandler) ListQueuedCommands(imei string) []commandqueue.Command {
+	return t.commandQueue.List(imei)
+}
+
+func (t *TcpHandler) CancelQueuedCommand(id string) (commandqueue.Command, error) {
+	cancelled, err := t.commandQueue.Cancel(id)
+	if err != nil {
+		return cancelled, err
+	}
+	t.reportCommandDelivery(cancelled, store.CommandDeliveryStatus_DELIVERY_CANCELLED, CommandResult{})
+	return cancelled, nil
+}
+
+// ExpireQueuedCommands drops and reports the expired commands of devices that stay offline, which
+// the delivery after a login would otherwise only find then. It runs until the context is done.
+func (t *TcpHandler) ExpireQueuedCommands(ctx context.Context) {
+	ticker := time.NewTicker(queuedCommandSweep)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.expireQueuedCommands()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (t *TcpHandler) expireQueuedCommands() {
+	for _, c := range t.commandQueue.Expire() {
+		t.reportCommandDelivery(c, store.CommandDeliveryStatus_DELIVERY_EXPIRED, CommandResult{})
+	}
+}
+
+// deliverQueuedCommands sends the device's queued commands one by one, highest priority first,
+// until the queue is empty or the device can't be written to
+func (t *TcpHandler) deliverQueuedCommands(imei string) {
+	for {
+		next, ok, expired := t.commandQueue.Claim(imei)
+		for _, c := range expired {
+			t.reportCommandDelivery(c, store.CommandDeliveryStatus_DELIVERY_EXPIRED, CommandResult{})
+		}
+		if !ok {
+			return
+		}
+
+		var result CommandResult
+		if next.Typed != nil {
+			result = t.SendTypedCommand(context.Background(), imei, next.Id, *next.Typed, queuedCommandTimeout)
+		} else {
+			result = t.SendCommand(context.Background(), imei, next.Id, next.Command, queuedCommandTimeout)
+		}
+		switch result.Status {
+		case store.CommandStatus_COMMAND_ACK, store.CommandStatus_COMMAND_NACK, store.CommandStatus_COMMAND_TIMEOUT:
+			// the command reached the device, a missing reply doesn't mean it should be sent again
+			status := store.CommandDeliveryStatus_DELIVERY_ACK
+			if result.Status == store.CommandStatus_COMMAND_NACK {
+				status = store.CommandDeliveryStatus_DELIVERY_NACK
+			} else if result.Status == store.CommandStatus_COMMAND_TIMEOUT {
+				status = store.CommandDeliveryStatus_DELIVERY_NO_REPLY
+			}
+			if _, err := t.commandQueue.Remove(next.Id); err != nil {
+				logger.Error("failed to remove delivered command", zap.String("commandId", next.Id), zap.Error(err))
+			}
+			t.reportCommandDelivery(next, status, result)
+		case store.CommandStatus_COMMAND_UNSUPPORTED:
+			if _, err := t.commandQueue.Remove(next.Id); err != nil {
+				logger.Error("failed to remove unsupported command", zap.String("commandId", next.Id), zap.Error(err))
+			}
+			t.reportCommandDelivery(next, store.CommandDeliveryStatus_DELIVERY_FAILED, result)
+		default:
+			if next.Attempts >= maxQueuedCommandAttempts {
+				if _, err := t.commandQueue.Remove(next.Id); err != nil {
+					logger.Error("failed to remove undeliverable command", zap.String("commandId", next.Id), zap.Error(err))
+				}
+				t.reportCommandDelivery(next, store.CommandDeliveryStatus_DELIVERY_FAILED, result)
+				continue
+			}
+			// the connection is gone, try again after the next login
+			t.commandQueue.Release(next.Id)
+			logger.Warn("queued command not delivered", zap.String("imei", imei), zap.String("commandId", next.Id),
+				zap.String("status", result.Status.String()), zap.String("message", result.Message))
+			return
+		}
+	}
+}
+
+func (t *TcpHandler) reportCommandDelivery(c commandqueue.Command, status store.CommandDeliveryStatus, result CommandResult) {
+	logger.Info("queued command outcome", zap.String("imei", c.Imei), zap.String("commandId", c.Id), zap.String("status", status.String()))
+	if !t.remoteStoreClient.IsConfigured() {
+		return
+	}
+
+	delivery := &store.CommandDelivery{
+		Imei:       c.Imei,
+		CommandId:  c.Id,
+		Command:    c.Command,
+		Status:     status,
+		Response:   result.Response,
+		EnqueuedAt: timestamppb.New(c.EnqueuedAt),
+		ReportedAt: timestamppb.Now(),
+		Attempts:   uint32(c.Attempts),
+		LatencyMs:  uint32(result.Latency.Milliseconds()),
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, err := t.remoteStoreClient.SaveCommandDelivery(ctx, delivery); err != nil {
+			logger.Error("failed to report command delivery", zap.String("commandId", c.Id), zap.Error(err))
+		}
+	}()
+}
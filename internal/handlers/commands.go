@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/commandcatalog"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"go.uber.org/zap"
+)
+
+const defaultCommandTimeout = 30 * time.Second
+
+// reply prefixes the supported devices use to reject a command
+var nackPrefixes = []string{"ERROR", "NACK", "FAIL", "INVALID", "UNKNOWN COMMAND", "WRONG"}
+
+type CommandResult struct {
+	RequestId string
+	Response  string
+	Status    store.CommandStatus
+	Latency   time.Duration
+	Message   string
+}
+
+type pendingCommand struct {
+	requestId string
+	reply     chan *types.DeviceResponse
+}
+
+// commandTracker matches device replies to the commands waiting for them. Device replies carry
+// no request id, so commands to a device are sent one at a time and the next reply from the
+// device once the command starts going out belongs to it. The command waits for it before the
+// write, so a device answering before the write returns isn't missed. A reply the device sends
+// on its own in the meantime is still taken for the command's, nothing in it tells them apart.
+type commandTracker struct {
+	mu       sync.Mutex
+	inFlight map[string]*commandSlot // per imei, only while a command is in flight or waiting
+	pending  map[string]*pendingCommand
+}
+
+// commandSlot holds a token while a command to the device is in flight
+type commandSlot struct {
+	token chan struct{}
+	users int // commands holding or waiting for the token
+}
+
+func newCommandTracker() *commandTracker {
+	return &commandTracker{
+		inFlight: make(map[string]*commandSlot),
+		pending:  make(map[string]*pendingCommand),
+	}
+}
+
+// acquire waits for the command in flight to the device, release has to be called once it's done
+func (c *commandTracker) acquire(ctx context.Context, imei string) (release func(), err error) {
+	c.mu.Lock()
+	slot, ok := c.inFlight[imei]
+	if !ok {
+		slot = &commandSlot{token: make(chan struct{}, 1)}
+		c.inFlight[imei] = slot
+	}
+	slot.users++
+	c.mu.Unlock()
+
+	select {
+	case slot.token <- struct{}{}:
+		return func() {
+			<-slot.token
+			c.leave(imei, slot)
+		}, nil
+	case <-ctx.Done():
+		c.leave(imei, slot)
+		return nil, ctx.Err()
+	}
+}
+
+// leave drops the device's slot once nothing holds or waits for it
+func (c *commandTracker) leave(imei string, slot *commandSlot) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	slot.users--
+	if slot.users == 0 && c.inFlight[imei] == slot {
+		delete(c.inFlight, imei)
+	}
+}
+
+func (c *commandTracker) register(imei string, requestId string) *pendingCommand {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := &pendingCommand{requestId: requestId, reply: make(chan *types.DeviceResponse, 1)}
+	c.pending[imei] = p
+	return p
+}
+
+func (c *commandTracker) unregister(imei string, p *pendingCommand) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.pending[imei] == p {
+		delete(c.pending, imei)
+	}
+}
+
+// deliver hands the reply to the command waiting on the device, if any
+func (c *commandTracker) deliver(response *types.DeviceResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, ok := c.pending[response.Imei]
+	if !ok {
+		return
+	}
+	delete(c.pending, response.Imei)
+	p.reply <- response
+}
+
+func newRequestId() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func classifyCommandReply(response string) store.CommandStatus {
+	reply := strings.ToUpper(strings.TrimSpace(response))
+	for _, prefix := range nackPrefixes {
+		if strings.HasPrefix(reply, prefix) {
+			return store.CommandStatus_COMMAND_NACK
+		}
+	}
+	return store.CommandStatus_COMMAND_ACK
+}
+
+// SendTypedCommand encodes the command for the connected device's protocol and sends it like SendCommand
+func (t *TcpHandler) SendTypedCommand(ctx context.Context, imei string, requestId string, command commandcatalog.Command, timeout time.Duration) CommandResult {
+	session, exists := t.session(imei)
+	if !exists {
+		return t.SendCommand(ctx, imei, requestId, "", timeout)
+	}
+
+	raw, err := commandcatalog.Encode(session.protocol.GetProtocolType(), command)
+	if err != nil {
+		if requestId == "" {
+			requestId = newRequestId()
+		}
+		result := CommandResult{RequestId: requestId, Status: store.CommandStatus_COMMAND_UNSUPPORTED, Message: err.Error()}
+		recordCommandResult(result)
+		return result
+	}
+	logger.Info("encoded typed command", zap.String("imei", imei), zap.String("command", command.String()),
+		zap.String("protocol", session.protocol.GetProtocolType().String()))
+	return t.SendCommand(ctx, imei, requestId, raw, timeout)
+}
+
+// SendCommand sends the command to the connected device and waits up to timeout for its reply
+func (t *TcpHandler) SendCommand(ctx context.Context, imei string, requestId string, command string, timeout time.Duration) CommandResult {
+	result := t.sendCommand(ctx, imei, requestId, command, timeout)
+	recordCommandResult(result)
+	return result
+}
+
+func (t *TcpHandler) sendCommand(ctx context.Context, imei string, requestId string, command string, timeout time.Duration) CommandResult {
+	if requestId == "" {
+		requestId = newRequestId()
+	}
+	if timeout <= 0 {
+		timeout = defaultCommandTimeout
+	}
+	result := CommandResult{RequestId: requestId}
+
+	session, exists := t.session(imei)
+	if !exists {
+		result.Status = store.CommandStatus_COMMAND_NOT_CONNECTED
+		result.Message = "Device not found"
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	release, err := t.commands.acquire(ctx, imei)
+	if err != nil {
+		result.Status = store.CommandStatus_COMMAND_TIMEOUT
+		result.Message = "Timed out waiting for the previous command to the device"
+		return result
+	}
+	defer release()
+
+	logger.Info("sending command to device", zap.String("imei", imei), zap.String("requestId", requestId),
+		zap.String("remoteAddr", session.conn.RemoteAddr().String()))
+	sentAt := time.Now()
+	// encode first so the command goes out in a single write between the protocol's acks
+	var encoded bytes.Buffer
+	if err := session.protocol.SendCommandToDevice(&encoded, command); err != nil {
+		result.Status = store.CommandStatus_COMMAND_SEND_FAILED
+		result.Message = "Failed to send command: " + err.Error()
+		return result
+	}
+	// registered before the write, the device can answer before it returns. When the write
+	// fails whatever came in meanwhile wasn't a reply to this and goes with the slot.
+	pending := t.commands.register(imei, requestId)
+	defer t.commands.unregister(imei, pending)
+	if err := session.write(encoded.Bytes()); err != nil {
+		result.Status = store.CommandStatus_COMMAND_SEND_FAILED
+		result.Message = "Failed to send command: " + err.Error()
+		return result
+	}
+
+	select {
+	case reply := <-pending.reply:
+		result.Latency = time.Since(sentAt)
+		result.Response = reply.Response
+		result.Status = classifyCommandReply(reply.Response)
+		result.Message = "Device replied"
+		logger.Info("device replied to command", zap.String("imei", imei), zap.String("requestId", requestId),
+			zap.String("status", result.Status.String()), zap.Duration("latency", result.Latency))
+	case <-ctx.Done():
+		result.Status = store.CommandStatus_COMMAND_TIMEOUT
+		result.Message = "Command sent, no reply from the device"
+		logger.Warn("no reply to command", zap.String("imei", imei), zap.String("requestId", requestId))
+	}
+	return result
+}
@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/commandcatalog"
+	"github.com/404minds/avl-receiver/internal/commandqueue"
+	"github.com/404minds/avl-receiver/internal/protocols/gt06"
+	"github.com/404minds/avl-receiver/internal/protocols/howen"
+	"github.com/404minds/avl-receiver/internal/protocols/obdii2g"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// connectFakeDevice registers one end of a pipe as the device's connection and returns the device end
+func connectFakeDevice(t *testing.T, handler *TcpHandler, imei string) *bufio.Reader {
+	server, device := net.Pipe()
+	t.Cleanup(func() {
+		server.Close()
+		device.Close()
+	})
+
+	handler.registerSession(newDeviceSession(imei, server, &obdii2g.AquilaOBDII2GProtocol{}, newConnectionStats()))
+	return bufio.NewReader(device)
+}
+
+// replyToCommand hands the device's reply to the command once it is waiting for one
+func replyToCommand(t *testing.T, handler *TcpHandler, response *types.DeviceResponse) {
+	assert.Eventually(t, func() bool {
+		handler.commands.mu.Lock()
+		defer handler.commands.mu.Unlock()
+		return handler.commands.pending[response.Imei] != nil
+	}, time.Second, time.Millisecond)
+	handler.commands.deliver(response)
+}
+
+func TestSendCommandWaitsForReply(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	device := connectFakeDevice(t, &handler, "861234567890123")
+
+	go func() {
+		line, _ := device.ReadString('\n')
+		assert.Equal(t, "#SET_INTERVAL,30\r\n", line)
+		time.Sleep(20 * time.Millisecond)
+		replyToCommand(t, &handler, &types.DeviceResponse{Imei: "861234567890123", Response: "SET_INTERVAL OK"})
+	}()
+
+	result := handler.SendCommand(context.Background(), "861234567890123", "req-1", "SET_INTERVAL,30", time.Second)
+	assert.Equal(t, "req-1", result.RequestId)
+	assert.Equal(t, store.CommandStatus_COMMAND_ACK, result.Status)
+	assert.Equal(t, "SET_INTERVAL OK", result.Response)
+	assert.GreaterOrEqual(t, result.Latency, 20*time.Millisecond)
+}
+
+func TestSendCommandReplyBeforeWriteReturns(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	device := connectFakeDevice(t, &handler, "861234567890123")
+
+	go func() {
+		// the pipe's write is still blocked on the rest of the line when the reply comes in
+		first := make([]byte, 1)
+		_, _ = device.Read(first)
+		handler.commands.deliver(&types.DeviceResponse{Imei: "861234567890123", Response: "RELAY OK"})
+		device.ReadString('\n')
+	}()
+
+	result := handler.SendCommand(context.Background(), "861234567890123", "req-1", "RELAY,1", time.Second)
+	assert.Equal(t, store.CommandStatus_COMMAND_ACK, result.Status)
+	assert.Equal(t, "RELAY OK", result.Response)
+}
+
+func TestSendCommandWriteFailsDiscardsReply(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	server, device := net.Pipe()
+	defer device.Close()
+	handler.registerSession(newDeviceSession("861234567890123", server, &obdii2g.AquilaOBDII2GProtocol{}, newConnectionStats()))
+
+	go func() {
+		first := make([]byte, 1)
+		_, _ = device.Read(first)
+		handler.commands.deliver(&types.DeviceResponse{Imei: "861234567890123", Response: "unrelated"})
+		server.Close()
+	}()
+
+	result := handler.SendCommand(context.Background(), "861234567890123", "req-1", "RELAY,1", time.Second)
+	assert.Equal(t, store.CommandStatus_COMMAND_SEND_FAILED, result.Status)
+	assert.Empty(t, result.Response)
+	handler.commands.mu.Lock()
+	assert.Empty(t, handler.commands.pending, "the slot goes with the failed write")
+	handler.commands.mu.Unlock()
+}
+
+func TestSendCommandNack(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	device := connectFakeDevice(t, &handler, "861234567890123")
+
+	go func() {
+		device.ReadString('\n')
+		replyToCommand(t, &handler, &types.DeviceResponse{Imei: "861234567890123", Response: "ERROR: unknown parameter"})
+	}()
+
+	result := handler.SendCommand(context.Background(), "861234567890123", "", "BOGUS", time.Second)
+	assert.NotEmpty(t, result.RequestId, "request id is generated")
+	assert.Equal(t, store.CommandStatus_COMMAND_NACK, result.Status)
+}
+
+func TestSendCommandTimesOut(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	device := connectFakeDevice(t, &handler, "861234567890123")
+	go func() {
+		for {
+			if _, err := device.ReadString('\n'); err != nil {
+				return
+			}
+		}
+	}()
+
+	result := handler.SendCommand(context.Background(), "861234567890123", "req-1", "REBOOT", 50*time.Millisecond)
+	assert.Equal(t, store.CommandStatus_COMMAND_TIMEOUT, result.Status)
+
+	// a late reply doesn't get matched to the next command
+	handler.commands.deliver(&types.DeviceResponse{Imei: "861234567890123", Response: "late"})
+	result = handler.SendCommand(context.Background(), "861234567890123", "req-2", "REBOOT", 50*time.Millisecond)
+	assert.Equal(t, store.CommandStatus_COMMAND_TIMEOUT, result.Status)
+}
+
+func TestCommandSlotsDroppedWhenIdle(t *testing.T) {
+	tracker := newCommandTracker()
+	release, err := tracker.acquire(context.Background(), "861234567890123")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = tracker.acquire(ctx, "861234567890123")
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "one command at a time")
+	assert.Len(t, tracker.inFlight, 1)
+
+	release()
+	assert.Empty(t, tracker.inFlight)
+}
+
+func TestSendCommandDeviceNotConnected(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	result := handler.SendCommand(context.Background(), "861234567890123", "req-1", "REBOOT", time.Second)
+	assert.Equal(t, store.CommandStatus_COMMAND_NOT_CONNECTED, result.Status)
+}
+
+// recordingStore collects the responses handed to it
+type recordingStore struct {
+	store.MultiStore
+	responses chan *types.DeviceResponse
+	closed    chan bool
+}
+
+func (s *recordingStore) Response(ctx context.Context) {
+	for {
+		select {
+		case r := <-s.ResponseChan:
+			s.responses <- r
+		case <-s.CloseResponseChan:
+			close(s.closed)
+			return
+		}
+	}
+}
+
+func TestTapStoreForwardsResponses(t *testing.T) {
+	inner := &recordingStore{
+		MultiStore: store.MultiStore{
+			ResponseChan:      make(chan *types.DeviceResponse, 1),
+			CloseResponseChan: make(chan bool, 1),
+		},
+		responses: make(chan *types.DeviceResponse, 1),
+		closed:    make(chan bool),
+	}
+	seen := make(chan *types.DeviceResponse, 1)
+	tap := &store.TapStore{
+		Store:             inner,
+		ResponseChan:      make(chan *types.DeviceResponse, 1),
+		CloseResponseChan: make(chan bool, 1),
+		OnResponse:        func(r *types.DeviceResponse) { seen <- r },
+	}
+	go tap.Response(context.Background())
+
+	response := &types.DeviceResponse{Imei: "861234567890123", Response: "OK"}
+	tap.GetResponseChan() <- response
+	assert.Same(t, response, <-seen)
+	assert.Same(t, response, <-inner.responses)
+
+	tap.GetCloseResponseChan() <- true
+	select {
+	case <-inner.closed:
+	case <-time.After(time.Second):
+		require.Fail(t, "close not forwarded to the wrapped store")
+	}
+}
+
+func TestQueuedCommandsDeliveredInPriorityOrder(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	low, err := handler.EnqueueCommand("861234567890123", "GETINFO", 0, time.Hour)
+	require.NoError(t, err)
+	_, err = handler.EnqueueCommand("861234567890123", "ENGINE_CUT", 10, time.Hour)
+	require.NoError(t, err)
+
+	device := connectFakeDevice(t, &handler, "861234567890123")
+	received := make(chan string, 2)
+	go func() {
+		for {
+			line, err := device.ReadString('\n')
+			if err != nil {
+				return
+			}
+			received <- line
+			replyToCommand(t, &handler, &types.DeviceResponse{Imei: "861234567890123", Response: "OK"})
+		}
+	}()
+
+	handler.deliverQueuedCommands("861234567890123")
+	assert.Equal(t, "#ENGINE_CUT\r\n", <-received)
+	assert.Equal(t, "#GETINFO\r\n", <-received)
+	assert.Empty(t, handler.ListQueuedCommands(""))
+
+	_, err = handler.CancelQueuedCommand(low.Id)
+	assert.Error(t, err, "delivered commands can't be cancelled")
+}
+
+func TestQueuedCommandKeptWhenDeviceOffline(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	queued, err := handler.EnqueueCommand("861234567890123", "GETINFO", 0, time.Hour)
+	require.NoError(t, err)
+
+	handler.deliverQueuedCommands("861234567890123")
+	commands := handler.ListQueuedCommands("861234567890123")
+	require.Len(t, commands, 1)
+	assert.Equal(t, queued.Id, commands[0].Id)
+	assert.Equal(t, int32(1), commands[0].Attempts)
+}
+
+func TestSendTypedCommandEncodesForProtocol(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	server, device := net.Pipe()
+	t.Cleanup(func() {
+		server.Close()
+		device.Close()
+	})
+	handler.registerSession(newDeviceSession("861234567890123", server, &gt06.GT06Protocol{}, newConnectionStats()))
+
+	go func() {
+		packet := make([]byte, 256)
+		n, _ := device.Read(packet)
+		assert.Contains(t, string(packet[:n]), "TIMER,60#")
+		replyToCommand(t, &handler, &types.DeviceResponse{Imei: "861234567890123", Response: "TIMER OK"})
+	}()
+
+	result := handler.SendTypedCommand(context.Background(), "861234567890123", "", commandcatalog.Command{Kind: commandcatalog.SetReportingInterval, IntervalSeconds: 60}, time.Second)
+	assert.Equal(t, store.CommandStatus_COMMAND_ACK, result.Status)
+}
+
+func TestSendTypedCommandUnsupported(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	server, device := net.Pipe()
+	defer device.Close()
+	handler.registerSession(newDeviceSession("861234567890123", server, &howen.HOWENWS{}, newConnectionStats()))
+
+	result := handler.SendTypedCommand(context.Background(), "861234567890123", "req-1", commandcatalog.Command{Kind: commandcatalog.EngineCut}, time.Second)
+	assert.Equal(t, store.CommandStatus_COMMAND_UNSUPPORTED, result.Status)
+	assert.Equal(t, "req-1", result.RequestId)
+
+	result = handler.SendTypedCommand(context.Background(), "350424063817363", "", commandcatalog.Command{Kind: commandcatalog.EngineCut}, time.Second)
+	assert.Equal(t, store.CommandStatus_COMMAND_NOT_CONNECTED, result.Status)
+}
+
+// deliveryStore is a remote data store keeping the command deliveries reported to it
+type deliveryStore struct {
+	deliveries chan *store.CommandDelivery
+}
+
+func (s *deliveryStore) Invoke(ctx context.Context, method string, args any, reply any, opts ...grpc.CallOption) error {
+	if delivery, ok := args.(*store.CommandDelivery); ok {
+		s.deliveries <- delivery
+	}
+	return nil
+}
+
+func (s *deliveryStore) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, nil
+}
+
+func TestExpiredCommandsOfOfflineDevicesReported(t *testing.T) {
+	deliveries := &deliveryStore{deliveries: make(chan *store.CommandDelivery, 1)}
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	handler.remoteStoreClient = *store.NewCustomAvlDataStoreClient(deliveries, "store.AvlDataStore")
+
+	queued, err := handler.EnqueueCommand("861234567890123", "GETINFO", 0, time.Millisecond)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	handler.expireQueuedCommands()
+
+	assert.Empty(t, handler.ListQueuedCommands(""))
+	delivery := <-deliveries.deliveries
+	assert.Equal(t, queued.Id, delivery.CommandId)
+	assert.Equal(t, store.CommandDeliveryStatus_DELIVERY_EXPIRED, delivery.Status)
+}
+
+func TestCancelCommandBeingDelivered(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	queued, err := handler.EnqueueCommand("861234567890123", "GETINFO", 0, time.Hour)
+	require.NoError(t, err)
+	_, ok, _ := handler.commandQueue.Claim("861234567890123")
+	require.True(t, ok)
+
+	_, err = handler.CancelQueuedCommand(queued.Id)
+	assert.ErrorIs(t, err, commandqueue.ErrCommandInFlight)
+	assert.Len(t, handler.ListQueuedCommands(""), 1)
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/ratelimit"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const defaultLoginTimeout = 20 * time.Second
+
+// GuardConfig limits what clients take of the receiver before they log in, so port scanners and
+// broken clients can't crowd out the trackers
+type GuardConfig struct {
+	MaxConnections int             // open tcp connections, logged in or not, 0 is unlimited
+	IPRate         ratelimit.Limit // new connections from an ip, unlimited without a rate
+	LoginTimeout   time.Duration   // for sending a login the receiver recognizes, 20s if 0
+}
+
+var connectionsRejectedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "avl_connections_rejected_total",
+	Help: "Tcp connections closed before their login was read, by reason.",
+}, []string{"reason"})
+
+func init() {
+	prometheus.MustRegister(connectionsRejectedTotal)
+}
+
+type connectionGuard struct {
+	config GuardConfig
+	ips    *ratelimit.Buckets
+	open   atomic.Int64
+}
+
+// UseGuard limits the connections clients can open and how long they have to log in
+func (t *TcpHandler) UseGuard(config GuardConfig) {
+	t.guard = &connectionGuard{config: config, ips: ratelimit.New(config.IPRate)}
+}
+
+// admit turns away the banned ips too, and reports the ips going over their connection rate
+func (t *TcpHandler) admit(conn net.Conn) (ok bool, reason string) {
+	ip := remoteIP(conn)
+	if _, banned := t.bans.banned(ip); banned {
+		t.limitedDevice("", ip, store.DeviceLimitReason_LIMIT_BANNED)
+		return false, "banned"
+	}
+	ok, reason = t.guard.admit(conn)
+	if reason == "ip_rate" {
+		t.limitedDevice("", ip, store.DeviceLimitReason_LIMIT_CONNECTION_RATE)
+	}
+	return ok, reason
+}
+
+// admit counts the connection as open unless it's over a limit, release has to be called once it
+// closes if it's admitted. reason is the rejection's metric label.
+func (g *connectionGuard) admit(conn net.Conn) (ok bool, reason string) {
+	if g == nil {
+		return true, ""
+	}
+	if !g.ips.Allow(remoteIP(conn)) {
+		return false, "ip_rate"
+	}
+	if open := g.open.Add(1); g.config.MaxConnections > 0 && open > int64(g.config.MaxConnections) {
+		g.open.Add(-1)
+		return false, "max_connections"
+	}
+	return true, ""
+}
+
+func (g *connectionGuard) release() {
+	if g != nil {
+		g.open.Add(-1)
+	}
+}
+
+func (g *connectionGuard) loginTimeout() time.Duration {
+	if g == nil || g.config.LoginTimeout <= 0 {
+		return defaultLoginTimeout
+	}
+	return g.config.LoginTimeout
+}
+
+// remoteIP is the connection's ip without the port, the whole address if it has none
+func remoteIP(conn net.Conn) string {
+	addr := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
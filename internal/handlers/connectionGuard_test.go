@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	"github.com/404minds/avl-receiver/internal/ratelimit"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// serveGuarded hands a pipe to the handler, done is closed once the handler is done with it
+func serveGuarded(handler *TcpHandler) (device net.Conn, done chan struct{}) {
+	server, device := net.Pipe()
+	done = make(chan struct{})
+	go func() {
+		handler.HandleConnection(server)
+		close(done)
+	}()
+	return device, done
+}
+
+func closedWithin(t *testing.T, done chan struct{}, timeout time.Duration) {
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		t.Fatalf("connection still open after %s", timeout)
+	}
+}
+
+func TestGuardMaxConnections(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{Local: store.RotationConfig{Dir: t.TempDir()}})
+	handler.UseGuard(GuardConfig{MaxConnections: 1})
+	before := testutil.ToFloat64(connectionsRejectedTotal.WithLabelValues("max_connections"))
+
+	first, firstDone := serveGuarded(&handler)
+	login, _ := hex.DecodeString("000F333536333037303433373231353739")
+	_, err := first.Write(login)
+	require.NoError(t, err)
+	_, err = io.ReadFull(first, make([]byte, 1))
+	require.NoError(t, err)
+
+	_, secondDone := serveGuarded(&handler)
+	closedWithin(t, secondDone, time.Second)
+	assert.Equal(t, before+1, testutil.ToFloat64(connectionsRejectedTotal.WithLabelValues("max_connections")))
+
+	// the logged in device's connection frees its place once it's closed
+	first.Close()
+	closedWithin(t, firstDone, time.Second)
+	third, thirdDone := serveGuarded(&handler)
+	defer third.Close()
+	_, err = third.Write(login)
+	require.NoError(t, err)
+	_, err = io.ReadFull(third, make([]byte, 1))
+	require.NoError(t, err)
+	third.Close()
+	closedWithin(t, thirdDone, time.Second)
+}
+
+func TestGuardIPRate(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	handler.UseGuard(GuardConfig{IPRate: ratelimit.Limit{Rate: 0.001, Burst: 2}})
+
+	// pipes all have the same remote address
+	for i := 0; i < 2; i++ {
+		server, _ := net.Pipe()
+		ok, _ := handler.guard.admit(server)
+		assert.True(t, ok, "burst %d", i)
+	}
+	server, _ := net.Pipe()
+	ok, reason := handler.guard.admit(server)
+	assert.False(t, ok)
+	assert.Equal(t, "ip_rate", reason)
+	assert.Equal(t, int64(2), handler.guard.open.Load(), "rejected connections aren't counted as open")
+}
+
+func TestGuardLoginTimeout(t *testing.T) {
+	level := configuredLogger.Level()
+	configuredLogger.SetLevel(zapcore.FatalLevel)
+	defer configuredLogger.SetLevel(level)
+
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	handler.UseGuard(GuardConfig{LoginTimeout: 100 * time.Millisecond})
+	before := testutil.ToFloat64(loginsTotal.WithLabelValues("failed", "timeout"))
+
+	// a client that connects and says nothing
+	device, done := serveGuarded(&handler)
+	defer device.Close()
+	closedWithin(t, done, time.Second)
+	assert.Equal(t, before+1, testutil.ToFloat64(loginsTotal.WithLabelValues("failed", "timeout")))
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/capture"
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	"github.com/404minds/avl-receiver/internal/types"
+	"go.uber.org/zap"
+)
+
+// connectionStats is updated from the connection's read path and store tap,
+// and read by the inventory rpcs
+type connectionStats struct {
+	connectedAt     time.Time
+	bytesReceived   atomic.Uint64
+	packetsReceived atomic.Uint64
+	lastPacketAt    atomic.Int64           // unix nanos
+	imei            atomic.Pointer[string] // set once the device logged in, for tracing its traffic
+
+	mu             sync.Mutex
+	lastPosition   *types.GPSPosition
+	lastPositionAt time.Time
+}
+
+func newConnectionStats() *connectionStats {
+	return &connectionStats{connectedAt: time.Now()}
+}
+
+// countingReader counts the bytes read from the device, and captures them if the connection is
+// being captured
+type countingReader struct {
+	reader   io.Reader
+	stats    *connectionStats
+	recorder *capture.Recorder
+}
+
+func (r countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.stats.bytesReceived.Add(uint64(n))
+		r.stats.lastPacketAt.Store(time.Now().UnixNano())
+		r.recorder.Inbound(p[:n])
+		if imei := r.stats.imei.Load(); imei != nil {
+			logTraffic("read from device", *imei, p[:n])
+		}
+	}
+	return n, err
+}
+
+// logTraffic dumps the bytes at debug level, or for devices being traced
+func logTraffic(msg string, imei string, data []byte) {
+	if configuredLogger.DeviceEnabled(imei, zap.DebugLevel) {
+		logger.Debug(msg, zap.String("imei", imei), zap.Int("bytes", len(data)), zap.String("hex", hex.EncodeToString(data)))
+	}
+}
+
+func (s *connectionStats) recordStatus(status *types.DeviceStatus) {
+	s.packetsReceived.Add(1)
+	if status.GetPosition() == nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastPosition = status.GetPosition()
+	if status.GetTimestamp() != nil {
+		s.lastPositionAt = status.GetTimestamp().AsTime()
+	} else {
+		s.lastPositionAt = time.Now()
+	}
+}
+
+// ConnectionSnapshot describes a connected device at the time it was taken
+type ConnectionSnapshot struct {
+	Imei            string
+	Protocol        types.DeviceProtocolType
+	DeviceType      types.DeviceType
+	RemoteAddr      string
+	ConnectedAt     time.Time
+	LastPacketAt    time.Time
+	BytesReceived   uint64
+	PacketsReceived uint64 // decoded device statuses
+	LastPosition    *types.GPSPosition
+	LastPositionAt  time.Time
+}
+
+func snapshotConnection(session *deviceSession) ConnectionSnapshot {
+	snapshot := ConnectionSnapshot{
+		Imei:       session.imei,
+		RemoteAddr: session.conn.RemoteAddr().String(),
+		Protocol:   session.protocol.GetProtocolType(),
+		DeviceType: session.protocol.GetDeviceType(),
+	}
+
+	stats := session.stats
+	snapshot.ConnectedAt = stats.connectedAt
+	snapshot.BytesReceived = stats.bytesReceived.Load()
+	snapshot.PacketsReceived = stats.packetsReceived.Load()
+	if nanos := stats.lastPacketAt.Load(); nanos != 0 {
+		snapshot.LastPacketAt = time.Unix(0, nanos)
+	}
+
+	stats.mu.Lock()
+	snapshot.LastPosition = stats.lastPosition
+	snapshot.LastPositionAt = stats.lastPositionAt
+	stats.mu.Unlock()
+	return snapshot
+}
+
+// ListConnections returns the connected devices ordered by imei
+func (t *TcpHandler) ListConnections() []ConnectionSnapshot {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	snapshots := make([]ConnectionSnapshot, 0, len(t.sessions))
+	for _, session := range t.sessions {
+		snapshots = append(snapshots, snapshotConnection(session))
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].Imei < snapshots[j].Imei })
+	return snapshots
+}
+
+func (t *TcpHandler) GetConnection(imei string) (ConnectionSnapshot, bool) {
+	session, exists := t.session(imei)
+	if !exists {
+		return ConnectionSnapshot{}, false
+	}
+	return snapshotConnection(session), true
+}
+
+// DisconnectDevice closes the device's session, HandleConnection then cleans up
+// like for any other disconnect. It returns the state of the connection just before closing it
+func (t *TcpHandler) DisconnectDevice(imei string) (ConnectionSnapshot, bool) {
+	session, exists := t.session(imei)
+	if !exists {
+		return ConnectionSnapshot{}, false
+	}
+
+	snapshot := snapshotConnection(session)
+	session.close()
+	logger.Info("disconnected device on request", zap.String("imei", imei), zap.String("remoteAddr", snapshot.RemoteAddr))
+	return snapshot, true
+}
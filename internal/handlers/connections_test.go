@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/protocols/obdii2g"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestConnectionStats(t *testing.T) {
+	stats := newConnectionStats()
+	reader := bufio.NewReader(countingReader{reader: strings.NewReader("$$CLIENT,861234567890123,1\n"), stats: stats})
+	_, err := reader.ReadString('\n')
+	require.NoError(t, err)
+
+	fixTime := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	stats.recordStatus(&types.DeviceStatus{Imei: "861234567890123"})
+	stats.recordStatus(&types.DeviceStatus{
+		Imei:      "861234567890123",
+		Timestamp: timestamppb.New(fixTime),
+		Position:  &types.GPSPosition{Latitude: 12.97, Longitude: 77.59},
+	})
+	stats.recordStatus(&types.DeviceStatus{Imei: "861234567890123"})
+
+	server, device := net.Pipe()
+	defer device.Close()
+	session := newDeviceSession("861234567890123", server, &obdii2g.AquilaOBDII2GProtocol{DeviceType: types.DeviceType_AQUILA}, stats)
+	defer session.close()
+
+	snapshot := snapshotConnection(session)
+	assert.Equal(t, uint64(27), snapshot.BytesReceived)
+	assert.Equal(t, uint64(3), snapshot.PacketsReceived)
+	assert.WithinDuration(t, time.Now(), snapshot.LastPacketAt, time.Second)
+	assert.Equal(t, types.DeviceProtocolType_OBDII2G, snapshot.Protocol)
+	assert.Equal(t, types.DeviceType_AQUILA, snapshot.DeviceType)
+	assert.Equal(t, float32(12.97), snapshot.LastPosition.Latitude, "statuses without a position keep the last one")
+	assert.Equal(t, fixTime, snapshot.LastPositionAt)
+}
+
+func TestListAndDisconnectConnections(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	connectFakeDevice(t, &handler, "861234567890123")
+	device := connectFakeDevice(t, &handler, "350424063817363")
+
+	connections := handler.ListConnections()
+	require.Len(t, connections, 2)
+	assert.Equal(t, "350424063817363", connections[0].Imei)
+	assert.Equal(t, "861234567890123", connections[1].Imei)
+
+	_, connected := handler.GetConnection("000000000000000")
+	assert.False(t, connected)
+
+	snapshot, connected := handler.DisconnectDevice("350424063817363")
+	require.True(t, connected)
+	assert.Equal(t, "350424063817363", snapshot.Imei)
+
+	_, err := device.ReadByte()
+	assert.Error(t, err, "the device side sees the connection closed")
+
+	_, connected = handler.DisconnectDevice("000000000000000")
+	assert.False(t, connected)
+}
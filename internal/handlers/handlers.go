@@ -1,28 +1,52 @@
 package handlers
 
 import (
+	"github.com/404minds/avl-receiver/internal/commandqueue"
+	"github.com/404minds/avl-receiver/internal/feed"
 	"github.com/404minds/avl-receiver/internal/protocols"
 	"github.com/404minds/avl-receiver/internal/store"
 	"github.com/404minds/avl-receiver/internal/types"
 )
 
-func NewTcpHandler(remoteStoreClient store.CustomAvlDataStoreClient, storeType string) TcpHandler {
+// Sinks are the shared clients behind the store types other than local and remote
+type Sinks struct {
+	Mqtt     *store.MqttPublisher
+	Kafka    *store.KafkaPublisher
+	Postgres *store.PostgresWriter
+	Sqlite   *store.SqliteDB // also the device registry when there is no remote store
+	Local    store.RotationConfig
+}
+
+func NewTcpHandler(remoteStoreClient store.CustomAvlDataStoreClient, storeType string, sinks Sinks) TcpHandler {
 	return TcpHandler{
 		connToProtocolMap: make(map[string]protocols.DeviceProtocol),
 		allowedProtocols:  []types.DeviceProtocolType{types.DeviceProtocolType_INTELLITRAC_A, types.DeviceProtocolType_OBDII2G, types.DeviceProtocolType_GT06, types.DeviceProtocolType_TR06, types.DeviceProtocolType_FM1200}, // registered device types can be made configurable to enable/disable a device-type at once
 		connToStoreMap:    make(map[string]store.Store),
 		remoteStoreClient: remoteStoreClient,
 		storeType:         storeType,
-		imeiToConnMap:     make(map[string]DeviceConnectionInfo),
+		sinks:             sinks,
+		sessions:          make(map[string]*deviceSession),
+		commands:          newCommandTracker(),
+		commandQueue:      memoryCommandQueue(),
+		positions:         feed.NewHub(),
+		bans:              newBanList(),
+		limited:           newLimitReporter(),
 	}
 }
 
-func NewWebSocketHandler(remoteStoreClient store.CustomAvlDataStoreClient, storeType string) WebSocketHandler {
+func NewWebSocketHandler(remoteStoreClient store.CustomAvlDataStoreClient, storeType string, sinks Sinks) WebSocketHandler {
 	return WebSocketHandler{
 		connToProtocolMap: make(map[string]protocols.DeviceProtocol),
 		allowedProtocols:  []types.DeviceProtocolType{types.DeviceProtocolType_HOWENWS},
 		remoteStoreClient: remoteStoreClient,
 		storeType:         storeType,
+		sinks:             sinks,
 		connToStoreMap:    make(map[string]store.Store),
+		positions:         feed.NewHub(),
 	}
 }
+
+func memoryCommandQueue() *commandqueue.Queue {
+	queue, _ := commandqueue.Open("")
+	return queue
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/ratelimit"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// limitReportInterval is how often a limited device is reported to the data store at most
+const limitReportInterval = time.Minute
+
+var recordsLimitedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "avl_records_limited_total",
+	Help: "Device statuses dropped for going over the per imei record rate, by protocol.",
+}, []string{"protocol"})
+
+func init() {
+	prometheus.MustRegister(recordsLimitedTotal)
+}
+
+// UseRecordLimit drops the statuses an imei sends over the limit, across its connections, e.g. of
+// a tracker misconfigured to report every second. The devices still get their acks.
+func (t *TcpHandler) UseRecordLimit(limit ratelimit.Limit) {
+	t.records = ratelimit.New(limit)
+}
+
+// allowRecord takes a token from the imei's bucket, the dropped statuses are reported
+func (t *TcpHandler) allowRecord(protocol types.DeviceProtocolType, imei string, ip string) bool {
+	if t.records == nil || t.records.Allow(imei) {
+		return true
+	}
+	recordsLimitedTotal.WithLabelValues(protocol.String()).Inc()
+	t.limitedDevice(imei, ip, store.DeviceLimitReason_LIMIT_RECORD_RATE)
+	return false
+}
+
+// limitedDevice counts what was dropped of a device and reports it now and then
+func (t *TcpHandler) limitedDevice(imei string, ip string, reason store.DeviceLimitReason) {
+	if event := t.limited.dropped(imei, ip, reason); event != nil {
+		t.reportLimited(event)
+	}
+}
+
+type limitKey struct {
+	imeiOrIP string
+	reason   store.DeviceLimitReason
+}
+
+// limitEpisode is what was dropped of a device since it was last reported
+type limitEpisode struct {
+	imei           string
+	ip             string
+	dropped        uint32
+	firstDroppedAt time.Time
+	reportedAt     time.Time
+}
+
+// limitReporter keeps what was dropped of the limited devices until they're reported, at most
+// once every limitReportInterval per device and reason
+type limitReporter struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	episodes  map[limitKey]*limitEpisode
+	lastSweep time.Time
+}
+
+func newLimitReporter() *limitReporter {
+	return &limitReporter{now: time.Now, episodes: make(map[limitKey]*limitEpisode)}
+}
+
+// dropped counts a record or connection dropped, the event is what's due to be reported if any
+func (r *limitReporter) dropped(imei string, ip string, reason store.DeviceLimitReason) *store.DeviceLimited {
+	key := limitKey{imeiOrIP: imei, reason: reason}
+	if imei == "" {
+		key.imeiOrIP = ip
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := r.now()
+	r.sweep(now)
+	episode, ok := r.episodes[key]
+	if !ok {
+		episode = &limitEpisode{}
+		r.episodes[key] = episode
+	}
+	episode.imei, episode.ip = imei, ip
+	if episode.dropped == 0 {
+		episode.firstDroppedAt = now
+	}
+	episode.dropped++
+	if now.Sub(episode.reportedAt) < limitReportInterval {
+		return nil
+	}
+	event := &store.DeviceLimited{
+		Imei:           episode.imei,
+		RemoteIp:       episode.ip,
+		Reason:         reason,
+		Dropped:        episode.dropped,
+		FirstDroppedAt: timestamppb.New(episode.firstDroppedAt),
+		ReportedAt:     timestamppb.New(now),
+	}
+	episode.dropped = 0
+	episode.reportedAt = now
+	return event
+}
+
+// sweep forgets the devices reported a while ago and not dropped since
+func (r *limitReporter) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < limitReportInterval {
+		return
+	}
+	r.lastSweep = now
+	for key, episode := range r.episodes {
+		if episode.dropped == 0 && now.Sub(episode.reportedAt) >= limitReportInterval {
+			delete(r.episodes, key)
+		}
+	}
+}
+
+// reportLimited tells the data store about a limited device so its configuration can be fixed
+func (t *TcpHandler) reportLimited(event *store.DeviceLimited) {
+	logger.Warn("device limited", zap.String("imei", event.Imei), zap.String("ip", event.RemoteIp),
+		zap.String("reason", event.Reason.String()), zap.Uint32("dropped", event.Dropped))
+	if !t.remoteStoreClient.IsConfigured() {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, err := t.remoteStoreClient.SaveDeviceLimited(ctx, event); err != nil {
+			logger.Error("failed to report limited device", zap.String("imei", event.Imei), zap.String("ip", event.RemoteIp), zap.Error(err))
+		}
+	}()
+}
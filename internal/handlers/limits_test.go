@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/ratelimit"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimitReporterAggregates(t *testing.T) {
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	reporter := newLimitReporter()
+	reporter.now = func() time.Time { return now }
+	reason := store.DeviceLimitReason_LIMIT_RECORD_RATE
+
+	first := reporter.dropped("356307043721579", "10.0.0.1", reason)
+	require.NotNil(t, first, "the first drop is reported right away")
+	assert.Equal(t, uint32(1), first.Dropped)
+
+	for i := 0; i < 5; i++ {
+		now = now.Add(time.Second)
+		assert.Nil(t, reporter.dropped("356307043721579", "10.0.0.1", reason))
+	}
+	assert.NotNil(t, reporter.dropped("10.0.0.2", "10.0.0.2", store.DeviceLimitReason_LIMIT_CONNECTION_RATE),
+		"other devices are reported on their own")
+
+	now = now.Add(limitReportInterval)
+	event := reporter.dropped("356307043721579", "10.0.0.1", reason)
+	require.NotNil(t, event)
+	assert.Equal(t, uint32(6), event.Dropped)
+	assert.Equal(t, first.ReportedAt.AsTime().Add(time.Second), event.FirstDroppedAt.AsTime())
+}
+
+func TestAllowRecord(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	assert.True(t, handler.allowRecord(types.DeviceProtocolType_FM1200, "356307043721579", "10.0.0.1"), "unlimited by default")
+
+	handler.UseRecordLimit(ratelimit.Limit{Rate: 0.001, Burst: 2})
+	before := testutil.ToFloat64(recordsLimitedTotal.WithLabelValues(types.DeviceProtocolType_FM1200.String()))
+	for i := 0; i < 2; i++ {
+		assert.True(t, handler.allowRecord(types.DeviceProtocolType_FM1200, "356307043721579", "10.0.0.1"))
+	}
+	assert.False(t, handler.allowRecord(types.DeviceProtocolType_FM1200, "356307043721579", "10.0.0.1"))
+	assert.True(t, handler.allowRecord(types.DeviceProtocolType_FM1200, "356307043721580", "10.0.0.1"), "limited per imei")
+	assert.Equal(t, before+1, testutil.ToFloat64(recordsLimitedTotal.WithLabelValues(types.DeviceProtocolType_FM1200.String())))
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"errors"
+	"os"
+
+	errs "github.com/404minds/avl-receiver/internal/errors"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	loginsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "avl_logins_total",
+		Help: "Device logins over tcp, by result and the reason failed ones were rejected for.",
+	}, []string{"result", "reason"})
+	recordsDecodedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "avl_records_decoded_total",
+		Help: "Device statuses decoded, by protocol and message type.",
+	}, []string{"protocol", "message_type"})
+	responsesDecodedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "avl_responses_decoded_total",
+		Help: "Device replies to commands decoded, by protocol.",
+	}, []string{"protocol"})
+	crcFailuresTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "avl_crc_failures_total",
+		Help: "Packets dropped for a bad crc, by protocol, unknown during login.",
+	}, []string{"protocol"})
+	framesTooLargeTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "avl_frames_too_large_total",
+		Help: "Connections dropped for a frame over the protocol's size limit, by protocol, unknown during login.",
+	}, []string{"protocol"})
+	commandsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "avl_commands_total",
+		Help: "Commands sent to devices, by outcome.",
+	}, []string{"status"})
+	commandReplySeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "avl_command_reply_seconds",
+		Help:    "Time devices took to reply to a command.",
+		Buckets: []float64{.1, .25, .5, 1, 2.5, 5, 10, 20, 30},
+	})
+)
+
+func init() {
+	prometheus.MustRegister(loginsTotal, recordsDecodedTotal, responsesDecodedTotal, crcFailuresTotal, framesTooLargeTotal, commandsTotal, commandReplySeconds)
+}
+
+// loginFailureReason is the reason label of a failed login
+func loginFailureReason(err error) string {
+	switch {
+	case errors.Is(err, errs.ErrUnknownDeviceType):
+		return "unknown_device_type"
+	case errors.Is(err, errs.ErrUnauthorizedDevice):
+		return "unauthorized_device"
+	case errors.Is(err, errs.ErrStoreUnavailable):
+		return "store_unavailable"
+	case errors.Is(err, errs.ErrBadCrc):
+		return "bad_crc"
+	case errors.Is(err, errs.ErrDeviceBanned):
+		return "banned"
+	case errors.Is(err, errs.ErrFrameTooLarge):
+		return "frame_too_large"
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return "timeout"
+	default:
+		return "error"
+	}
+}
+
+func recordLogin(err error) {
+	if err != nil {
+		loginsTotal.WithLabelValues("failed", loginFailureReason(err)).Inc()
+		if errors.Is(err, errs.ErrBadCrc) {
+			crcFailuresTotal.WithLabelValues("unknown").Inc()
+		}
+		if errors.Is(err, errs.ErrFrameTooLarge) {
+			framesTooLargeTotal.WithLabelValues("unknown").Inc()
+		}
+		return
+	}
+	loginsTotal.WithLabelValues("ok", "").Inc()
+}
+
+func recordDecodedStatus(protocol types.DeviceProtocolType, status *types.DeviceStatus) {
+	messageType := status.GetMessageType()
+	if messageType == "" {
+		messageType = "unknown"
+	}
+	recordsDecodedTotal.WithLabelValues(protocol.String(), messageType).Inc()
+}
+
+func recordCommandResult(result CommandResult) {
+	commandsTotal.WithLabelValues(result.Status.String()).Inc()
+	if result.Latency > 0 {
+		commandReplySeconds.Observe(result.Latency.Seconds())
+	}
+}
+
+var (
+	connectionsActiveDesc = prometheus.NewDesc("avl_tcp_connections_active",
+		"Devices logged in over tcp, by protocol.", []string{"protocol"}, nil)
+	storeQueueDepthDesc = prometheus.NewDesc("avl_store_queue_depth",
+		"Device statuses and responses decoded but not taken by the stores yet, summed over the connections.", []string{"queue"}, nil)
+)
+
+// tcpCollector reads the gauges off the handler's connections when scraped
+type tcpCollector struct {
+	t *TcpHandler
+}
+
+// Collector exposes the handler's connections and store queues, register it with the metrics registry
+func (t *TcpHandler) Collector() prometheus.Collector {
+	return tcpCollector{t: t}
+}
+
+func (c tcpCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- connectionsActiveDesc
+	ch <- storeQueueDepthDesc
+}
+
+func (c tcpCollector) Collect(ch chan<- prometheus.Metric) {
+	c.t.mu.RLock()
+	perProtocol := make(map[types.DeviceProtocolType]int)
+	// zeros too, so alerts on a protocol going quiet have a series to look at
+	for _, protocol := range c.t.allowedProtocols {
+		perProtocol[protocol] = 0
+	}
+	for _, protocol := range c.t.connToProtocolMap {
+		perProtocol[protocol.GetProtocolType()]++
+	}
+	statusDepth, responseDepth := 0, 0
+	for _, s := range c.t.connToStoreMap {
+		statusDepth += queueDepth(s, true)
+		responseDepth += queueDepth(s, false)
+	}
+	c.t.mu.RUnlock()
+
+	for protocol, n := range perProtocol {
+		ch <- prometheus.MustNewConstMetric(connectionsActiveDesc, prometheus.GaugeValue, float64(n), protocol.String())
+	}
+	ch <- prometheus.MustNewConstMetric(storeQueueDepthDesc, prometheus.GaugeValue, float64(statusDepth), "status")
+	ch <- prometheus.MustNewConstMetric(storeQueueDepthDesc, prometheus.GaugeValue, float64(responseDepth), "response")
+}
+
+// queueDepth also counts what's waiting in the stores behind taps and fan outs
+func queueDepth(s store.Store, statuses bool) int {
+	switch s := s.(type) {
+	case *store.TapStore:
+		depth := queueDepth(s.Store, statuses)
+		if !statuses {
+			depth += len(s.ResponseChan)
+		} else if s.ProcessChan != nil {
+			// without its own channel the tap hands out the wrapped store's
+			depth += len(s.ProcessChan)
+		}
+		return depth
+	case *store.MultiStore:
+		depth := ownQueueDepth(s, statuses)
+		for _, child := range s.Stores {
+			depth += queueDepth(child, statuses)
+		}
+		return depth
+	}
+	return ownQueueDepth(s, statuses)
+}
+
+func ownQueueDepth(s store.Store, statuses bool) int {
+	if statuses {
+		return len(s.GetProcessChan())
+	}
+	return len(s.GetResponseChan())
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	errs "github.com/404minds/avl-receiver/internal/errors"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginFailureReasons(t *testing.T) {
+	assert.Equal(t, "unknown_device_type", loginFailureReason(errs.ErrUnknownDeviceType))
+	assert.Equal(t, "unauthorized_device", loginFailureReason(fmt.Errorf("verify: %w", errs.ErrUnauthorizedDevice)))
+	assert.Equal(t, "store_unavailable", loginFailureReason(fmt.Errorf("%w: connection refused", errs.ErrStoreUnavailable)))
+	assert.Equal(t, "frame_too_large", loginFailureReason(fmt.Errorf("%w: fm1200 data length 4294967295", errs.ErrFrameTooLarge)))
+	assert.Equal(t, "timeout", loginFailureReason(fmt.Errorf("header peek failed: %w", os.ErrDeadlineExceeded)))
+	assert.Equal(t, "error", loginFailureReason(errors.New("read timeout")))
+}
+
+// gaugeValue is the value of the collector's metric with the given name and label
+func gaugeValue(t *testing.T, c prometheus.Collector, name string, label string, value string) float64 {
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(c))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, l := range metric.GetLabel() {
+				if l.GetName() == label && l.GetValue() == value {
+					return metric.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	require.Failf(t, "metric not found", "%s{%s=%q}", name, label, value)
+	return 0
+}
+
+func TestMetricsTrackConnectionsAndLogins(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{Local: store.RotationConfig{Dir: t.TempDir()}})
+	teltonika := types.DeviceProtocolType_FM1200.String()
+	loginsBefore := testutil.ToFloat64(loginsTotal.WithLabelValues("ok", ""))
+	assert.Equal(t, float64(0), gaugeValue(t, handler.Collector(), "avl_tcp_connections_active", "protocol", teltonika))
+
+	server, device := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		handler.HandleConnection(server)
+		close(done)
+	}()
+	login, _ := hex.DecodeString("000F333536333037303433373231353739")
+	_, err := device.Write(login)
+	require.NoError(t, err)
+	_, err = io.ReadFull(device, make([]byte, 1))
+	require.NoError(t, err)
+
+	assert.Equal(t, loginsBefore+1, testutil.ToFloat64(loginsTotal.WithLabelValues("ok", "")))
+	assert.Equal(t, float64(1), gaugeValue(t, handler.Collector(), "avl_tcp_connections_active", "protocol", teltonika))
+	assert.Equal(t, float64(0), gaugeValue(t, handler.Collector(), "avl_store_queue_depth", "queue", "status"))
+
+	device.Close()
+	<-done
+	assert.Equal(t, float64(0), gaugeValue(t, handler.Collector(), "avl_tcp_connections_active", "protocol", teltonika))
+}
+
+func TestQueueDepthLooksThroughTapsAndFanOuts(t *testing.T) {
+	child := &store.JsonLinesStore{ProcessChan: make(chan *types.DeviceStatus, 10), ResponseChan: make(chan *types.DeviceResponse, 10)}
+	multi := &store.MultiStore{
+		ProcessChan:  make(chan *types.DeviceStatus, 10),
+		ResponseChan: make(chan *types.DeviceResponse, 10),
+		Stores:       []store.Store{child},
+	}
+	tap := &store.TapStore{Store: multi, ResponseChan: make(chan *types.DeviceResponse, 10)}
+
+	child.ProcessChan <- &types.DeviceStatus{}
+	multi.ProcessChan <- &types.DeviceStatus{}
+	tap.ResponseChan <- &types.DeviceResponse{}
+
+	assert.Equal(t, 2, queueDepth(tap, true), "the tap without its own status channel isn't counted twice")
+	assert.Equal(t, 1, queueDepth(tap, false))
+}
+
+func TestCommandMetrics(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	before := testutil.ToFloat64(commandsTotal.WithLabelValues(store.CommandStatus_COMMAND_NOT_CONNECTED.String()))
+
+	handler.SendCommand(context.Background(), "861234567890123", "", "getinfo", time.Second)
+	assert.Equal(t, before+1, testutil.ToFloat64(commandsTotal.WithLabelValues(store.CommandStatus_COMMAND_NOT_CONNECTED.String())))
+}
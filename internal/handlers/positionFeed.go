@@ -0,0 +1,12 @@
+package handlers
+
+import "github.com/404minds/avl-receiver/internal/feed"
+
+// UsePositionFeed publishes the decoded statuses to a hub shared with the websocket handler
+func (t *TcpHandler) UsePositionFeed(hub *feed.Hub) {
+	t.positions = hub
+}
+
+func (t *TcpHandler) PositionFeed() *feed.Hub {
+	return t.positions
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"time"
+
+	errs "github.com/404minds/avl-receiver/internal/errors"
+	devices "github.com/404minds/avl-receiver/internal/protocols"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// sessionEndReason is why a connection ended, closed is for the connections the receiver closed
+// itself, their reader only sees the closed socket
+func sessionEndReason(err error, closed bool) store.SessionEndReason {
+	var closeErr *websocket.CloseError
+	switch {
+	case closed:
+		return store.SessionEndReason_END_CLOSED
+	case err == nil, errors.Is(err, io.EOF):
+		return store.SessionEndReason_END_EOF
+	case errors.As(err, &closeErr) && (closeErr.Code == websocket.CloseNormalClosure || closeErr.Code == websocket.CloseGoingAway):
+		return store.SessionEndReason_END_EOF
+	case errors.Is(err, errs.ErrUnauthorizedDevice), errors.Is(err, errs.ErrDeviceBanned):
+		return store.SessionEndReason_END_AUTH_FAILED
+	case errors.Is(err, os.ErrDeadlineExceeded):
+		return store.SessionEndReason_END_TIMEOUT
+	case errors.Is(err, errs.ErrBadPacket), errors.Is(err, errs.ErrBadCrc), errors.Is(err, errs.ErrParserPanic):
+		return store.SessionEndReason_END_PARSE_ERROR
+	default:
+		return store.SessionEndReason_END_ERROR
+	}
+}
+
+func connectedEvent(imei string, remoteAddr string, protocol devices.DeviceProtocol, stats *connectionStats) *store.DeviceSessionEvent {
+	return &store.DeviceSessionEvent{
+		Event:       store.SessionEventType_SESSION_CONNECTED,
+		Imei:        imei,
+		RemoteAddr:  remoteAddr,
+		Protocol:    protocol.GetProtocolType(),
+		DeviceType:  protocol.GetDeviceType(),
+		ConnectedAt: timestamppb.New(stats.connectedAt),
+	}
+}
+
+// disconnectedEvent is the whole session, with what the device sent and why it ended
+func disconnectedEvent(imei string, remoteAddr string, protocol devices.DeviceProtocol, stats *connectionStats, err error, closed bool) *store.DeviceSessionEvent {
+	now := time.Now()
+	event := connectedEvent(imei, remoteAddr, protocol, stats)
+	event.Event = store.SessionEventType_SESSION_DISCONNECTED
+	event.DisconnectedAt = timestamppb.New(now)
+	event.DurationMs = uint64(now.Sub(stats.connectedAt).Milliseconds())
+	event.BytesReceived = stats.bytesReceived.Load()
+	event.PacketsReceived = stats.packetsReceived.Load()
+	event.Reason = sessionEndReason(err, closed)
+	if err != nil && !errors.Is(err, io.EOF) {
+		event.Error = err.Error()
+	}
+	return event
+}
+
+// reportSession sends the event to the data store in the background, connections don't wait on it
+func reportSession(client store.CustomAvlDataStoreClient, event *store.DeviceSessionEvent) {
+	logger.Debug("device session", zap.String("imei", event.Imei), zap.String("remoteAddr", event.RemoteAddr),
+		zap.String("event", event.Event.String()), zap.String("reason", event.Reason.String()))
+	if !client.IsConfigured() {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, err := client.SaveDeviceSession(ctx, event); err != nil {
+			logger.Error("failed to report device session", zap.String("imei", event.Imei), zap.String("remoteAddr", event.RemoteAddr), zap.Error(err))
+		}
+	}()
+}
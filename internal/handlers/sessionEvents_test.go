@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	errs "github.com/404minds/avl-receiver/internal/errors"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// sessionEventStore is a remote data store knowing one imei, it keeps the session events it gets
+type sessionEventStore struct {
+	imei string
+
+	mu     sync.Mutex
+	events []*store.DeviceSessionEvent
+}
+
+func (s *sessionEventStore) Invoke(ctx context.Context, method string, args any, reply any, opts ...grpc.CallOption) error {
+	switch reply := reply.(type) {
+	case *store.VerifyDeviceReply:
+		if args.(*store.VerifyDeviceRequest).Imei == s.imei {
+			reply.Imei = s.imei
+			reply.DeviceType = types.DeviceType_TELTONIKA
+		}
+	}
+	if event, ok := args.(*store.DeviceSessionEvent); ok {
+		s.mu.Lock()
+		s.events = append(s.events, event)
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+func (s *sessionEventStore) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, nil
+}
+
+// event waits for the session event of the type
+func (s *sessionEventStore) event(t *testing.T, eventType store.SessionEventType) *store.DeviceSessionEvent {
+	var event *store.DeviceSessionEvent
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for _, e := range s.events {
+			if e.Event == eventType {
+				event = e
+				return true
+			}
+		}
+		return false
+	}, time.Second, 5*time.Millisecond, "no %s event", eventType)
+	return event
+}
+
+func TestSessionEndReason(t *testing.T) {
+	cases := []struct {
+		err    error
+		closed bool
+		reason store.SessionEndReason
+	}{
+		{nil, false, store.SessionEndReason_END_EOF},
+		{io.EOF, false, store.SessionEndReason_END_EOF},
+		{&websocket.CloseError{Code: websocket.CloseNormalClosure}, false, store.SessionEndReason_END_EOF},
+		{&websocket.CloseError{Code: websocket.CloseAbnormalClosure}, false, store.SessionEndReason_END_ERROR},
+		{fmt.Errorf("reading: %w", os.ErrDeadlineExceeded), false, store.SessionEndReason_END_TIMEOUT},
+		{errs.ErrFM1200BadDataPacket, false, store.SessionEndReason_END_PARSE_ERROR},
+		{errs.ErrFrameTooLarge, false, store.SessionEndReason_END_PARSE_ERROR},
+		{errs.ErrBadCrc, false, store.SessionEndReason_END_PARSE_ERROR},
+		{errs.ErrUnauthorizedDevice, false, store.SessionEndReason_END_AUTH_FAILED},
+		{fmt.Errorf("%w: 356307043721579", errs.ErrDeviceBanned), false, store.SessionEndReason_END_AUTH_FAILED},
+		{errors.New("use of closed network connection"), true, store.SessionEndReason_END_CLOSED},
+		{errors.New("connection reset by peer"), false, store.SessionEndReason_END_ERROR},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.reason, sessionEndReason(c.err, c.closed), "%v", c.err)
+	}
+}
+
+func TestSessionEventsReported(t *testing.T) {
+	events := &sessionEventStore{imei: "356307043721579"}
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "remote", Sinks{})
+	handler.remoteStoreClient = *store.NewCustomAvlDataStoreClient(events, "store.AvlDataStore")
+
+	login, _ := hex.DecodeString("000F333536333037303433373231353739")
+	device, done := serveGuarded(&handler)
+	_, err := device.Write(login)
+	require.NoError(t, err)
+	_, err = io.ReadFull(device, make([]byte, 1))
+	require.NoError(t, err)
+
+	connected := events.event(t, store.SessionEventType_SESSION_CONNECTED)
+	assert.Equal(t, "356307043721579", connected.Imei)
+	assert.Equal(t, types.DeviceProtocolType_FM1200, connected.Protocol)
+	assert.Equal(t, types.DeviceType_TELTONIKA, connected.DeviceType)
+	assert.NotEmpty(t, connected.RemoteAddr)
+
+	device.Close()
+	closedWithin(t, done, time.Second)
+	disconnected := events.event(t, store.SessionEventType_SESSION_DISCONNECTED)
+	assert.Equal(t, store.SessionEndReason_END_EOF, disconnected.Reason)
+	assert.Equal(t, uint64(len(login)), disconnected.BytesReceived)
+	assert.Equal(t, connected.ConnectedAt.AsTime(), disconnected.ConnectedAt.AsTime())
+	assert.False(t, disconnected.DisconnectedAt.AsTime().Before(disconnected.ConnectedAt.AsTime()))
+}
+
+func TestSessionEventsClosedByReceiver(t *testing.T) {
+	events := &sessionEventStore{imei: "356307043721579"}
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "remote", Sinks{})
+	handler.remoteStoreClient = *store.NewCustomAvlDataStoreClient(events, "store.AvlDataStore")
+
+	login, _ := hex.DecodeString("000F333536333037303433373231353739")
+	device, done := serveGuarded(&handler)
+	defer device.Close()
+	_, err := device.Write(login)
+	require.NoError(t, err)
+	_, err = io.ReadFull(device, make([]byte, 1))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return handler.IsConnected("356307043721579") }, time.Second, 5*time.Millisecond)
+
+	_, ok := handler.DisconnectDevice("356307043721579")
+	require.True(t, ok)
+	closedWithin(t, done, time.Second)
+	assert.Equal(t, store.SessionEndReason_END_CLOSED, events.event(t, store.SessionEventType_SESSION_DISCONNECTED).Reason)
+}
+
+func TestSessionEventsUnauthorizedDevice(t *testing.T) {
+	events := &sessionEventStore{imei: "000000000000000"}
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "remote", Sinks{})
+	handler.remoteStoreClient = *store.NewCustomAvlDataStoreClient(events, "store.AvlDataStore")
+
+	login, _ := hex.DecodeString("000F333536333037303433373231353739")
+	device, done := serveGuarded(&handler)
+	defer device.Close()
+	_, err := device.Write(login)
+	require.NoError(t, err)
+	closedWithin(t, done, time.Second)
+
+	disconnected := events.event(t, store.SessionEventType_SESSION_DISCONNECTED)
+	assert.Equal(t, "356307043721579", disconnected.Imei)
+	assert.Equal(t, store.SessionEndReason_END_AUTH_FAILED, disconnected.Reason)
+	assert.Contains(t, disconnected.Error, "unauthorized")
+
+	events.mu.Lock()
+	defer events.mu.Unlock()
+	assert.Len(t, events.events, 1, "a rejected device never connected")
+}
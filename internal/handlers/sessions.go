@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/capture"
+	devices "github.com/404minds/avl-receiver/internal/protocols"
+	"go.uber.org/zap"
+)
+
+const sessionWriteTimeout = 20 * time.Second
+
+var errSessionClosed = errors.New("device session closed")
+
+type writeRequest struct {
+	data   []byte
+	result chan error
+}
+
+// deviceSession is a logged in device connection. Everything written to the device, acks from
+// the protocol as well as commands from the api, goes through the session's writer goroutine so
+// writes from different goroutines never interleave on the socket.
+type deviceSession struct {
+	imei     string
+	conn     net.Conn
+	protocol devices.DeviceProtocol
+	stats    *connectionStats
+	recorder *capture.Recorder // set before the first write, nil if the connection isn't captured
+
+	writes    chan writeRequest
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func newDeviceSession(imei string, conn net.Conn, protocol devices.DeviceProtocol, stats *connectionStats) *deviceSession {
+	s := &deviceSession{
+		imei:     imei,
+		conn:     conn,
+		protocol: protocol,
+		stats:    stats,
+		writes:   make(chan writeRequest),
+		done:     make(chan struct{}),
+	}
+	go s.writeLoop()
+	return s
+}
+
+func (s *deviceSession) writeLoop() {
+	for {
+		select {
+		case req := <-s.writes:
+			if err := s.conn.SetWriteDeadline(time.Now().Add(sessionWriteTimeout)); err != nil {
+				req.result <- err
+				continue
+			}
+			_, err := s.conn.Write(req.data)
+			if err == nil {
+				logTraffic("wrote to device", s.imei, req.data)
+				s.recorder.Outbound(req.data)
+			}
+			req.result <- err
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// write hands data to the writer goroutine and waits until it is on the socket
+func (s *deviceSession) write(data []byte) error {
+	req := writeRequest{data: data, result: make(chan error, 1)}
+	select {
+	case s.writes <- req:
+	case <-s.done:
+		return errSessionClosed
+	}
+
+	select {
+	case err := <-req.result:
+		return err
+	case <-s.done:
+		return errSessionClosed
+	}
+}
+
+// close ends the session, the connection's reader sees the closed socket and cleans up
+func (s *deviceSession) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		if err := s.conn.Close(); err != nil {
+			logger.Debug("closing device connection", zap.String("imei", s.imei), zap.Error(err))
+		}
+	})
+}
+
+// closed tells if close was called, e.g. for a takeover, rather than the device going away
+func (s *deviceSession) closed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// sessionConn is the connection as the protocol sees it, reads and deadlines go to the socket
+// but writes are serialized with the commands through the session
+type sessionConn struct {
+	net.Conn
+	session *deviceSession
+}
+
+func (c sessionConn) Write(p []byte) (int, error) {
+	if err := c.session.write(p); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// registerSession makes the session the one for its imei. A device logging in again while
+// its old connection is still open takes over, the old session is closed.
+func (t *TcpHandler) registerSession(session *deviceSession) {
+	t.mu.Lock()
+	previous := t.sessions[session.imei]
+	t.sessions[session.imei] = session
+	t.mu.Unlock()
+
+	if previous != nil && previous != session {
+		logger.Info("device logged in again, closing its previous session", zap.String("imei", session.imei),
+			zap.String("previousAddr", previous.conn.RemoteAddr().String()), zap.String("remoteAddr", session.conn.RemoteAddr().String()))
+		previous.close()
+	}
+}
+
+// unregisterSession removes the session unless a newer session for the imei took its place
+func (t *TcpHandler) unregisterSession(session *deviceSession) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.sessions[session.imei] == session {
+		delete(t.sessions, session.imei)
+	}
+}
+
+func (t *TcpHandler) session(imei string) (*deviceSession, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	session, exists := t.sessions[imei]
+	return session, exists
+}
+
+func (t *TcpHandler) IsConnected(imei string) bool {
+	_, connected := t.session(imei)
+	return connected
+}
@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/protocols/obdii2g"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// overlapConn records writes that run at the same time as another write
+type overlapConn struct {
+	net.Conn
+	active   atomic.Int32
+	overlaps atomic.Int32
+}
+
+func (c *overlapConn) Write(p []byte) (int, error) {
+	if c.active.Add(1) > 1 {
+		c.overlaps.Add(1)
+	}
+	defer c.active.Add(-1)
+	time.Sleep(100 * time.Microsecond)
+	return c.Conn.Write(p)
+}
+
+func TestSessionSerializesAcksAndCommands(t *testing.T) {
+	server, device := net.Pipe()
+	defer device.Close()
+	conn := &overlapConn{Conn: server}
+
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	session := newDeviceSession("861234567890123", conn, &obdii2g.AquilaOBDII2GProtocol{}, newConnectionStats())
+	handler.registerSession(session)
+	defer session.close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		var all []byte
+		buf := make([]byte, 1024)
+		for {
+			n, err := device.Read(buf)
+			if err != nil {
+				received <- all
+				return
+			}
+			all = append(all, buf[:n]...)
+			// net.Pipe hands over one write per read, answer commands so the next one can go out
+			if bytes.HasPrefix(buf[:n], []byte("#CMD")) {
+				go replyToCommand(t, &handler, &types.DeviceResponse{Imei: "861234567890123", Response: "OK"})
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			// what ConsumeStream does when acking a packet
+			defer wg.Done()
+			_, err := sessionConn{Conn: conn, session: session}.Write([]byte(fmt.Sprintf("ACK%02d;", i)))
+			assert.NoError(t, err)
+		}(i)
+		go func(i int) {
+			defer wg.Done()
+			result := handler.SendCommand(context.Background(), "861234567890123", "", fmt.Sprintf("CMD%02d", i), 5*time.Second)
+			assert.Equal(t, store.CommandStatus_COMMAND_ACK, result.Status)
+		}(i)
+	}
+	wg.Wait()
+	session.close()
+
+	b := <-received
+	assert.Zero(t, conn.overlaps.Load(), "writes to the socket overlapped")
+	assert.Equal(t, 20, bytes.Count(b, []byte("ACK")))
+	assert.Equal(t, 20, bytes.Count(b, []byte("#CMD")))
+}
+
+func TestSessionTakeover(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	oldServer, oldDevice := net.Pipe()
+	newServer, newDevice := net.Pipe()
+	defer oldDevice.Close()
+	defer newDevice.Close()
+
+	old := newDeviceSession("861234567890123", oldServer, &obdii2g.AquilaOBDII2GProtocol{}, newConnectionStats())
+	handler.registerSession(old)
+	current := newDeviceSession("861234567890123", newServer, &obdii2g.AquilaOBDII2GProtocol{}, newConnectionStats())
+	handler.registerSession(current)
+	defer current.close()
+
+	_, err := oldDevice.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, io.EOF, "the old connection is closed on takeover")
+	assert.ErrorIs(t, old.write([]byte("x")), errSessionClosed)
+
+	// the old connection's cleanup runs after the takeover
+	handler.unregisterSession(old)
+	session, connected := handler.session("861234567890123")
+	require.True(t, connected)
+	assert.Same(t, current, session)
+
+	handler.unregisterSession(current)
+	assert.False(t, handler.IsConnected("861234567890123"))
+}
+
+func TestHandleConnectionTakeover(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{Local: store.RotationConfig{Dir: t.TempDir()}})
+	login, _ := hex.DecodeString("000F333536333037303433373231353739")
+
+	connect := func() (net.Conn, chan struct{}) {
+		server, device := net.Pipe()
+		done := make(chan struct{})
+		go func() {
+			handler.HandleConnection(server)
+			close(done)
+		}()
+		_, err := device.Write(login)
+		require.NoError(t, err)
+		ack := make([]byte, 1)
+		_, err = io.ReadFull(device, ack)
+		require.NoError(t, err)
+		assert.Equal(t, []byte{0x01}, ack)
+		return device, done
+	}
+
+	first, firstDone := connect()
+	defer first.Close()
+	require.Eventually(t, func() bool { return handler.IsConnected("356307043721579") }, time.Second, 5*time.Millisecond)
+	firstSession, _ := handler.session("356307043721579")
+
+	second, secondDone := connect()
+	select {
+	case <-firstDone:
+	case <-time.After(2 * time.Second):
+		require.Fail(t, "first connection not closed on takeover")
+	}
+	require.Eventually(t, func() bool {
+		session, connected := handler.session("356307043721579")
+		return connected && session != firstSession
+	}, time.Second, 5*time.Millisecond, "the new session survives the old connection's cleanup")
+
+	second.Close()
+	<-secondDone
+	assert.False(t, handler.IsConnected("356307043721579"))
+}
+
+func TestSessionRegistryConcurrentAccess(t *testing.T) {
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				server, device := net.Pipe()
+				session := newDeviceSession(fmt.Sprintf("86123456789012%d", j%3), server, &obdii2g.AquilaOBDII2GProtocol{}, newConnectionStats())
+				handler.registerSession(session)
+				handler.unregisterSession(session)
+				session.close()
+				device.Close()
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				handler.IsConnected("861234567890120")
+				handler.GetConnection("861234567890121")
+				handler.ListConnections()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Empty(t, handler.ListConnections())
+}
@@ -8,15 +8,19 @@ import (
 	"io"
 	"net"
 	"os"
-	"path"
 	"runtime/debug"
 	"slices"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/404minds/avl-receiver/internal/capture"
+	"github.com/404minds/avl-receiver/internal/commandqueue"
 	errs "github.com/404minds/avl-receiver/internal/errors"
+	"github.com/404minds/avl-receiver/internal/feed"
 	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
 	devices "github.com/404minds/avl-receiver/internal/protocols"
+	"github.com/404minds/avl-receiver/internal/ratelimit"
 	"github.com/404minds/avl-receiver/internal/store"
 	"github.com/404minds/avl-receiver/internal/types"
 	"go.uber.org/zap"
@@ -24,11 +28,6 @@ import (
 
 var logger = configuredLogger.Logger
 
-type DeviceConnectionInfo struct {
-	Conn     net.Conn
-	Protocol devices.DeviceProtocol
-}
-
 type TcpHandler struct {
 	mu                sync.RWMutex
 	connToProtocolMap map[string]devices.DeviceProtocol // make this an LRU cache to evict stale connections
@@ -36,7 +35,18 @@ type TcpHandler struct {
 	connToStoreMap    map[string]store.Store
 	remoteStoreClient store.CustomAvlDataStoreClient
 	storeType         string
-	imeiToConnMap     map[string]DeviceConnectionInfo
+	sinks             Sinks
+	sessions          map[string]*deviceSession // logged in devices by imei
+	commands          *commandTracker
+	commandQueue      *commandqueue.Queue
+	positions         *feed.Hub
+	verifyCache       *verifyCache
+	capture           *capture.Config
+	guard             *connectionGuard
+	bans              *banList
+	records           *ratelimit.Buckets // records per imei, unlimited if nil
+	limited           *limitReporter
+	makeStore         func(devices.DeviceProtocol) store.Store // replaces the store type's stores, for tests
 }
 
 func (t *TcpHandler) HandleConnection(conn net.Conn) {
@@ -49,36 +59,77 @@ func (t *TcpHandler) HandleConnection(conn net.Conn) {
 		}
 	}(conn)
 
-	err := conn.SetReadDeadline(time.Now().Add(20 * time.Second))
+	// scanners are turned away before anything is read or allocated for them
+	if ok, reason := t.admit(conn); !ok {
+		connectionsRejectedTotal.WithLabelValues(reason).Inc()
+		logger.Debug("rejected connection", zap.String("remoteAddr", remoteAddr), zap.String("reason", reason))
+		return
+	}
+	defer t.guard.release()
+
+	err := conn.SetReadDeadline(time.Now().Add(t.guard.loginTimeout()))
 	if err != nil {
 		return
 	}
-	err = conn.SetWriteDeadline(time.Now().Add(20 * time.Second))
+	err = conn.SetWriteDeadline(time.Now().Add(t.guard.loginTimeout()))
 	if err != nil {
 		return
 	}
-	reader := bufio.NewReader(conn)
+	stats := newConnectionStats()
+	recorder := capture.Start(t.capture, remoteAddr)
+	defer recorder.Close()
+	reader := bufio.NewReader(countingReader{reader: conn, stats: stats, recorder: recorder})
 	deviceProtocol, ack, err := t.attemptDeviceLogin(reader)
+	recordLogin(err)
 	if err != nil {
 		logger.Error("failed to identify device", zap.String("remoteAddr", remoteAddr), zap.Error(err))
+		if deviceProtocol != nil {
+			// the device was turned away, scanners and unknown devices aren't reported
+			reportSession(t.remoteStoreClient, disconnectedEvent(deviceProtocol.GetDeviceID(), remoteAddr, deviceProtocol, stats, err, false))
+		}
+		return
+	}
+	asyncStore, err := t.makeAsyncStore(deviceProtocol)
+	if err != nil {
+		logger.Error("failed to create the device's store", zap.String("remoteAddr", remoteAddr), zap.Error(err))
 		return
 	}
 
 	// Lock for map writes
 	t.mu.Lock()
 	t.connToProtocolMap[remoteAddr] = deviceProtocol
-	deviceID := deviceProtocol.GetDeviceID()
+	t.mu.Unlock()
 
+	deviceID := deviceProtocol.GetDeviceID()
+	stats.imei.Store(&deviceID)
 	if deviceID != "" {
-		t.imeiToConnMap[deviceID] = DeviceConnectionInfo{
-			Conn:     conn,
-			Protocol: deviceProtocol,
-		}
-		logger.Sugar().Infof("Mapped deviceID %s to connection %v", deviceID, remoteAddr)
+		// the parser's logs carry the imei from here on, for tracing the device
+		deviceProtocol.SetLogger(logger.With(zap.String("imei", deviceID)))
 	}
-	t.mu.Unlock()
+	recorder.SetDevice(deviceID, deviceProtocol.GetProtocolType())
+	session := newDeviceSession(deviceID, conn, deviceProtocol, stats)
+	session.recorder = recorder
+	defer session.close()
 
-	dataStore := t.makeAsyncStore(deviceProtocol)
+	dataStore := store.Store(&store.TapStore{
+		Store:             asyncStore,
+		ProcessChan:       make(chan *types.DeviceStatus, 200),
+		CloseChan:         make(chan bool, 1),
+		ResponseChan:      make(chan *types.DeviceResponse, 200),
+		CloseResponseChan: make(chan bool, 1),
+		Filter: func(deviceStatus *types.DeviceStatus) bool {
+			return t.allowRecord(deviceProtocol.GetProtocolType(), deviceID, remoteIP(conn))
+		},
+		OnStatus: func(deviceStatus *types.DeviceStatus) {
+			stats.recordStatus(deviceStatus)
+			recordDecodedStatus(deviceProtocol.GetProtocolType(), deviceStatus)
+			t.positions.Publish(deviceStatus)
+		},
+		OnResponse: func(deviceResponse *types.DeviceResponse) {
+			responsesDecodedTotal.WithLabelValues(deviceProtocol.GetProtocolType().String()).Inc()
+			t.commands.deliver(deviceResponse)
+		},
+	})
 
 	// Start processing goroutines
 	// Start processing goroutines
@@ -115,14 +166,6 @@ func (t *TcpHandler) HandleConnection(conn net.Conn) {
 		// Clean up all maps
 		delete(t.connToProtocolMap, remoteAddr)
 		delete(t.connToStoreMap, remoteAddr)
-
-		// More efficient cleanup using reverse mapping
-		for imei, info := range t.imeiToConnMap {
-			if info.Conn == conn {
-				delete(t.imeiToConnMap, imei)
-				break
-			}
-		}
 	}()
 
 	// Lock for store map update
@@ -130,9 +173,22 @@ func (t *TcpHandler) HandleConnection(conn net.Conn) {
 	t.connToStoreMap[remoteAddr] = dataStore
 	t.mu.Unlock()
 
-	if _, err = conn.Write(ack); err != nil {
-		logger.Error("Error writing login ack", zap.Error(err))
-		return
+	if len(ack) > 0 {
+		if err = session.write(ack); err != nil {
+			logger.Error("Error writing login ack", zap.Error(err))
+			return
+		}
+	}
+
+	// commands can only go out once the device has its login ack
+	if deviceID != "" {
+		t.registerSession(session)
+		defer t.unregisterSession(session)
+		reportSession(t.remoteStoreClient, connectedEvent(deviceID, remoteAddr, deviceProtocol, stats))
+		logger.Sugar().Infof("Mapped deviceID %s to connection %v", deviceID, remoteAddr)
+
+		// the reply to a queued command is read by ConsumeStream below
+		go t.deliverQueuedCommands(deviceID)
 	}
 
 	go func() {
@@ -146,17 +202,22 @@ func (t *TcpHandler) HandleConnection(conn net.Conn) {
 					logger.Error("failed to refresh read deadline", zap.Error(err))
 					return
 				}
-				if err := conn.SetWriteDeadline(time.Now().Add(20 * time.Second)); err != nil {
-					logger.Error("failed to refresh write deadline", zap.Error(err))
-					return
-				}
 			case <-ctx.Done():
 				return
 			}
 		}
 	}()
 
-	err = deviceProtocol.ConsumeStream(reader, conn, dataStore)
+	err = deviceProtocol.ConsumeStream(reader, sessionConn{Conn: conn, session: session}, dataStore)
+	if deviceID != "" {
+		reportSession(t.remoteStoreClient, disconnectedEvent(deviceID, remoteAddr, deviceProtocol, stats, err, session.closed()))
+	}
+	if errors.Is(err, errs.ErrBadCrc) {
+		crcFailuresTotal.WithLabelValues(deviceProtocol.GetProtocolType().String()).Inc()
+	}
+	if errors.Is(err, errs.ErrFrameTooLarge) {
+		framesTooLargeTotal.WithLabelValues(deviceProtocol.GetProtocolType().String()).Inc()
+	}
 	if err != nil && err != io.EOF {
 		logger.Error("Failure while reading from stream", zap.String("remoteAddr", remoteAddr), zap.Error(err))
 		return
@@ -166,19 +227,103 @@ func (t *TcpHandler) HandleConnection(conn net.Conn) {
 	}
 }
 
-func (t *TcpHandler) makeAsyncStore(deviceProtocol devices.DeviceProtocol) store.Store {
-	if t.storeType == "local" {
-		if err := os.Mkdir("./logs", os.ModeDir); err == nil || errors.Is(err, os.ErrExist) {
-			return makeJsonStore("./logs", deviceProtocol.GetDeviceID())
-		}
-	} else if t.storeType == "remote" {
-		return makeRemoteRpcStore(t.remoteStoreClient)
-	} else {
-		panic("Invalid store type")
+// UseCapture records the raw traffic of the connections to the config's dir, for replaying with
+// cmd/replay. A config without a dir stops capturing new connections.
+func (t *TcpHandler) UseCapture(config capture.Config) error {
+	if config.Dir == "" {
+		t.capture = nil
+		return nil
+	}
+	if err := os.MkdirAll(config.Dir, 0o700); err != nil {
+		return err
 	}
+	t.capture = &config
 	return nil
 }
 
+func (t *TcpHandler) makeAsyncStore(deviceProtocol devices.DeviceProtocol) (store.Store, error) {
+	if t.makeStore != nil {
+		return t.makeStore(deviceProtocol), nil
+	}
+	return makeAsyncStore(t.storeType, deviceProtocol, t.remoteStoreClient, t.sinks)
+}
+
+// StoreTypes are the store types storeType can list
+var StoreTypes = []string{"local", "remote", "mqtt", "kafka", "postgres", "sqlite"}
+
+// ParseStoreTypes splits the comma separated store types, for checking them once at startup
+func ParseStoreTypes(storeType string) ([]string, error) {
+	var storeTypes []string
+	for _, st := range strings.Split(storeType, ",") {
+		st = strings.TrimSpace(st)
+		if !slices.Contains(StoreTypes, st) {
+			return nil, fmt.Errorf("invalid store type %q, expected one of %s", st, strings.Join(StoreTypes, ", "))
+		}
+		if !slices.Contains(storeTypes, st) {
+			storeTypes = append(storeTypes, st)
+		}
+	}
+	return storeTypes, nil
+}
+
+// makeAsyncStore builds the store for a connection, storeType can be a comma separated list
+// of store types in which case the records are fanned out to each one of them
+func makeAsyncStore(storeType string, deviceProtocol devices.DeviceProtocol, remoteStoreClient store.CustomAvlDataStoreClient, sinks Sinks) (store.Store, error) {
+	storeTypes, err := ParseStoreTypes(storeType)
+	if err != nil {
+		return nil, err
+	}
+	if len(storeTypes) == 1 {
+		return makeStoreForType(storeTypes[0], deviceProtocol, remoteStoreClient, sinks)
+	}
+
+	multiStore := &store.MultiStore{
+		ProcessChan:       make(chan *types.DeviceStatus, 200),
+		ResponseChan:      make(chan *types.DeviceResponse, 200),
+		CloseChan:         make(chan bool, 1),
+		CloseResponseChan: make(chan bool, 1),
+	}
+	for _, st := range storeTypes {
+		s, err := makeStoreForType(st, deviceProtocol, remoteStoreClient, sinks)
+		if err != nil {
+			return nil, err
+		}
+		multiStore.Stores = append(multiStore.Stores, s)
+	}
+	return multiStore, nil
+}
+
+func makeStoreForType(storeType string, deviceProtocol devices.DeviceProtocol, remoteStoreClient store.CustomAvlDataStoreClient, sinks Sinks) (store.Store, error) {
+	switch storeType {
+	case "local":
+		return makeJsonStore(sinks.Local, deviceProtocol.GetDeviceID())
+	case "remote":
+		return makeRemoteRpcStore(remoteStoreClient), nil
+	case "mqtt":
+		if sinks.Mqtt == nil {
+			return nil, errors.New("mqtt store requested without an mqtt publisher")
+		}
+		return makeMqttStore(sinks.Mqtt, deviceProtocol.GetDeviceType()), nil
+	case "kafka":
+		if sinks.Kafka == nil {
+			return nil, errors.New("kafka store requested without a kafka publisher")
+		}
+		return makeKafkaStore(sinks.Kafka), nil
+	case "postgres":
+		if sinks.Postgres == nil {
+			return nil, errors.New("postgres store requested without a postgres writer")
+		}
+		return makePostgresStore(sinks.Postgres), nil
+	case "sqlite":
+		if sinks.Sqlite == nil {
+			return nil, errors.New("sqlite store requested without a sqlite database")
+		}
+		return makeSqliteStore(sinks.Sqlite, deviceProtocol), nil
+	default:
+		return nil, fmt.Errorf("invalid store type %q", storeType)
+	}
+}
+
 func makeRemoteRpcStore(remoteStoreClient store.CustomAvlDataStoreClient) store.Store {
 	return &store.RemoteRpcStore{
 		ProcessChan:       make(chan *types.DeviceStatus, 200),
@@ -189,32 +334,118 @@ func makeRemoteRpcStore(remoteStoreClient store.CustomAvlDataStoreClient) store.
 	}
 }
 
+func makeMqttStore(publisher *store.MqttPublisher, deviceType types.DeviceType) store.Store {
+	return &store.MqttStore{
+		ProcessChan:       make(chan *types.DeviceStatus, 200),
+		ResponseChan:      make(chan *types.DeviceResponse, 200),
+		CloseChan:         make(chan bool, 1),
+		CloseResponseChan: make(chan bool, 1),
+		Publisher:         publisher,
+		DeviceType:        deviceType,
+	}
+}
+
+func makeKafkaStore(publisher *store.KafkaPublisher) store.Store {
+	return &store.KafkaStore{
+		ProcessChan:       make(chan *types.DeviceStatus, 200),
+		ResponseChan:      make(chan *types.DeviceResponse, 200),
+		CloseChan:         make(chan bool, 1),
+		CloseResponseChan: make(chan bool, 1),
+		Publisher:         publisher,
+	}
+}
+
+func makePostgresStore(writer *store.PostgresWriter) store.Store {
+	return &store.PostgresStore{
+		ProcessChan:       make(chan *types.DeviceStatus, 200),
+		ResponseChan:      make(chan *types.DeviceResponse, 200),
+		CloseChan:         make(chan bool, 1),
+		CloseResponseChan: make(chan bool, 1),
+		Writer:            writer,
+	}
+}
+
+func makeSqliteStore(db *store.SqliteDB, deviceProtocol devices.DeviceProtocol) store.Store {
+	return &store.SqliteStore{
+		ProcessChan:       make(chan *types.DeviceStatus, 200),
+		ResponseChan:      make(chan *types.DeviceResponse, 200),
+		CloseChan:         make(chan bool, 1),
+		CloseResponseChan: make(chan bool, 1),
+		DB:                db,
+		Imei:              deviceProtocol.GetDeviceID(),
+		DeviceType:        deviceProtocol.GetDeviceType(),
+		Protocol:          deviceProtocol.GetProtocolType(),
+	}
+}
+
+// UsesRemoteStore tells if one of the comma separated store types is the remote data store
+func UsesRemoteStore(storeType string) bool {
+	for _, st := range strings.Split(storeType, ",") {
+		if strings.TrimSpace(st) == "remote" {
+			return true
+		}
+	}
+	return false
+}
+
 func (t *TcpHandler) VerifyDevice(deviceID string, detectedProtocol types.DeviceProtocolType) (types.DeviceType, error) {
-	if t.storeType == "local" {
-		return devices.GetDeviceTypesForProtocol(detectedProtocol)[0], nil
-	} else {
-		req := store.VerifyDeviceRequest{Imei: deviceID}
-		reply, err := t.remoteStoreClient.VerifyDevice(context.Background(), &req)
-		if err != nil {
+	if t.storeType != "local" && t.remoteStoreClient.IsConfigured() {
+		device, err := t.verifyWithRemoteStore(deviceID)
+		if errors.Is(err, errs.ErrStoreUnavailable) {
+			// the device retries its login, which gets through once the store is back
+			logger.Error("Data store unavailable, rejecting device login", zap.String("deviceID", deviceID), zap.Error(err))
+			return 0, err
+		} else if err != nil {
 			logger.Error("Failed to verify device", zap.String("deviceID", deviceID), zap.String("detectedProtocol", detectedProtocol.String()), zap.Error(err))
 			return 0, err
 		}
 
-		if reply.GetImei() != deviceID ||
-			!slices.Contains(devices.GetDeviceTypesForProtocol(detectedProtocol), reply.GetDeviceType()) {
+		if !device.known || !slices.Contains(devices.GetDeviceTypesForProtocol(detectedProtocol), device.deviceType) {
+			return 0, errs.ErrUnauthorizedDevice
+		}
+		return device.deviceType, nil
+	} else if t.sinks.Sqlite != nil {
+		return verifyDeviceWithSqlite(t.sinks.Sqlite, deviceID, detectedProtocol)
+	}
+
+	// without a device registry every device is accepted
+	return devices.GetDeviceTypesForProtocol(detectedProtocol)[0], nil
+}
+
+func verifyDeviceWithSqlite(db *store.SqliteDB, deviceID string, detectedProtocol types.DeviceProtocolType) (types.DeviceType, error) {
+	ctx := context.Background()
+	deviceTypes := devices.GetDeviceTypesForProtocol(detectedProtocol)
+
+	device, err := db.GetDevice(ctx, deviceID)
+	if errors.Is(err, store.ErrDeviceNotFound) {
+		if !db.AutoRegister {
 			return 0, errs.ErrUnauthorizedDevice
 		}
-		return reply.GetDeviceType(), nil
+		if err := db.UpsertDevice(ctx, deviceID, deviceTypes[0], ""); err != nil {
+			return 0, err
+		}
+		logger.Info("Registered new device", zap.String("deviceID", deviceID), zap.String("deviceType", deviceTypes[0].String()))
+		return deviceTypes[0], nil
+	} else if err != nil {
+		logger.Error("Failed to verify device", zap.String("deviceID", deviceID), zap.String("detectedProtocol", detectedProtocol.String()), zap.Error(err))
+		return 0, err
+	}
+
+	if !slices.Contains(deviceTypes, device.DeviceType) {
+		return 0, errs.ErrUnauthorizedDevice
 	}
+	return device.DeviceType, nil
 }
 
-func makeJsonStore(destDir string, deviceIdentifier string) store.Store {
-	file, err := os.OpenFile(path.Join(destDir, deviceIdentifier+".json"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+func makeJsonStore(config store.RotationConfig, deviceIdentifier string) (store.Store, error) {
+	if config.Dir == "" {
+		config.Dir = "./logs"
+	}
+	file, err := store.OpenRotatingFile(deviceIdentifier, config)
 	if err != nil {
-		logger.Error("failed to open file to store data")
-		logger.Panic(err.Error())
+		return nil, fmt.Errorf("failed to open file to store data: %w", err)
 	}
-	logger.Sugar().Infof("[deviceId: %s] Created json file store at %s", deviceIdentifier, file.Name())
+	logger.Sugar().Infof("[deviceId: %s] Created json file store in %s", deviceIdentifier, config.Dir)
 
 	return &store.JsonLinesStore{
 		File:              file,
@@ -223,30 +454,33 @@ func makeJsonStore(destDir string, deviceIdentifier string) store.Store {
 		CloseChan:         make(chan bool, 200),
 		CloseResponseChan: make(chan bool, 200),
 		DeviceID:          deviceIdentifier,
-	}
+	}, nil
 }
 
+// attemptDeviceLogin identifies the device, the protocol is also returned for the devices turned
+// away as unauthorized or banned so they can be reported
 func (t *TcpHandler) attemptDeviceLogin(reader *bufio.Reader) (protocol devices.DeviceProtocol, ack []byte, err error) {
 	defer func() {
 		if r := recover(); r != nil {
 			logger.Sugar().Error("Panic occurred during protocol login: ", r)
 			err = fmt.Errorf("panic occurred during protocol login: %v", r)
+			protocol = nil
 		}
 	}()
 
 	for _, protocolType := range t.allowedProtocols {
-		logger.Sugar().Info("Attempting device login with ProtocolType: ", protocolType)
+		logger.Sugar().Debug("Attempting device login with ProtocolType: ", protocolType)
 		protocol = devices.MakeProtocolForType(protocolType)
-		logger.Sugar().Info("Created Protocol: ", protocol)
+		logger.Sugar().Debug("Created Protocol: ", protocol)
 
 		if protocol == nil {
 			logger.Sugar().Error("Unsupported ProtocolType: ", protocolType)
 			continue
 		}
 
-		logger.Sugar().Info("Attempting to login with protocol: ", protocolType)
+		logger.Sugar().Debug("Attempting to login with protocol: ", protocolType)
 		ack, bytesToSkip, err := protocol.Login(reader)
-		logger.Sugar().Infof("Acknowledgement: %v for bytes to skip: %d and error: %v", ack, bytesToSkip, err)
+		logger.Sugar().Debugf("Acknowledgement: %v for bytes to skip: %d and error: %v", ack, bytesToSkip, err)
 
 		if err != nil {
 			if errors.Is(err, errs.ErrUnknownProtocol) {
@@ -259,26 +493,31 @@ func (t *TcpHandler) attemptDeviceLogin(reader *bufio.Reader) (protocol devices.
 
 		// Only call GetDeviceID after a successful login
 		deviceID := protocol.GetDeviceID()
-		logger.Sugar().Infof("Device ID: %s", deviceID)
+		logger.Sugar().Debugf("Device ID: %s", deviceID)
 
 		if deviceID == "" {
 			logger.Error("Device ID is empty after successful login")
 			continue
 		}
 
+		if _, banned := t.bans.banned(deviceID); banned {
+			t.limitedDevice(deviceID, "", store.DeviceLimitReason_LIMIT_BANNED)
+			return protocol, nil, fmt.Errorf("%w: %s", errs.ErrDeviceBanned, deviceID)
+		}
+
 		logger.Info("Device identified", zap.String("protocol", protocolType.String()), zap.String("deviceID", deviceID), zap.Int("bytesToSkip", bytesToSkip))
 		if _, err := reader.Discard(bytesToSkip); err != nil {
 			logger.Sugar().Error("Error discarding bytes: ", err)
 			return nil, nil, err
 		}
-		logger.Sugar().Infoln("protocol.GetProtocolType()", protocol.GetProtocolType())
+		logger.Sugar().Debugln("protocol.GetProtocolType()", protocol.GetProtocolType())
 
 		deviceType, err := t.VerifyDevice(deviceID, protocol.GetProtocolType())
-		logger.Sugar().Info("device Type: ", deviceType, " error: ", err)
+		logger.Sugar().Debug("device Type: ", deviceType, " error: ", err)
 		if err != nil {
 			if errors.Is(err, errs.ErrUnauthorizedDevice) {
 				logger.Error("Device is not authorized", zap.String("deviceID", deviceID), zap.String("protocolType", protocol.GetProtocolType().String()))
-				return nil, nil, err
+				return protocol, nil, err
 			}
 			logger.Sugar().Error("Error verifying device: ", err)
 			return nil, nil, err
@@ -292,10 +531,3 @@ func (t *TcpHandler) attemptDeviceLogin(reader *bufio.Reader) (protocol devices.
 	logger.Sugar().Error("All protocols failed, unknown device type")
 	return nil, nil, errs.ErrUnknownDeviceType
 }
-
-// Getter for connection info by IMEI
-
-func (t *TcpHandler) GetConnInfoByIMEI(imei string) (DeviceConnectionInfo, bool) {
-	info, exists := t.imeiToConnMap[imei]
-	return info, exists
-}
@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"runtime"
+	"slices"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	devices "github.com/404minds/avl-receiver/internal/protocols"
+	"github.com/404minds/avl-receiver/internal/simulator"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+// go test -run '^$' -bench . -benchmem ./internal/handlers/ measures logins and records per second
+// of every protocol, go test -run TestSoak -soak.connections 10000 ./internal/handlers/ the memory
+// an idle connection takes. Both go through HandleConnection over loopback tcp into benchSink.
+var (
+	soakConnections = flag.Int("soak.connections", 0, "Idle connections TestSoak opens, skipped if 0")
+	soakProtocol    = flag.String("soak.protocol", "codec8", "Simulated protocol of the soak's connections")
+	soakHold        = flag.Duration("soak.hold", 30*time.Second, "How long the soak's connections stay idle before they report a position")
+)
+
+const benchImeiBase = 861234567890000
+
+// benchProtocols are the simulator's protocols the receiver takes over tcp, tr06 devices log in as
+// GT06 which drops them on their first 0x12 position
+func benchProtocols() []string {
+	return slices.DeleteFunc(simulator.Protocols(), func(name string) bool { return name == "tr06" })
+}
+
+// benchSink counts what the stores of all the connections get, in place of a data store
+type benchSink struct {
+	mu       sync.Mutex
+	changed  *sync.Cond
+	logins   int // a store is made for every login
+	statuses int
+}
+
+func newBenchSink() *benchSink {
+	s := &benchSink{}
+	s.changed = sync.NewCond(&s.mu)
+	return s
+}
+
+func (s *benchSink) makeStore(devices.DeviceProtocol) store.Store {
+	s.add(&s.logins)
+	return &benchStore{
+		sink:              s,
+		processChan:       make(chan *types.DeviceStatus, 200),
+		responseChan:      make(chan *types.DeviceResponse, 200),
+		closeChan:         make(chan bool, 1),
+		closeResponseChan: make(chan bool, 1),
+	}
+}
+
+func (s *benchSink) add(counter *int) {
+	s.mu.Lock()
+	*counter++
+	s.mu.Unlock()
+	s.changed.Broadcast()
+}
+
+// waitFor waits until the counter is at least n, or the timeout is up
+func (s *benchSink) waitFor(counter *int, n int, timeout time.Duration) error {
+	timer := time.AfterFunc(timeout, s.changed.Broadcast)
+	defer timer.Stop()
+	deadline := time.Now().Add(timeout)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for *counter < n {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%d of %d after %s", *counter, n, timeout)
+		}
+		s.changed.Wait()
+	}
+	return nil
+}
+
+type benchStore struct {
+	sink              *benchSink
+	processChan       chan *types.DeviceStatus
+	responseChan      chan *types.DeviceResponse
+	closeChan         chan bool
+	closeResponseChan chan bool
+}
+
+func (s *benchStore) Process(ctx context.Context) {
+	for {
+		select {
+		case <-s.processChan:
+			s.sink.add(&s.sink.statuses)
+		case <-s.closeChan:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *benchStore) Response(ctx context.Context) {
+	for {
+		select {
+		case <-s.responseChan:
+		case <-s.closeResponseChan:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *benchStore) GetProcessChan() chan *types.DeviceStatus    { return s.processChan }
+func (s *benchStore) GetResponseChan() chan *types.DeviceResponse { return s.responseChan }
+func (s *benchStore) GetCloseChan() chan bool                     { return s.closeChan }
+func (s *benchStore) GetCloseResponseChan() chan bool             { return s.closeResponseChan }
+
+// startBenchReceiver serves a tcp handler storing into the sink on a loopback port. Logging is off
+// for the rest of the run, the handlers log their connections closing after the benchmark too.
+func startBenchReceiver(tb testing.TB, sink *benchSink) string {
+	configuredLogger.SetLevel(zapcore.FatalLevel)
+
+	handler := NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", Sinks{})
+	handler.makeStore = sink.makeStore
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(tb, err)
+	tb.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			if err == nil {
+				go handler.HandleConnection(conn)
+			}
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// benchDevice is a connection sending pre-encoded frames
+type benchDevice struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func dialBenchDevice(addr string) (*benchDevice, error) {
+	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return &benchDevice{conn: conn, reader: bufio.NewReader(conn)}, nil
+}
+
+func (d *benchDevice) send(frame simulator.Frame) error {
+	_ = d.conn.SetDeadline(time.Now().Add(10 * time.Second))
+	if _, err := d.conn.Write(frame.Data); err != nil {
+		return err
+	}
+	if frame.Ack == nil {
+		return nil
+	}
+	return frame.Ack(d.reader)
+}
+
+// login connects a device and waits for the receiver to log it in, the sink's logins are counted
+// on top of the ones before
+func login(addr string, sink *benchSink, codec simulator.Codec, logins int) (*benchDevice, error) {
+	d, err := dialBenchDevice(addr)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.send(codec.Login(simulator.DefaultRoute.Track(0).Fix(time.Now()))); err != nil {
+		d.conn.Close()
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	// not every protocol acks its login
+	if err := sink.waitFor(&sink.logins, logins, 10*time.Second); err != nil {
+		d.conn.Close()
+		return nil, fmt.Errorf("login: %w", err)
+	}
+	return d, nil
+}
+
+func BenchmarkLogin(b *testing.B) {
+	for _, protocol := range benchProtocols() {
+		b.Run(protocol, func(b *testing.B) {
+			sink := newBenchSink()
+			addr := startBenchReceiver(b, sink)
+			codecs := make([]simulator.Codec, b.N)
+			for i := range codecs {
+				codec, err := simulator.CodecFor(protocol, fmt.Sprint(benchImeiBase+i))
+				require.NoError(b, err)
+				codecs[i] = codec
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i, codec := range codecs {
+				d, err := login(addr, sink, codec, i+1)
+				if err != nil {
+					b.Fatal(err)
+				}
+				d.conn.Close()
+			}
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "logins/s")
+		})
+	}
+}
+
+// BenchmarkRecords streams position reports of 10 fixes over one connection, an op is a record
+func BenchmarkRecords(b *testing.B) {
+	const batch = 10
+	for _, protocol := range benchProtocols() {
+		b.Run(protocol, func(b *testing.B) {
+			sink := newBenchSink()
+			addr := startBenchReceiver(b, sink)
+			codec, err := simulator.CodecFor(protocol, fmt.Sprint(benchImeiBase))
+			require.NoError(b, err)
+			d, err := login(addr, sink, codec, 1)
+			require.NoError(b, err)
+			defer d.conn.Close()
+
+			// encoded up front so the device's work isn't measured
+			track := simulator.DefaultRoute.Track(0)
+			start := time.Now().Add(-time.Duration(b.N) * time.Second)
+			var frames []simulator.Frame
+			for sent := 0; sent < b.N; sent += batch {
+				fixes := make([]simulator.Fix, min(batch, b.N-sent))
+				for i := range fixes {
+					fixes[i] = track.Next(start.Add(time.Duration(sent+i)*time.Second), time.Second)
+				}
+				frames = append(frames, codec.Positions(fixes))
+			}
+			statuses := sink.statuses
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for _, frame := range frames {
+				if err := d.send(frame); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if err := sink.waitFor(&sink.statuses, statuses+b.N, time.Minute); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "records/s")
+		})
+	}
+}
+
+// raiseFileLimit raises the open files limit to what n connections take on both ends, as far as the
+// hard limit lets it
+func raiseFileLimit(n int) error {
+	var limit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &limit); err != nil {
+		return err
+	}
+	want := uint64(2*n + 256)
+	if limit.Cur >= want {
+		return nil
+	}
+	if limit.Max < want {
+		return fmt.Errorf("%d connections need %d open files, the hard limit is %d (ulimit -Hn)", n, want, limit.Max)
+	}
+	limit.Cur = want
+	return syscall.Setrlimit(syscall.RLIMIT_NOFILE, &limit)
+}
+
+type memory struct {
+	bytes      uint64 // heap and goroutine stacks in use
+	goroutines int
+}
+
+func measureMemory() memory {
+	runtime.GC()
+	var stats runtime.MemStats
+	runtime.ReadMemStats(&stats)
+	return memory{bytes: stats.HeapInuse + stats.StackInuse, goroutines: runtime.NumGoroutine()}
+}
+
+// TestSoak logs in -soak.connections devices, measures the memory they take while idle and after
+// holding them for -soak.hold checks every one of them still gets its positions through. The device
+// ends of the connections are in this process too, they take a small part of the memory measured.
+func TestSoak(t *testing.T) {
+	n := *soakConnections
+	if n == 0 {
+		t.Skip("no -soak.connections")
+	}
+	require.NoError(t, raiseFileLimit(n))
+	sink := newBenchSink()
+	addr := startBenchReceiver(t, sink)
+	before := measureMemory()
+
+	// the handler's read deadline is refreshed while the device is connected, so idle is fine
+	codecs := make([]simulator.Codec, n)
+	devices := make([]*benchDevice, n)
+	defer func() {
+		for _, d := range devices {
+			if d != nil {
+				d.conn.Close()
+			}
+		}
+	}()
+	start := time.Now()
+	for i := range devices {
+		codec, err := simulator.CodecFor(*soakProtocol, fmt.Sprint(benchImeiBase+i))
+		require.NoError(t, err)
+		codecs[i] = codec
+		devices[i], err = login(addr, sink, codec, i+1)
+		require.NoError(t, err, "device %d", i)
+	}
+	t.Logf("%d logins in %s", n, time.Since(start).Round(time.Millisecond))
+
+	idle := measureMemory()
+	t.Logf("idle: %d bytes and %.1f goroutines per connection",
+		(idle.bytes-before.bytes)/uint64(n), float64(idle.goroutines-before.goroutines)/float64(n))
+
+	time.Sleep(*soakHold)
+	track := simulator.DefaultRoute.Track(0)
+	start = time.Now()
+	for i, d := range devices {
+		require.NoError(t, d.send(codecs[i].Positions([]simulator.Fix{track.Next(time.Now(), time.Second)})), "device %d", i)
+	}
+	require.NoError(t, sink.waitFor(&sink.statuses, n, time.Minute))
+	t.Logf("a position from each after %s idle in %s", *soakHold, time.Since(start).Round(time.Millisecond))
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/hex"
+	"errors"
 	"testing"
 
 	errs "github.com/404minds/avl-receiver/internal/errors"
@@ -14,33 +15,38 @@ import (
 	"github.com/404minds/avl-receiver/internal/types"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc"
-	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// mockRemoteDataStore answers the data store calls in place of a grpc connection
 type mockRemoteDataStore struct {
 	Imei       string
 	DeviceType types.DeviceType
 }
 
-func (s *mockRemoteDataStore) VerifyDevice(ctx context.Context, in *store.VerifyDeviceRequest, opts ...grpc.CallOption) (*store.VerifyDeviceReply, error) {
-	return &store.VerifyDeviceReply{
-		Imei:       s.Imei,
-		DeviceType: s.DeviceType,
-	}, nil
+func (s *mockRemoteDataStore) Invoke(ctx context.Context, method string, args any, reply any, opts ...grpc.CallOption) error {
+	if verifyReply, ok := reply.(*store.VerifyDeviceReply); ok {
+		verifyReply.Imei = s.Imei
+		verifyReply.DeviceType = s.DeviceType
+	}
+	return nil
 }
 
-func (s *mockRemoteDataStore) SaveDeviceStatus(ctx context.Context, in *types.DeviceStatus, opts ...grpc.CallOption) (*emptypb.Empty, error) {
-	return &emptypb.Empty{}, nil
+func (s *mockRemoteDataStore) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return nil, errors.New("streams not supported")
+}
+
+func mockRemoteClient(s *mockRemoteDataStore) store.CustomAvlDataStoreClient {
+	return *store.NewCustomAvlDataStoreClient(s, "")
 }
 
 func TestTeltonikaDeviceLogin(t *testing.T) {
 	buf, _ := hex.DecodeString("000F333536333037303433373231353739")
 
 	reader := bufio.NewReader(bytes.NewReader(buf))
-	handler := NewTcpHandler(&mockRemoteDataStore{
+	handler := NewTcpHandler(mockRemoteClient(&mockRemoteDataStore{
 		Imei:       "356307043721579",
 		DeviceType: types.DeviceType_TELTONIKA,
-	}, "")
+	}), "", Sinks{})
 	protocol, ack, err := handler.attemptDeviceLogin(reader)
 
 	assert.NoError(t, err, "device login should succeed")
@@ -54,25 +60,41 @@ func TestWanwayDeviceLogin(t *testing.T) {
 	buf, _ := hex.DecodeString(hexString)
 
 	reader := bufio.NewReader(bytes.NewReader(buf))
-	handler := NewTcpHandler(&mockRemoteDataStore{
+	handler := NewTcpHandler(mockRemoteClient(&mockRemoteDataStore{
 		Imei:       "752533678900242",
 		DeviceType: types.DeviceType_WANWAY,
-	}, "")
+	}), "", Sinks{})
 	protocol, ack, err := handler.attemptDeviceLogin(reader)
 
 	assert.NoError(t, err, "device login should succeed")
 	assert.IsType(t, &gt06.GT06Protocol{}, protocol, "protocol should be of type GT06Protocol")
 	assert.Equal(t, "752533678900242", protocol.GetDeviceID(), "imei should be parsed correctly")
-	assert.Equal(t, []byte{0x78, 0x78, 0x11, 0x01, 0x00, 0x05, 0x12, 0x79, 0x0d, 0x0a}, ack, "login ack should be of the format as GT06 expects")
+	assert.Equal(t, []byte{0x78, 0x78, 0x05, 0x01, 0x00, 0x05, 0x9f, 0xf8, 0x0d, 0x0a}, ack, "login ack should be of the format as GT06 expects")
 }
 
 func TestUnknownDeviceLogin(t *testing.T) {
 	buf, _ := hex.DecodeString("7676fafafafa")
 	reader := bufio.NewReader(bytes.NewReader(buf))
-	handler := NewTcpHandler(&mockRemoteDataStore{}, "")
+	handler := NewTcpHandler(mockRemoteClient(&mockRemoteDataStore{}), "", Sinks{})
 	protocol, ack, err := handler.attemptDeviceLogin(reader)
 
 	assert.Nil(t, protocol, "protocol should be nil")
 	assert.Nil(t, ack, "ack should be nil")
 	assert.ErrorIs(t, err, errs.ErrUnknownDeviceType, "error should be ErrUnknownDevice")
 }
+
+func TestParseStoreTypes(t *testing.T) {
+	storeTypes, err := ParseStoreTypes("remote, mqtt,remote")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"remote", "mqtt"}, storeTypes)
+
+	_, err = ParseStoreTypes("remote,mqt")
+	assert.ErrorContains(t, err, `"mqt"`)
+	_, err = ParseStoreTypes("")
+	assert.Error(t, err)
+}
+
+func TestMakeStoreWithoutItsSink(t *testing.T) {
+	_, err := makeAsyncStore("remote,kafka", &fm1200.FM1200Protocol{}, store.CustomAvlDataStoreClient{}, Sinks{})
+	assert.Error(t, err, "kafka without a publisher is an error, not a panic")
+}
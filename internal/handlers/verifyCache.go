@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"go.uber.org/zap"
+)
+
+// verifyCacheSweepInterval is how often entries too old to be used are dropped
+const verifyCacheSweepInterval = time.Minute
+
+// VerifyCacheConfig is how long the remote store's answer to VerifyDevice is reused for
+type VerifyCacheConfig struct {
+	TTL         time.Duration // known devices, 0 disables the cache
+	NegativeTTL time.Duration // devices the store doesn't know, 0 doesn't cache them
+	// known devices older than TTL are still let in for this long while they're verified again
+	// in the background, so they can log in when the store is down. 0 verifies them before login.
+	StaleTTL time.Duration
+}
+
+// verifiedDevice is the store's reply for an imei, checking it against the login protocol is
+// left to the caller since the same imei can show up with another protocol
+type verifiedDevice struct {
+	known      bool
+	deviceType types.DeviceType
+	verifiedAt time.Time
+}
+
+type verifyCache struct {
+	config VerifyCacheConfig
+	lookup func(ctx context.Context, imei string) (verifiedDevice, error)
+	now    func() time.Time
+
+	mu           sync.Mutex
+	entries      map[string]verifiedDevice
+	revalidating map[string]bool
+	generation   uint64 // bumped on invalidation so lookups started before it aren't cached
+	lastSweep    time.Time
+}
+
+func newVerifyCache(config VerifyCacheConfig, lookup func(ctx context.Context, imei string) (verifiedDevice, error)) *verifyCache {
+	return &verifyCache{
+		config:       config,
+		lookup:       lookup,
+		now:          time.Now,
+		entries:      make(map[string]verifiedDevice),
+		revalidating: make(map[string]bool),
+	}
+}
+
+func (c *verifyCache) get(imei string) (verifiedDevice, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[imei]
+	age := c.now().Sub(entry.verifiedAt)
+	switch {
+	case ok && entry.known && age < c.config.TTL:
+		c.mu.Unlock()
+		return entry, nil
+	case ok && !entry.known && age < c.config.NegativeTTL:
+		c.mu.Unlock()
+		return entry, nil
+	case ok && entry.known && age < c.config.TTL+c.config.StaleTTL:
+		revalidate := !c.revalidating[imei]
+		c.revalidating[imei] = true
+		generation := c.generation
+		c.mu.Unlock()
+		if revalidate {
+			go c.revalidate(imei, generation)
+		}
+		return entry, nil
+	}
+	generation := c.generation
+	c.mu.Unlock()
+
+	entry, err := c.lookup(context.Background(), imei)
+	if err != nil {
+		return entry, err
+	}
+	c.put(imei, entry, generation)
+	return entry, nil
+}
+
+func (c *verifyCache) revalidate(imei string, generation uint64) {
+	defer func() {
+		c.mu.Lock()
+		delete(c.revalidating, imei)
+		c.mu.Unlock()
+	}()
+
+	entry, err := c.lookup(context.Background(), imei)
+	if err != nil {
+		logger.Warn("failed to verify device again, keeping the cached verification", zap.String("imei", imei), zap.Error(err))
+		return
+	}
+	c.put(imei, entry, generation)
+}
+
+func (c *verifyCache) put(imei string, entry verifiedDevice, generation uint64) {
+	if (entry.known && c.config.TTL <= 0) || (!entry.known && c.config.NegativeTTL <= 0) {
+		return
+	}
+	entry.verifiedAt = c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.entries[imei] = entry
+	c.sweep(entry.verifiedAt)
+}
+
+// expired is true once the entry can't be used anymore, not even while it's verified again
+func (c *verifyCache) expired(entry verifiedDevice, now time.Time) bool {
+	age := now.Sub(entry.verifiedAt)
+	if entry.known {
+		return age >= c.config.TTL+c.config.StaleTTL
+	}
+	return age >= c.config.NegativeTTL
+}
+
+// sweep drops the expired entries, so imeis that don't log in again like a scanner's don't pile
+// up. Called with mu held.
+func (c *verifyCache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < verifyCacheSweepInterval {
+		return
+	}
+	c.lastSweep = now
+	for imei, entry := range c.entries {
+		if c.expired(entry, now) {
+			delete(c.entries, imei)
+		}
+	}
+}
+
+// invalidate drops the given imeis, or every entry if none are given, and returns how many were dropped
+func (c *verifyCache) invalidate(imeis []string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	if len(imeis) == 0 {
+		n := len(c.entries)
+		clear(c.entries)
+		return n
+	}
+	n := 0
+	for _, imei := range imeis {
+		if _, ok := c.entries[imei]; ok {
+			delete(c.entries, imei)
+			n++
+		}
+	}
+	return n
+}
+
+// UseVerifyCache caches the remote store's device verifications
+func (t *TcpHandler) UseVerifyCache(config VerifyCacheConfig) {
+	if config.TTL <= 0 {
+		t.verifyCache = nil
+		return
+	}
+	t.verifyCache = newVerifyCache(config, t.lookupDevice)
+}
+
+// InvalidateVerifyCache makes the next login of the devices go to the remote store, e.g. after one
+// was provisioned or revoked. Without imeis the whole cache is dropped.
+func (t *TcpHandler) InvalidateVerifyCache(imeis []string) int {
+	if t.verifyCache == nil {
+		return 0
+	}
+	n := t.verifyCache.invalidate(imeis)
+	logger.Info("invalidated cached device verifications", zap.Strings("imeis", imeis), zap.Int("invalidated", n))
+	return n
+}
+
+func (t *TcpHandler) lookupDevice(ctx context.Context, imei string) (verifiedDevice, error) {
+	reply, err := t.remoteStoreClient.VerifyDevice(ctx, &store.VerifyDeviceRequest{Imei: imei})
+	if err != nil {
+		return verifiedDevice{}, err
+	}
+	return verifiedDevice{known: reply.GetImei() == imei, deviceType: reply.GetDeviceType()}, nil
+}
+
+// verifyWithRemoteStore goes through the cache if there is one
+func (t *TcpHandler) verifyWithRemoteStore(imei string) (verifiedDevice, error) {
+	if t.verifyCache != nil {
+		return t.verifyCache.get(imei)
+	}
+	return t.lookupDevice(context.Background(), imei)
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	errs "github.com/404minds/avl-receiver/internal/errors"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry stands in for the remote store, counting lookups per imei
+type fakeRegistry struct {
+	mu      sync.Mutex
+	known   map[string]types.DeviceType
+	down    bool
+	lookups map[string]int
+}
+
+func (f *fakeRegistry) lookup(ctx context.Context, imei string) (verifiedDevice, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lookups[imei]++
+	if f.down {
+		return verifiedDevice{}, errs.ErrStoreUnavailable
+	}
+	deviceType, known := f.known[imei]
+	return verifiedDevice{known: known, deviceType: deviceType}, nil
+}
+
+func (f *fakeRegistry) set(fn func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	fn()
+}
+
+func (f *fakeRegistry) lookupCount(imei string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lookups[imei]
+}
+
+func newTestVerifyCache(config VerifyCacheConfig) (*verifyCache, *fakeRegistry, *time.Time) {
+	registry := &fakeRegistry{
+		known:   map[string]types.DeviceType{"357454075177072": types.DeviceType_TELTONIKA},
+		lookups: make(map[string]int),
+	}
+	cache := newVerifyCache(config, registry.lookup)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+	return cache, registry, &now
+}
+
+func TestVerifyCacheCachesKnownAndUnknownDevices(t *testing.T) {
+	cache, registry, now := newTestVerifyCache(VerifyCacheConfig{TTL: time.Minute, NegativeTTL: 10 * time.Second})
+
+	for i := 0; i < 3; i++ {
+		device, err := cache.get("357454075177072")
+		require.NoError(t, err)
+		assert.True(t, device.known)
+		assert.Equal(t, types.DeviceType_TELTONIKA, device.deviceType)
+
+		device, err = cache.get("000000000000000")
+		require.NoError(t, err)
+		assert.False(t, device.known)
+	}
+	assert.Equal(t, 1, registry.lookupCount("357454075177072"))
+	assert.Equal(t, 1, registry.lookupCount("000000000000000"))
+
+	// unknown devices expire sooner, e.g. so a newly provisioned device gets in
+	*now = now.Add(30 * time.Second)
+	_, _ = cache.get("357454075177072")
+	_, _ = cache.get("000000000000000")
+	assert.Equal(t, 1, registry.lookupCount("357454075177072"))
+	assert.Equal(t, 2, registry.lookupCount("000000000000000"))
+
+	*now = now.Add(time.Minute)
+	_, _ = cache.get("357454075177072")
+	assert.Equal(t, 2, registry.lookupCount("357454075177072"))
+}
+
+func TestVerifyCacheDoesNotCacheStoreErrors(t *testing.T) {
+	cache, registry, _ := newTestVerifyCache(VerifyCacheConfig{TTL: time.Minute, NegativeTTL: time.Minute})
+	registry.set(func() { registry.down = true })
+
+	_, err := cache.get("357454075177072")
+	assert.ErrorIs(t, err, errs.ErrStoreUnavailable)
+
+	registry.set(func() { registry.down = false })
+	device, err := cache.get("357454075177072")
+	require.NoError(t, err)
+	assert.True(t, device.known)
+	assert.Equal(t, 2, registry.lookupCount("357454075177072"))
+}
+
+func TestVerifyCacheServesStaleDevicesWhileStoreIsDown(t *testing.T) {
+	cache, registry, now := newTestVerifyCache(VerifyCacheConfig{TTL: time.Minute, StaleTTL: time.Hour})
+	_, err := cache.get("357454075177072")
+	require.NoError(t, err)
+
+	registry.set(func() { registry.down = true })
+	*now = now.Add(30 * time.Minute)
+	device, err := cache.get("357454075177072")
+	require.NoError(t, err, "known devices still log in during the outage")
+	assert.True(t, device.known)
+	// verified again in the background, which fails and keeps the entry
+	assert.Eventually(t, func() bool { return registry.lookupCount("357454075177072") == 2 }, time.Second, 5*time.Millisecond)
+
+	*now = now.Add(time.Hour)
+	_, err = cache.get("357454075177072")
+	assert.ErrorIs(t, err, errs.ErrStoreUnavailable, "past the stale window the store has to answer")
+}
+
+func TestVerifyCacheRevalidatesStaleEntries(t *testing.T) {
+	cache, registry, now := newTestVerifyCache(VerifyCacheConfig{TTL: time.Minute, NegativeTTL: time.Minute, StaleTTL: time.Hour})
+	_, err := cache.get("357454075177072")
+	require.NoError(t, err)
+
+	// revoked in the store, the stale entry is used once more while the revocation is picked up
+	registry.set(func() { delete(registry.known, "357454075177072") })
+	*now = now.Add(2 * time.Minute)
+	device, err := cache.get("357454075177072")
+	require.NoError(t, err)
+	assert.True(t, device.known)
+
+	assert.Eventually(t, func() bool {
+		device, _ := cache.get("357454075177072")
+		return !device.known
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestVerifyCacheInvalidate(t *testing.T) {
+	cache, registry, _ := newTestVerifyCache(VerifyCacheConfig{TTL: time.Hour, NegativeTTL: time.Hour})
+	_, _ = cache.get("357454075177072")
+	_, _ = cache.get("000000000000000")
+
+	// provisioned in the store
+	registry.set(func() { registry.known["000000000000000"] = types.DeviceType_TELTONIKA })
+	assert.Equal(t, 1, cache.invalidate([]string{"000000000000000", "111111111111111"}))
+	device, err := cache.get("000000000000000")
+	require.NoError(t, err)
+	assert.True(t, device.known)
+
+	assert.Equal(t, 2, cache.invalidate(nil))
+	_, _ = cache.get("357454075177072")
+	assert.Equal(t, 2, registry.lookupCount("357454075177072"))
+}
+
+func TestVerifyCacheIgnoresLookupsStartedBeforeInvalidation(t *testing.T) {
+	cache, _, _ := newTestVerifyCache(VerifyCacheConfig{TTL: time.Hour})
+	generation := cache.generation
+	cache.invalidate([]string{"357454075177072"})
+
+	cache.put("357454075177072", verifiedDevice{known: true}, generation)
+	assert.Empty(t, cache.entries)
+}
+
+func TestVerifyCacheSweepsExpiredEntries(t *testing.T) {
+	cache, _, now := newTestVerifyCache(VerifyCacheConfig{TTL: time.Minute, NegativeTTL: 10 * time.Second, StaleTTL: time.Hour})
+	_, _ = cache.get("357454075177072")
+	_, _ = cache.get("000000000000000")
+
+	// the unknown imei is dropped, the known one can still be used while it's verified again
+	*now = now.Add(2 * time.Minute)
+	_, _ = cache.get("000000000000001")
+	cache.mu.Lock()
+	assert.Contains(t, cache.entries, "357454075177072")
+	assert.NotContains(t, cache.entries, "000000000000000")
+	cache.mu.Unlock()
+
+	*now = now.Add(2 * time.Hour)
+	_, _ = cache.get("000000000000002")
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	assert.Len(t, cache.entries, 1)
+	assert.Contains(t, cache.entries, "000000000000002")
+}
@@ -2,13 +2,14 @@ package handlers
 
 import (
 	"context"
-	"errors"
+
+	"github.com/404minds/avl-receiver/internal/feed"
 	devices "github.com/404minds/avl-receiver/internal/protocols"
 	"github.com/404minds/avl-receiver/internal/protocols/howen"
 	"github.com/404minds/avl-receiver/internal/store"
 	"github.com/404minds/avl-receiver/internal/types"
 	"github.com/gorilla/websocket"
-	"os"
+	"go.uber.org/zap"
 )
 
 type WebSocketHandler struct {
@@ -16,7 +17,9 @@ type WebSocketHandler struct {
 	allowedProtocols  []types.DeviceProtocolType
 	remoteStoreClient store.CustomAvlDataStoreClient
 	storeType         string
+	sinks             Sinks
 	connToStoreMap    map[string]store.Store
+	positions         *feed.Hub
 }
 
 // HandleMessage processes the incoming message and parses it based on action type
@@ -24,9 +27,34 @@ func (w *WebSocketHandler) HandleMessage(conn *websocket.Conn) {
 	logger.Sugar().Info("creating data store")
 
 	deviceProtocol := &howen.HOWENWS{DeviceType: types.DeviceType_HOWEN}
-	dataStore := w.makeAsyncStore(deviceProtocol)
+	asyncStore, err := w.makeAsyncStore(deviceProtocol)
+	if err != nil {
+		logger.Error("failed to create the websocket's store", zap.Error(err))
+		if conn != nil {
+			conn.Close()
+		}
+		return
+	}
+	stats := newConnectionStats()
+	dataStore := store.Store(&store.TapStore{
+		Store:       asyncStore,
+		ProcessChan: make(chan *types.DeviceStatus, 200),
+		CloseChan:   make(chan bool, 1),
+		OnStatus: func(deviceStatus *types.DeviceStatus) {
+			stats.recordStatus(deviceStatus)
+			w.positions.Publish(deviceStatus)
+		},
+	})
 
-	ctx, _ := context.WithCancel(context.Background())
+	// the websocket carries all of the server's devices, its sessions have no imei
+	var remoteAddr string
+	if conn != nil {
+		remoteAddr = conn.RemoteAddr().String()
+	}
+	reportSession(w.remoteStoreClient, connectedEvent("", remoteAddr, deviceProtocol, stats))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
 	logger.Sugar().Info("starting data store process")
 	go dataStore.Process(ctx)
 
@@ -37,7 +65,8 @@ func (w *WebSocketHandler) HandleMessage(conn *websocket.Conn) {
 		}
 	}()
 
-	err := deviceProtocol.ConsumeConnection(conn, dataStore)
+	err = deviceProtocol.ConsumeConnection(conn, dataStore)
+	reportSession(w.remoteStoreClient, disconnectedEvent("", remoteAddr, deviceProtocol, stats, err, false))
 	if err != nil {
 		if websocket.IsUnexpectedCloseError(err) {
 			logger.Sugar().Error("WebSocket connection closed unexpectedly:", err)
@@ -47,15 +76,11 @@ func (w *WebSocketHandler) HandleMessage(conn *websocket.Conn) {
 	}
 }
 
-func (w *WebSocketHandler) makeAsyncStore(deviceProtocol devices.DeviceProtocol) store.Store {
-	if w.storeType == "local" {
-		if err := os.Mkdir("./logs", os.ModeDir); err == nil || errors.Is(err, os.ErrExist) {
-			return makeJsonStore("./logs", deviceProtocol.GetDeviceID())
-		}
-	} else if w.storeType == "remote" {
-		return makeRemoteRpcStore(w.remoteStoreClient)
-	} else {
-		panic("Invalid store type")
-	}
-	return nil
+// UsePositionFeed publishes the decoded statuses to a hub shared with the tcp handler
+func (w *WebSocketHandler) UsePositionFeed(hub *feed.Hub) {
+	w.positions = hub
+}
+
+func (w *WebSocketHandler) makeAsyncStore(deviceProtocol devices.DeviceProtocol) (store.Store, error) {
+	return makeAsyncStore(w.storeType, deviceProtocol, w.remoteStoreClient, w.sinks)
 }
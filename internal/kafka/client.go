@@ -0,0 +1,143 @@
+// Package kafka configures the franz-go client the kafka store produces with
+package kafka
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"time"
+
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sasl"
+	"github.com/twmb/franz-go/pkg/sasl/plain"
+	"github.com/twmb/franz-go/pkg/sasl/scram"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var logger = configuredLogger.Logger
+
+const (
+	SaslPlain       = "plain"
+	SaslScramSha256 = "scram-sha-256"
+	SaslScramSha512 = "scram-sha-512"
+)
+
+type ProducerConfig struct {
+	Brokers       []string // bootstrap brokers, host:port
+	ClientID      string
+	BatchMaxBytes int32         // per partition, franz-go's default (1MB) if 0
+	BatchTimeout  time.Duration // how long records wait for their batch to fill up
+	Compression   string        // none, gzip, snappy, lz4 or zstd
+	Idempotent    bool          // dedupe retried batches on the broker
+	MaxBuffered   int           // records waiting to be produced before Produce blocks, 10000 if 0
+	TLS           *tls.Config
+	SaslMechanism string // SaslPlain, SaslScramSha256, SaslScramSha512 or empty for none
+	Username      string
+	Password      string
+}
+
+func compressionCodec(name string) (kgo.CompressionCodec, error) {
+	switch name {
+	case "none", "":
+		return kgo.NoCompression(), nil
+	case "gzip":
+		return kgo.GzipCompression(), nil
+	case "snappy":
+		return kgo.SnappyCompression(), nil
+	case "lz4":
+		return kgo.Lz4Compression(), nil
+	case "zstd":
+		return kgo.ZstdCompression(), nil
+	default:
+		return kgo.CompressionCodec{}, fmt.Errorf("invalid kafka compression %s", name)
+	}
+}
+
+func saslMechanism(config ProducerConfig) (sasl.Mechanism, error) {
+	switch config.SaslMechanism {
+	case SaslPlain:
+		return plain.Auth{User: config.Username, Pass: config.Password}.AsMechanism(), nil
+	case SaslScramSha256:
+		return scram.Auth{User: config.Username, Pass: config.Password}.AsSha256Mechanism(), nil
+	case SaslScramSha512:
+		return scram.Auth{User: config.Username, Pass: config.Password}.AsSha512Mechanism(), nil
+	default:
+		return nil, fmt.Errorf("invalid kafka sasl mechanism %s", config.SaslMechanism)
+	}
+}
+
+// NewClient builds the producer. Records with the same key (imei) go to the same partition with
+// the java client's murmur2 hash, so they keep their order and land where other producers put
+// them. It doesn't connect until the first record or ping.
+func NewClient(config ProducerConfig) (*kgo.Client, error) {
+	if len(config.Brokers) == 0 {
+		return nil, errors.New("at least one kafka broker is required")
+	}
+	if config.ClientID == "" {
+		config.ClientID = "avl-receiver"
+	}
+	if config.MaxBuffered <= 0 {
+		config.MaxBuffered = 10000
+	}
+	codec, err := compressionCodec(config.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []kgo.Opt{
+		kgo.SeedBrokers(config.Brokers...),
+		kgo.ClientID(config.ClientID),
+		kgo.RecordPartitioner(kgo.StickyKeyPartitioner(nil)),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.ProducerBatchCompression(codec),
+		kgo.MaxBufferedRecords(config.MaxBuffered),
+		kgo.WithLogger(kgoLogger{}),
+	}
+	if config.BatchTimeout > 0 {
+		opts = append(opts, kgo.ProducerLinger(config.BatchTimeout))
+	}
+	if config.BatchMaxBytes > 0 {
+		opts = append(opts, kgo.ProducerBatchMaxBytes(config.BatchMaxBytes))
+	}
+	if !config.Idempotent {
+		opts = append(opts, kgo.DisableIdempotentWrite())
+	}
+	if config.TLS != nil {
+		opts = append(opts, kgo.DialTLSConfig(config.TLS))
+	}
+	if config.SaslMechanism != "" {
+		mechanism, err := saslMechanism(config)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, kgo.SASL(mechanism))
+	}
+	return kgo.NewClient(opts...)
+}
+
+// kgoLogger hands franz-go's warnings and errors to zap, its info is per request
+type kgoLogger struct{}
+
+func (kgoLogger) Level() kgo.LogLevel {
+	if !logger.Core().Enabled(zapcore.WarnLevel) {
+		return kgo.LogLevelError
+	}
+	return kgo.LogLevelWarn
+}
+
+func (kgoLogger) Log(level kgo.LogLevel, msg string, keyvals ...any) {
+	fields := make([]zap.Field, 0, len(keyvals)/2)
+	for i := 0; i+1 < len(keyvals); i += 2 {
+		fields = append(fields, zap.Any(fmt.Sprint(keyvals[i]), keyvals[i+1]))
+	}
+	switch level {
+	case kgo.LogLevelError:
+		logger.Error("kafka: "+msg, fields...)
+	case kgo.LogLevelWarn:
+		logger.Warn("kafka: "+msg, fields...)
+	default:
+		logger.Debug("kafka: "+msg, fields...)
+	}
+}
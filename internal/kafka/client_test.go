@@ -0,0 +1,91 @@
+package kafka
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// selfSignedCert is a certificate for 127.0.0.1 and the pool that trusts it
+func selfSignedCert(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "kfake"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	leaf, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, pool
+}
+
+// produceOne produces a record through a client built from the config, returning the delivery error.
+// A client that can't get in keeps retrying until the timeout.
+func produceOne(t *testing.T, config ProducerConfig, timeout time.Duration) error {
+	client, err := NewClient(config)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return client.ProduceSync(ctx, &kgo.Record{Topic: "positions", Key: []byte("350424063817363"), Value: []byte("{}")}).FirstErr()
+}
+
+func TestClientSasl(t *testing.T) {
+	cases := []struct {
+		mechanism string
+		method    string
+	}{
+		{SaslPlain, "PLAIN"},
+		{SaslScramSha256, "SCRAM-SHA-256"},
+		{SaslScramSha512, "SCRAM-SHA-512"},
+	}
+	for _, c := range cases {
+		t.Run(c.mechanism, func(t *testing.T) {
+			cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, "positions"),
+				kfake.EnableSASL(), kfake.Superuser(c.method, "avl", "secret"))
+			require.NoError(t, err)
+			defer cluster.Close()
+
+			config := ProducerConfig{Brokers: cluster.ListenAddrs(), SaslMechanism: c.mechanism, Username: "avl", Password: "secret"}
+			assert.NoError(t, produceOne(t, config, 5*time.Second))
+
+			config.Password = "wrong"
+			assert.Error(t, produceOne(t, config, 500*time.Millisecond), "a wrong password is turned away")
+		})
+	}
+}
+
+func TestClientTls(t *testing.T) {
+	cert, pool := selfSignedCert(t)
+	cluster, err := kfake.NewCluster(kfake.NumBrokers(1), kfake.SeedTopics(1, "positions"),
+		kfake.TLS(&tls.Config{Certificates: []tls.Certificate{cert}}))
+	require.NoError(t, err)
+	defer cluster.Close()
+
+	assert.NoError(t, produceOne(t, ProducerConfig{Brokers: cluster.ListenAddrs(), TLS: &tls.Config{RootCAs: pool}}, 5*time.Second))
+	assert.Error(t, produceOne(t, ProducerConfig{Brokers: cluster.ListenAddrs(), TLS: &tls.Config{}}, 500*time.Millisecond), "the broker isn't trusted without its ca")
+}
@@ -1,16 +1,246 @@
 package logger
 
 import (
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Config is the logging setup picked by the receiver's flags
+type Config struct {
+	Level    string // debug, info, warn or error
+	Encoding string // json or console
+	// of the entries with the same level and message in a second, the first SampleInitial are
+	// logged and then every SampleThereafter-th one. 0 disables sampling.
+	SampleInitial    int
+	SampleThereafter int
+}
+
+// output is where entries end up, swapped as a whole by Configure
+type output struct {
+	core    zapcore.Core // every entry given to it, used for traced devices
+	sampled zapcore.Core
+}
+
+var (
+	level      = zap.NewAtomicLevelAt(zap.DebugLevel)
+	stackLevel = zap.NewAtomicLevelAt(zap.WarnLevel)
+	current    atomic.Pointer[output]
+	traced     = &deviceSet{devices: make(map[string]time.Time)}
 )
 
-// Logger is a wrapper around zap.Logger
-// we can configure it as we want
-func zapLogger() *zap.Logger {
-	logger, _ := zap.NewDevelopment(
-	//zap.AddStacktrace(zap.PanicLevel)
-	)
-	return logger
+func init() {
+	// the development setup until Configure is called, e.g. in tests
+	encoderConfig := zap.NewDevelopmentEncoderConfig()
+	core := zapcore.NewCore(zapcore.NewConsoleEncoder(encoderConfig), zapcore.Lock(os.Stderr), zap.DebugLevel)
+	current.Store(&output{core: core, sampled: core})
+}
+
+// Logger is shared by every package, Configure changes its output after the packages took it
+var Logger = zap.New(&switchCore{}, zap.AddCaller(), zap.AddStacktrace(stackLevel), zap.ErrorOutput(zapcore.Lock(os.Stderr)))
+
+func Configure(config Config) error {
+	lvl, err := zapcore.ParseLevel(config.Level)
+	if err != nil {
+		return err
+	}
+
+	var encoder zapcore.Encoder
+	switch config.Encoding {
+	case "json":
+		encoder = zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	case "console":
+		encoder = zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	default:
+		return fmt.Errorf("unknown log encoding %q, expected json or console", config.Encoding)
+	}
+
+	// the level is checked by switchCore so traced devices can log below it
+	core := zapcore.NewCore(encoder, zapcore.Lock(os.Stderr), zap.DebugLevel)
+	out := &output{core: core, sampled: core}
+	if config.SampleInitial > 0 {
+		out.sampled = zapcore.NewSamplerWithOptions(core, time.Second, config.SampleInitial, config.SampleThereafter)
+	}
+	current.Store(out)
+	level.SetLevel(lvl)
+	stackLevel.SetLevel(zap.ErrorLevel)
+	return nil
+}
+
+func SetLevel(l zapcore.Level) {
+	level.SetLevel(l)
 }
 
-var Logger = zapLogger()
+func Level() zapcore.Level {
+	return level.Level()
+}
+
+// TraceDevice logs everything about the device, debug entries included, until the ttl is up.
+// Entries are matched by their imei or deviceID field.
+func TraceDevice(imei string, ttl time.Duration) {
+	traced.add(imei, time.Now().Add(ttl))
+}
+
+func UntraceDevice(imei string) {
+	traced.remove(imei)
+}
+
+// TracedDevices returns the traced imeis with the time their tracing ends
+func TracedDevices() map[string]time.Time {
+	return traced.list()
+}
+
+// DeviceEnabled tells if an entry at the level about the device would be logged, to skip
+// building expensive fields such as hex dumps
+func DeviceEnabled(imei string, l zapcore.Level) bool {
+	return level.Enabled(l) || traced.has(imei)
+}
+
+// fields the device of an entry is identified by
+var deviceFieldKeys = map[string]bool{"imei": true, "deviceID": true, "deviceId": true, "IMEI": true}
+
+func deviceOf(fields []zapcore.Field) string {
+	for _, f := range fields {
+		if f.Type == zapcore.StringType && deviceFieldKeys[f.Key] {
+			return f.String
+		}
+	}
+	return ""
+}
+
+type deviceSet struct {
+	count   atomic.Int32 // checked on every entry, the map only when devices are traced
+	mu      sync.Mutex
+	devices map[string]time.Time
+}
+
+func (s *deviceSet) add(imei string, until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.devices[imei] = until
+	s.count.Store(int32(len(s.devices)))
+}
+
+func (s *deviceSet) remove(imei string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.devices, imei)
+	s.count.Store(int32(len(s.devices)))
+}
+
+func (s *deviceSet) any() bool {
+	return s.count.Load() > 0
+}
+
+func (s *deviceSet) has(imei string) bool {
+	if imei == "" || !s.any() {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	until, ok := s.devices[imei]
+	if ok && time.Now().After(until) {
+		delete(s.devices, imei)
+		s.count.Store(int32(len(s.devices)))
+		return false
+	}
+	return ok
+}
+
+func (s *deviceSet) list() map[string]time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	res := make(map[string]time.Time, len(s.devices))
+	for imei, until := range s.devices {
+		if now.After(until) {
+			delete(s.devices, imei)
+			continue
+		}
+		res[imei] = until
+	}
+	s.count.Store(int32(len(s.devices)))
+	return res
+}
+
+// switchCore writes to the current output, below the level only for traced devices
+type switchCore struct {
+	fields []zapcore.Field
+	device string // from the fields added with With
+
+	bound atomic.Pointer[boundOutput]
+}
+
+// boundOutput is the output with the core's fields added, rebuilt when the output is swapped
+type boundOutput struct {
+	out     *output
+	core    zapcore.Core
+	sampled zapcore.Core
+}
+
+func (c *switchCore) output() *boundOutput {
+	out := current.Load()
+	if b := c.bound.Load(); b != nil && b.out == out {
+		return b
+	}
+	b := &boundOutput{out: out, core: out.core.With(c.fields), sampled: out.sampled.With(c.fields)}
+	c.bound.Store(b)
+	return b
+}
+
+func (c *switchCore) Enabled(l zapcore.Level) bool {
+	return level.Enabled(l) || traced.any()
+}
+
+func (c *switchCore) With(fields []zapcore.Field) zapcore.Core {
+	child := &switchCore{
+		fields: append(append([]zapcore.Field{}, c.fields...), fields...),
+		device: c.device,
+	}
+	if child.device == "" {
+		child.device = deviceOf(fields)
+	}
+	return child
+}
+
+func (c *switchCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !traced.any() {
+		if level.Enabled(entry.Level) {
+			return c.output().sampled.Check(entry, ce)
+		}
+		return ce
+	}
+	if c.device != "" {
+		if traced.has(c.device) {
+			return c.output().core.Check(entry, ce)
+		} else if level.Enabled(entry.Level) {
+			return c.output().sampled.Check(entry, ce)
+		}
+		return ce
+	}
+	// the device is only known from the entry's fields, decided in Write
+	return ce.AddCore(entry, c)
+}
+
+func (c *switchCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	out := c.output()
+	if traced.has(deviceOf(fields)) {
+		return out.core.Write(entry, fields)
+	}
+	if !level.Enabled(entry.Level) {
+		return nil
+	}
+	if ce := out.sampled.Check(entry, nil); ce != nil {
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+func (c *switchCore) Sync() error {
+	return current.Load().core.Sync()
+}
@@ -0,0 +1,101 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// observe swaps the output for an observer and restores the default setup afterwards
+func observe(t *testing.T, lvl zapcore.Level, sampled bool) *observer.ObservedLogs {
+	core, logs := observer.New(zap.DebugLevel)
+	out := &output{core: core, sampled: core}
+	if sampled {
+		out.sampled = zapcore.NewSamplerWithOptions(core, time.Minute, 1, 0)
+	}
+	previous := current.Swap(out)
+	previousLevel := level.Level()
+	level.SetLevel(lvl)
+	t.Cleanup(func() {
+		current.Store(previous)
+		level.SetLevel(previousLevel)
+		for imei := range TracedDevices() {
+			UntraceDevice(imei)
+		}
+	})
+	return logs
+}
+
+func TestLevelAppliesToEveryLogger(t *testing.T) {
+	logs := observe(t, zap.InfoLevel, false)
+	child := Logger.With(zap.String("component", "test"))
+
+	Logger.Debug("hidden")
+	child.Info("shown")
+	SetLevel(zap.DebugLevel)
+	Logger.Sugar().Debugf("shown too %d", 1)
+
+	assert.Equal(t, []string{"shown", "shown too 1"}, messages(logs))
+	assert.Equal(t, "test", logs.All()[0].ContextMap()["component"])
+}
+
+func TestTracedDeviceLogsBelowLevel(t *testing.T) {
+	logs := observe(t, zap.InfoLevel, false)
+	TraceDevice("350424063817426", time.Minute)
+
+	Logger.Debug("traced field", zap.String("imei", "350424063817426"))
+	Logger.Debug("other device", zap.String("imei", "861234567890123"))
+	Logger.With(zap.String("deviceID", "350424063817426")).Debug("traced context")
+	Logger.With(zap.String("deviceID", "861234567890123")).Debug("other context")
+	Logger.Debug("no device")
+	Logger.Info("info", zap.String("imei", "861234567890123"))
+
+	assert.Equal(t, []string{"traced field", "traced context", "info"}, messages(logs))
+	assert.True(t, DeviceEnabled("350424063817426", zap.DebugLevel))
+	assert.False(t, DeviceEnabled("861234567890123", zap.DebugLevel))
+
+	UntraceDevice("350424063817426")
+	Logger.Debug("untraced", zap.String("imei", "350424063817426"))
+	assert.Len(t, logs.All(), 3)
+}
+
+func TestTracingExpires(t *testing.T) {
+	logs := observe(t, zap.InfoLevel, false)
+	TraceDevice("350424063817426", -time.Second)
+
+	Logger.Debug("expired", zap.String("imei", "350424063817426"))
+	assert.Empty(t, logs.All())
+	assert.Empty(t, TracedDevices())
+}
+
+func TestSamplingSparesTracedDevices(t *testing.T) {
+	logs := observe(t, zap.InfoLevel, true)
+	for i := 0; i < 5; i++ {
+		Logger.Info("packet")
+	}
+	assert.Len(t, logs.All(), 1, "sampled after the first")
+
+	TraceDevice("350424063817426", time.Minute)
+	for i := 0; i < 5; i++ {
+		Logger.Info("packet", zap.String("imei", "350424063817426"))
+		Logger.With(zap.String("imei", "350424063817426")).Info("packet")
+	}
+	assert.Len(t, logs.All(), 11)
+}
+
+func TestConfigureRejectsUnknownSettings(t *testing.T) {
+	assert.Error(t, Configure(Config{Level: "loud", Encoding: "json"}))
+	assert.Error(t, Configure(Config{Level: "info", Encoding: "xml"}))
+}
+
+func messages(logs *observer.ObservedLogs) []string {
+	var res []string
+	for _, entry := range logs.All() {
+		res = append(res, entry.Message)
+	}
+	return res
+}
@@ -0,0 +1,63 @@
+package protocols
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/404minds/avl-receiver/internal/decode"
+	"github.com/404minds/avl-receiver/internal/types"
+)
+
+// FrameDecoder is a protocol that can decode frames outside of a connection
+type FrameDecoder interface {
+	// DecodeFrame decodes the frame at the start of data, decode.ErrIncomplete if it's cut short.
+	// What a frame tells about the device, like the imei of a login, is kept for the next ones.
+	DecodeFrame(data []byte) (*decode.Frame, error)
+}
+
+// Detect guesses the protocol of a dump from its first bytes, the way the tcp handler would log the
+// device in
+func Detect(data []byte) (types.DeviceProtocolType, bool) {
+	switch {
+	case len(data) >= 2 && data[0] == 0x00 && data[1] == 0x0F:
+		return types.DeviceProtocolType_FM1200, true // imei login
+	case len(data) >= 9 && bytes.Equal(data[:4], []byte{0, 0, 0, 0}) && (data[8] == 0x08 || data[8] == 0x8E || data[8] == 0x0C):
+		return types.DeviceProtocolType_FM1200, true // avl data or a codec 12 response
+	case len(data) >= 4 && data[0] == 0x78 && data[1] == 0x78 && data[3] == 0x12:
+		return types.DeviceProtocolType_TR06, true // only the tr06 package has 0x12 positions
+	case len(data) >= 2 && (data[0] == 0x78 && data[1] == 0x78 || data[0] == 0x79 && data[1] == 0x79):
+		return types.DeviceProtocolType_GT06, true
+	case len(data) >= 2 && data[0] == '$' && data[1] == '$':
+		return types.DeviceProtocolType_OBDII2G, true
+	case len(data) >= 2 && data[0] == 0xFA && data[1] == 0xF8, len(data) >= 4 && data[2] == 0x00 && data[3] == 0x02:
+		return types.DeviceProtocolType_INTELLITRAC_A, true
+	}
+	return 0, false
+}
+
+// DecodeFrames decodes the frames in data with a fresh protocol, until it runs out or hits one it
+// can't decode. rest is what's left and err why, decode.ErrIncomplete if it's a frame cut short.
+func DecodeFrames(t types.DeviceProtocolType, data []byte) (frames []*decode.Frame, rest []byte, err error) {
+	decoder, ok := MakeProtocolForType(t).(FrameDecoder)
+	if !ok {
+		return nil, data, fmt.Errorf("%s frames can't be decoded offline", t)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	for rest = data; len(rest) > 0; {
+		frame, err := decoder.DecodeFrame(rest)
+		if err != nil {
+			return frames, rest, err
+		}
+		if frame.Size <= 0 || frame.Size > len(rest) {
+			return frames, rest, fmt.Errorf("%s decoded a frame of %d bytes out of %d", t, frame.Size, len(rest))
+		}
+		frames = append(frames, frame)
+		rest = rest[frame.Size:]
+	}
+	return frames, rest, nil
+}
@@ -0,0 +1,95 @@
+package protocols
+
+import (
+	"testing"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/decode"
+	"github.com/404minds/avl-receiver/internal/simulator"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var simulatorProtocols = map[string]types.DeviceProtocolType{
+	"codec8":            types.DeviceProtocolType_FM1200,
+	"codec8e":           types.DeviceProtocolType_FM1200,
+	"gt06":              types.DeviceProtocolType_GT06,
+	"tr06":              types.DeviceProtocolType_TR06,
+	"aquila":            types.DeviceProtocolType_OBDII2G,
+	"intellitrac":       types.DeviceProtocolType_INTELLITRAC_A,
+	"intellitrac-ascii": types.DeviceProtocolType_INTELLITRAC_A,
+}
+
+// TestDecodeFrames decodes what the simulated devices send, frame by frame
+func TestDecodeFrames(t *testing.T) {
+	start := time.Now().UTC().Truncate(time.Second)
+	for _, name := range simulator.Protocols() {
+		t.Run(name, func(t *testing.T) {
+			protocolType, ok := simulatorProtocols[name]
+			require.True(t, ok)
+			codec, err := simulator.CodecFor(name, "861234567890123")
+			require.NoError(t, err)
+			track := simulator.DefaultRoute.Track(100)
+			fix := track.Next(start.Add(10*time.Second), 10*time.Second)
+
+			login := codec.Login(track.Fix(start)).Data
+			positions := codec.Positions([]simulator.Fix{fix}).Data
+			if name != "tr06" {
+				// a tr06 login can't be told from a gt06 one, its positions can
+				detected, ok := Detect(login)
+				assert.True(t, ok)
+				assert.Equal(t, protocolType, detected)
+			}
+			if name != "intellitrac-ascii" {
+				// ascii lines only make sense after the login
+				detected, ok := Detect(positions)
+				assert.True(t, ok)
+				assert.Equal(t, protocolType, detected)
+			}
+
+			stream := append(append(append([]byte{}, login...), positions...), codec.Heartbeat(fix).Data...)
+			frames, rest, err := DecodeFrames(protocolType, stream)
+			require.NoError(t, err)
+			assert.Empty(t, rest)
+			require.NotEmpty(t, frames)
+
+			statuses := 0
+			for _, frame := range frames {
+				if frame.Checksum != nil {
+					assert.True(t, frame.Checksum.Valid(), "%s %s", frame.Kind, frame.Checksum.Name)
+				}
+				for _, status := range frame.Statuses {
+					assert.Equal(t, codec.Imei(), status.Imei)
+					statuses++
+				}
+			}
+			assert.NotZero(t, statuses)
+
+			// a frame cut short is left over
+			frames, rest, err = DecodeFrames(protocolType, stream[:len(login)+len(positions)-1])
+			assert.ErrorIs(t, err, decode.ErrIncomplete)
+			assert.Len(t, rest, len(positions)-1)
+			assert.Len(t, frames, 1)
+		})
+	}
+}
+
+func TestDecodeFramesBadChecksum(t *testing.T) {
+	codec, err := simulator.CodecFor("gt06", "861234567890123")
+	require.NoError(t, err)
+	data := codec.Heartbeat(simulator.DefaultRoute.Track(100).Fix(time.Now())).Data
+	data[len(data)-3] ^= 0xFF
+
+	frames, rest, err := DecodeFrames(types.DeviceProtocolType_GT06, data)
+	require.NoError(t, err)
+	assert.Empty(t, rest)
+	require.Len(t, frames, 1)
+	assert.False(t, frames[0].Checksum.Valid())
+}
+
+func TestDecodeFramesUnsupported(t *testing.T) {
+	_, rest, err := DecodeFrames(types.DeviceProtocolType_HOWENWS, []byte("{}"))
+	assert.Error(t, err)
+	assert.Equal(t, []byte("{}"), rest)
+}
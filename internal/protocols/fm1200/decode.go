@@ -0,0 +1,90 @@
+package fm1200
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/404minds/avl-receiver/internal/crc"
+	"github.com/404minds/avl-receiver/internal/decode"
+	errs "github.com/404minds/avl-receiver/internal/errors"
+	"github.com/404minds/avl-receiver/internal/types"
+)
+
+// LoginPacket is the imei a device opens the connection with
+type LoginPacket struct {
+	Imei string `json:"imei"`
+}
+
+// DecodeFrame decodes the imei login or avl data packet at the start of data. The imei of a
+// login is kept for the statuses of the packets after it.
+func (t *FM1200Protocol) DecodeFrame(data []byte) (*decode.Frame, error) {
+	if len(data) < 2 {
+		return nil, decode.ErrIncomplete
+	}
+	if imeiLen := binary.BigEndian.Uint16(data); imeiLen == 15 {
+		if len(data) < 2+int(imeiLen) {
+			return nil, decode.ErrIncomplete
+		}
+		t.Imei = string(data[2 : 2+imeiLen])
+		return &decode.Frame{Kind: "login", Packet: &LoginPacket{Imei: t.Imei}, Size: 2 + int(imeiLen)}, nil
+	}
+
+	if len(data) < 8 {
+		return nil, decode.ErrIncomplete
+	}
+	if binary.BigEndian.Uint32(data) != 0 {
+		return nil, fmt.Errorf("%w: no preamble", errs.ErrFM1200BadDataPacket)
+	}
+	dataLen := uint64(binary.BigEndian.Uint32(data[4:]))
+	if dataLen > maxDataLength {
+		return nil, fmt.Errorf("%w: fm1200 data length %d", errs.ErrFrameTooLarge, dataLen)
+	}
+	if dataLen == 0 {
+		return nil, fmt.Errorf("%w: data length %d", errs.ErrFM1200BadDataPacket, dataLen)
+	}
+	if uint64(len(data)) < 12+dataLen {
+		return nil, decode.ErrIncomplete
+	}
+	dataBytes := data[8 : 8+dataLen]
+	frame := &decode.Frame{
+		Size: 12 + int(dataLen),
+		Checksum: &decode.Checksum{
+			Name:       "crc16",
+			Sent:       binary.BigEndian.Uint32(data[8+dataLen:]),
+			Calculated: uint32(crc.CrcTeltonika(dataBytes)),
+		},
+	}
+
+	codecID := dataBytes[0]
+	reader := bufio.NewReader(bytes.NewReader(dataBytes[1:]))
+	switch CodecID(codecID) {
+	case Codec8, Codec8E:
+		packet, err, _ := t.parseDataToRecord(reader, codecID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errs.ErrFM1200BadDataPacket, err)
+		}
+		packet.CodecID = codecID
+		packet.CRC = frame.Checksum.Sent
+		frame.Kind = fmt.Sprintf("codec %X avl data", codecID)
+		frame.Packet = packet
+		for _, record := range packet.Data {
+			r := Record{Record: record, IMEI: t.Imei}
+			frame.Statuses = append(frame.Statuses, r.ToProtobufDeviceStatus())
+		}
+	case codec12:
+		response, err := t.ParseDeviceResponse(reader, uint32(dataLen))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errs.ErrFM1200BadDataPacket, err)
+		}
+		response.CRC = frame.Checksum.Sent
+		frame.Kind = "codec 12 response"
+		frame.Packet = response
+		r := Response{Reply: response.ResponseData, IMEI: t.Imei}
+		frame.Responses = []*types.DeviceResponse{r.ToProtobufDeviceResponse()}
+	default:
+		return nil, fmt.Errorf("%w: unsupported codec %#02x", errs.ErrFM1200BadDataPacket, codecID)
+	}
+	return frame, nil
+}
@@ -8,7 +8,6 @@ import (
 	"io"
 	"log"
 	"net"
-	"os"
 	"sort"
 	"time"
 
@@ -27,6 +26,19 @@ var logger = configuredLogger.Logger
 type FM1200Protocol struct {
 	Imei       string
 	DeviceType types.DeviceType
+
+	deviceLogger *zap.Logger
+}
+
+func (t *FM1200Protocol) SetLogger(l *zap.Logger) {
+	t.deviceLogger = l
+}
+
+func (t *FM1200Protocol) log() *zap.Logger {
+	if t.deviceLogger != nil {
+		return t.deviceLogger
+	}
+	return logger
 }
 
 func (t *FM1200Protocol) GetDeviceID() string {
@@ -66,10 +78,10 @@ func (t *FM1200Protocol) ConsumeStream(reader *bufio.Reader, responseWriter io.W
 		//peeked, err := reader.Peek(1) // Peek a single byte to load the buffer
 		//if err != nil {
 		//	if err == io.EOF {
-		//		logger.Info("End of stream reached.")
+		//		t.log().Info("End of stream reached.")
 		//		return nil // Gracefully handle EOF
 		//	}
-		//	logger.Error("Error peeking from reader", zap.Error(err))
+		//	t.log().Error("Error peeking from reader", zap.Error(err))
 		//	return err
 		//}
 		//
@@ -78,68 +90,30 @@ func (t *FM1200Protocol) ConsumeStream(reader *bufio.Reader, responseWriter io.W
 		//if buffered > 0 {
 		//	// Peek all buffered bytes
 		//	peeked, _ = reader.Peek(buffered)
-		//	logger.Sugar().Info("buffered length: ", buffered)
-		//	logger.Sugar().Info("raw bytes: ", peeked)
+		//	t.log().Sugar().Debug("buffered length: ", buffered)
+		//	t.log().Sugar().Debug("raw bytes: ", peeked)
 		//} else {
-		//	logger.Sugar().Info("No bytes are buffered yet.")
+		//	t.log().Sugar().Debug("No bytes are buffered yet.")
 		//}
 
 		// Process the message
 		//var fuelError bool
 		// Set a read timeout to avoid blocking indefinitely
 		if err := t.setReadTimeout(responseWriter, 30*time.Second); err != nil {
-			logger.Error("Failed to set read timeout", zap.Error(err))
+			t.log().Error("Failed to set read timeout", zap.Error(err))
 			return err
 		}
 
 		err, _ := t.consumeMessage(reader, dataStore, responseWriter)
 		if err != nil {
 			if err == io.EOF {
-				logger.Info("End of stream reached while consuming message.")
+				t.log().Info("End of stream reached while consuming message.")
 				return nil // Gracefully handle EOF
 			}
-			logger.Error("Failed to consume message", zap.Error(err))
+			t.log().Error("Failed to consume message", zap.Error(err))
 			return err
 		}
-
-		//if fuelError {
-		//	saveErr := saveToFile("errorLogs.txt", peeked)
-		//	if saveErr != nil {
-		//		logger.Error("Failed to save peeked data to file", zap.Error(saveErr))
-		//	} else {
-		//		logger.Info("fuel error raw data saved to file successfully.")
-		//	}
-		//}
-	}
-}
-
-func saveToFile(filename string, data []byte) error {
-	// Open the file in append mode, create it if it doesn't exist
-	file, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("unable to open file: %w", err)
-	}
-	defer func(file *os.File) {
-		err := file.Close()
-		if err != nil {
-
-		}
-	}(file)
-
-	// Convert raw bytes to string format for logging
-	byteArrayString := fmt.Sprintf("%v", data) // Formats as [0 0 0 0 ...]
-
-	// Prepare the log entry with timestamp
-	timestamp := time.Now().Format("2006-01-02 15:04:05") // Format: YYYY-MM-DD HH:MM:SS
-	logEntry := fmt.Sprintf("%s raw bytes: %s\n\n\n", timestamp, byteArrayString)
-
-	// Write the formatted log entry to the file
-	_, err = file.WriteString(logEntry)
-	if err != nil {
-		return fmt.Errorf("failed to write data to file: %w", err)
 	}
-
-	return nil
 }
 
 func (t *FM1200Protocol) setReadTimeout(writer io.Writer, timeout time.Duration) error {
@@ -156,7 +130,7 @@ func (t *FM1200Protocol) consumeMessage(reader *bufio.Reader, dataStore store.St
 	if err != nil {
 		var netErr net.Error
 		if errors.As(err, &netErr) && netErr.Timeout() {
-			logger.Sugar().Error("Read timeout", zap.Error(err))
+			t.log().Sugar().Error("Read timeout", zap.Error(err))
 			return errors.New("read timeout"), false
 		}
 		return errors.Wrapf(err, "Failed during binary.Read"), false
@@ -172,7 +146,13 @@ func (t *FM1200Protocol) consumeMessage(reader *bufio.Reader, dataStore store.St
 		return err, false
 	}
 
-	logger.Sugar().Info("consumeMessage Data length: ", dataLen)
+	t.log().Sugar().Debug("consumeMessage Data length: ", dataLen)
+	if dataLen > maxDataLength {
+		return errors.Wrapf(errs.ErrFrameTooLarge, "fm1200 data length %d", dataLen), false
+	}
+	if dataLen == 0 {
+		return errors.Wrapf(errs.ErrFM1200BadDataPacket, "data length %d", dataLen), false
+	}
 
 	dataBytes := make([]byte, dataLen)
 	_, err = io.ReadFull(reader, dataBytes)
@@ -182,7 +162,7 @@ func (t *FM1200Protocol) consumeMessage(reader *bufio.Reader, dataStore store.St
 	if err != nil {
 		return errors.Wrapf(errs.ErrFM1200BadDataPacket, "error at Data length"), false
 	}
-	logger.Sugar().Info("consumeMessage Data Byte: ", dataBytes)
+	t.log().Sugar().Debug("consumeMessage Data Byte: ", dataBytes)
 
 	// Create a reader for the data bytes
 	dataReader := bufio.NewReader(bytes.NewReader(dataBytes))
@@ -192,29 +172,29 @@ func (t *FM1200Protocol) consumeMessage(reader *bufio.Reader, dataStore store.St
 	if err != nil {
 		return err, false
 	}
-	logger.Sugar().Info("Codec ID: ", codecID)
+	t.log().Sugar().Debug("Codec ID: ", codecID)
 
 	// Check if it's a normal AVL Data Packet or a Device Response based on Codec ID
 	if codecID == 0x0C { // Codec12
-		logger.Sugar().Info("Received response from the device")
+		t.log().Sugar().Debug("Received response from the device")
 		// Check if this is a response (Type field == 0x06) or a normal packet
 
-		logger.Sugar().Info("Parsing Device Response")
+		t.log().Sugar().Debug("Parsing Device Response")
 		response, err := t.ParseDeviceResponse(dataReader, dataLen)
 		if err != nil {
 			return err, false
 		}
 
-		logger.Sugar().Infof("Parsed response from device: %+v", response)
+		t.log().Sugar().Debugf("Parsed response from device: %+v", response)
 
 		r := Response{
 			Reply: response.ResponseData, // Assign the entire ResponseData directly
 			IMEI:  t.Imei,
 		}
-		logger.Sugar().Info(r)
+		t.log().Sugar().Debug(r)
 		protoReply := r.ToProtobufDeviceResponse()
 
-		logger.Sugar().Info("proto reply device response", protoReply)
+		t.log().Sugar().Debug("proto reply device response", protoReply)
 		asyncResponseStore := dataStore.GetResponseChan()
 		asyncResponseStore <- protoReply
 
@@ -260,9 +240,8 @@ func (t *FM1200Protocol) consumeMessage(reader *bufio.Reader, dataStore store.St
 		asyncStore := dataStore.GetProcessChan()
 		protoRecord := r.ToProtobufDeviceStatus()
 		asyncStore <- protoRecord
-		time.Sleep(100 * time.Millisecond)
 	}
-	logger.Sugar().Infof("stored %d records", len(parsedPacket.Data))
+	t.log().Sugar().Debugf("stored %d records", len(parsedPacket.Data))
 
 	err = binary.Write(responseWriter, binary.BigEndian, int32(parsedPacket.NumberOfData))
 	if err != nil {
@@ -275,7 +254,7 @@ func (t *FM1200Protocol) parseDataToRecord(reader *bufio.Reader, codecId uint8)
 	var packet AvlDataPacket
 	var err error
 
-	logger.Sugar().Info("parseDataToRecord:  codec: ", codecId)
+	t.log().Sugar().Debug("parseDataToRecord:  codec: ", codecId)
 
 	// number of data
 	packet.NumberOfData, err = reader.ReadByte()
@@ -283,7 +262,7 @@ func (t *FM1200Protocol) parseDataToRecord(reader *bufio.Reader, codecId uint8)
 		return nil, err, false
 	}
 
-	logger.Sugar().Info("parseDataRecord: NumberofData ", packet.NumberOfData)
+	t.log().Sugar().Debug("parseDataRecord: NumberofData ", packet.NumberOfData)
 	var fuelError bool
 	// parse each record
 	for i := uint8(0); i < packet.NumberOfData; i++ { //TODO range == packet.NumberOfData currently just for debugging
@@ -301,7 +280,7 @@ func (t *FM1200Protocol) parseDataToRecord(reader *bufio.Reader, codecId uint8)
 	if err != nil {
 		return nil, err, false
 	}
-	logger.Sugar().Info("parseDataToRecord endNumRecords: ", endNumRecords)
+	t.log().Sugar().Debug("parseDataToRecord endNumRecords: ", endNumRecords)
 	if endNumRecords != packet.NumberOfData {
 		return nil, errors.Wrapf(errs.ErrFM1200BadDataPacket, "error end Num Records != packet.NumberOfData"), false
 	}
@@ -378,17 +357,17 @@ func (t *FM1200Protocol) parseIOElements(reader *bufio.Reader, codecID uint8) (*
 	var err1, err2, err3, err4, err5 error
 	ioElement.Properties1B, err1 = t.read1BProperties(reader, codecID)
 	if err1 != nil {
-		logger.Sugar().Info("parseIOElements: properties1B error: ", err1)
+		t.log().Sugar().Debug("parseIOElements: properties1B error: ", err1)
 	}
 
 	ioElement.Properties2B, err2 = t.read2BProperties(reader, codecID)
 	if err2 != nil {
-		logger.Sugar().Info("parseIOElements: properties2B error: ", err2)
+		t.log().Sugar().Debug("parseIOElements: properties2B error: ", err2)
 	}
 
 	ioElement.Properties4B, err3 = t.read4BProperties(reader, codecID)
 	if err3 != nil {
-		logger.Sugar().Info("parseIOElements: properties4B error: ", err3)
+		t.log().Sugar().Debug("parseIOElements: properties4B error: ", err3)
 	}
 
 	if (ioElement.Properties1B[TIO_DigitalInput1] > 0 || ioElement.Properties1B[TIO_Ignition] > 0) && (ioElement.Properties4B[TIO_FuelLevel] == 127 || ioElement.Properties4B[TIO_FuelLevel] == 0) {
@@ -397,7 +376,7 @@ func (t *FM1200Protocol) parseIOElements(reader *bufio.Reader, codecID uint8) (*
 
 	ioElement.Properties8B, err4 = t.read8BProperties(reader, codecID)
 	if err4 != nil {
-		logger.Sugar().Info("parseIOElements: properties8B error: ", err4)
+		t.log().Sugar().Debug("parseIOElements: properties8B error: ", err4)
 	}
 
 	if codecID == 0x8E {
@@ -490,14 +469,14 @@ func (t *FM1200Protocol) readNByteProperties(n int, reader *bufio.Reader, codecI
 	if codecID == 0x8E {
 		err := binary.Read(reader, binary.BigEndian, &numProperties)
 		if err != nil {
-			logger.Sugar().Info("readNByteProperties: error:  ", err)
+			t.log().Sugar().Debug("readNByteProperties: error:  ", err)
 			return nil, err
 		}
 	} else {
 		var numProperties8 uint8
 		err := binary.Read(reader, binary.BigEndian, &numProperties8)
 		if err != nil {
-			logger.Sugar().Info("readNByteProperties: error:  ", err)
+			t.log().Sugar().Debug("readNByteProperties: error:  ", err)
 			return nil, err
 		}
 		numProperties = uint16(numProperties8)
@@ -509,14 +488,14 @@ func (t *FM1200Protocol) readNByteProperties(n int, reader *bufio.Reader, codecI
 		if codecID == 0x8E {
 			err := binary.Read(reader, binary.BigEndian, &propertyID)
 			if err != nil {
-				logger.Sugar().Info("readNByteProperties: error:  ", err)
+				t.log().Sugar().Debug("readNByteProperties: error:  ", err)
 				return nil, err
 			}
 		} else {
 			var propertyID8 uint8
 			err := binary.Read(reader, binary.BigEndian, &propertyID8)
 			if err != nil {
-				logger.Sugar().Info("readNByteProperties: error:  ", err)
+				t.log().Sugar().Debug("readNByteProperties: error:  ", err)
 				return nil, err
 			}
 			propertyID = uint16(propertyID8)
@@ -527,7 +506,7 @@ func (t *FM1200Protocol) readNByteProperties(n int, reader *bufio.Reader, codecI
 		propBytes := make([]byte, n)
 		err := binary.Read(reader, binary.BigEndian, &propBytes)
 		if err != nil {
-			logger.Sugar().Info("readNByteProperties: error:  ", err)
+			t.log().Sugar().Debug("readNByteProperties: error:  ", err)
 			return nil, err
 		}
 		if n == 1 {
@@ -549,7 +528,7 @@ func (t *FM1200Protocol) readNXByteProperties(reader *bufio.Reader, codecID uint
 	if codecID == 0x8E {
 		err := binary.Read(reader, binary.BigEndian, &numProperties)
 		if err != nil {
-			logger.Sugar().Info("readNXByteProperties: error:  ", err)
+			t.log().Sugar().Debug("readNXByteProperties: error:  ", err)
 			return nil, err
 		}
 	}
@@ -560,7 +539,7 @@ func (t *FM1200Protocol) readNXByteProperties(reader *bufio.Reader, codecID uint
 
 		err := binary.Read(reader, binary.BigEndian, &propertyID)
 		if err != nil {
-			logger.Sugar().Info("readNXByteProperties: error:  ", err)
+			t.log().Sugar().Debug("readNXByteProperties: error:  ", err)
 			return nil, err
 		}
 
@@ -569,14 +548,14 @@ func (t *FM1200Protocol) readNXByteProperties(reader *bufio.Reader, codecID uint
 		var valueLen uint16
 		err = binary.Read(reader, binary.BigEndian, &valueLen)
 		if err != nil {
-			logger.Sugar().Info("readNXByteProperties: error:  ", err)
+			t.log().Sugar().Debug("readNXByteProperties: error:  ", err)
 			return nil, err
 		}
 
 		propBytes := make([]byte, valueLen)
 		_, err = io.ReadFull(reader, propBytes)
 		if err != nil {
-			logger.Sugar().Info("readNXByteProperties: error:  ", err)
+			t.log().Sugar().Debug("readNXByteProperties: error:  ", err)
 			return nil, err
 		}
 
@@ -655,7 +634,7 @@ func (t *FM1200Protocol) peekImei(reader *bufio.Reader) (imei string, bytesConsu
 }
 
 func (t *FM1200Protocol) isImeiAuthorized(imei string) bool {
-	logger.Sugar().Infof("IMEI %s is authorized", imei)
+	t.log().Sugar().Debugf("IMEI %s is authorized", imei)
 	return true
 }
 
@@ -708,7 +687,7 @@ func (t *FM1200Protocol) SendCommandToDevice(writer io.Writer, command string) e
 	commandHex = append(commandHex, 0x01) // Command Quantity 2
 
 	// Calculate the CRC-16 checksum (from Codec ID onward, which is byte 5)
-	logger.Sugar().Infof("Bytes passed for CRC calculation: %x", commandHex[8:])
+	t.log().Sugar().Debugf("Bytes passed for CRC calculation: %x", commandHex[8:])
 
 	// Start CRC calculation from codec to before CRC
 	crcR := crc.CrcTeltonika(commandHex[8:]) // Start CRC calculation from codec to before CRC
@@ -718,14 +697,14 @@ func (t *FM1200Protocol) SendCommandToDevice(writer io.Writer, command string) e
 	commandHex = append(commandHex, byte(crcR>>8), byte(crcR))
 
 	// Send the command over the network
-	logger.Sugar().Info(commandHex)
+	t.log().Sugar().Debug(commandHex)
 	_, err := writer.Write(commandHex)
 	if err != nil {
-		logger.Error("Failed to send command", zap.Error(err))
+		t.log().Error("Failed to send command", zap.Error(err))
 		return err
 	}
 
-	logger.Sugar().Infof("Command %s sent successfully", command)
+	t.log().Sugar().Debugf("Command %s sent successfully", command)
 	return nil
 }
 
@@ -738,14 +717,14 @@ func (t *FM1200Protocol) ParseDeviceResponse(dataReader *bufio.Reader, dataLen u
 	if err != nil {
 		return nil, err
 	}
-	logger.Sugar().Info("Response Quantity: ", response.ResponseQuantity1)
+	t.log().Sugar().Debug("Response Quantity: ", response.ResponseQuantity1)
 
 	// Read Response Type
 	response.Type, err = dataReader.ReadByte()
 	if err != nil {
 		return nil, err
 	}
-	logger.Sugar().Info("Response Type: ", response.Type)
+	t.log().Sugar().Debug("Response Type: ", response.Type)
 
 	// Read Response Size
 	err = binary.Read(dataReader, binary.BigEndian, &response.ResponseSize)
@@ -753,7 +732,10 @@ func (t *FM1200Protocol) ParseDeviceResponse(dataReader *bufio.Reader, dataLen u
 		return nil, err
 	}
 
-	logger.Sugar().Info("Response Size: ", response.ResponseSize)
+	t.log().Sugar().Debug("Response Size: ", response.ResponseSize)
+	if response.ResponseSize > dataLen {
+		return nil, errors.Wrapf(errs.ErrFM1200BadDataPacket, "response size %d in %d bytes of data", response.ResponseSize, dataLen)
+	}
 
 	//todo: try to parse response data based on response quantity
 	// Read the actual Response Data (based on Response Size)
@@ -762,7 +744,7 @@ func (t *FM1200Protocol) ParseDeviceResponse(dataReader *bufio.Reader, dataLen u
 	if err != nil {
 		return nil, err
 	}
-	logger.Sugar().Info("Response Data: ", response.ResponseData)
+	t.log().Sugar().Debug("Response Data: ", response.ResponseData)
 
 	// Read Response Quantity 2
 	response.ResponseQuantity2, err = dataReader.ReadByte()
@@ -770,7 +752,7 @@ func (t *FM1200Protocol) ParseDeviceResponse(dataReader *bufio.Reader, dataLen u
 		return nil, err
 	}
 
-	logger.Sugar().Info("Response Quantity: ", response.ResponseQuantity2)
+	t.log().Sugar().Debug("Response Quantity: ", response.ResponseQuantity2)
 
 	response.CodecID = 0x0C
 
@@ -0,0 +1,87 @@
+package fm1200
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"io"
+	"testing"
+
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"go.uber.org/zap/zapcore"
+)
+
+// seeds from the protocol document and devices in the field, as the device sends them
+var fuzzSeeds = []string{
+	"000F333536333037303433373231353739", // imei login
+	"00000000000000A608030000013FEB40E0B2000F0EC760209A6B000062000006000000170A010002000300B300B4004501F00150041503C80008B50012B6000A423024180000CD0386CE0001431057440000044600000112C700000000F10000601A4800000000014E00000000000000000000013F14A1D1CE000F0EB790209A778000AB010C0500000000000000000000013F1498A63A000F0EB790209A77800095010C0400000000000000000300003390",
+	"000000000000003608010000016B40D8EA30010000000000000000000000000000000105021503010101425E0F01F10000601A014E0000000000000000010000C7CF",
+	"000000000000004A8E010000016B412CEE000100000000000000000000000000000000010005000100010100010011001D00010010015E2C880002000B000000003544C87A000E000000001DD7E06A00000100002994",
+	"00000000000000900C010600000088494E493A323031392F372F323220373A3232205254433A323031392F372F323220373A3533205253543A32204552523A312053523A302042523A302043463A302046473A3020464C3A302054553A302F302055543A3020534D533A30204E4F4750533A303A3330204750533A31205341543A302052533A332052463A36352053463A31204D443A30010000C78F",
+	"000000000000000F0C010500000007676574696E666F0100004312",
+}
+
+// fuzzSetup adds the seeds and turns the protocol's logging off, it slows the fuzzer down a lot
+func fuzzSetup(f *testing.F) {
+	configuredLogger.SetLevel(zapcore.FatalLevel)
+	for _, seed := range fuzzSeeds {
+		data, err := hex.DecodeString(seed)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+}
+
+// fuzzStore takes what the protocol stores until the test is done
+func fuzzStore(t *testing.T) store.Store {
+	s := &store.JsonLinesStore{ProcessChan: make(chan *types.DeviceStatus), ResponseChan: make(chan *types.DeviceResponse)}
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	go func() {
+		for {
+			select {
+			case <-s.ProcessChan:
+			case <-s.ResponseChan:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return s
+}
+
+func FuzzLogin(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		protocol := &FM1200Protocol{}
+		_, _, _ = protocol.Login(bufio.NewReader(bytes.NewReader(data)))
+	})
+}
+
+func FuzzConsumeStream(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		protocol := &FM1200Protocol{Imei: "356307043721579"}
+		_ = protocol.ConsumeStream(bufio.NewReader(bytes.NewReader(data)), io.Discard, fuzzStore(t))
+	})
+}
+
+func FuzzDecodeFrame(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		protocol := &FM1200Protocol{}
+		for len(data) > 0 {
+			frame, err := protocol.DecodeFrame(data)
+			if err != nil {
+				return
+			}
+			if frame.Size <= 0 || frame.Size > len(data) {
+				t.Fatalf("frame of %d bytes out of %d", frame.Size, len(data))
+			}
+			data = data[frame.Size:]
+		}
+	})
+}
@@ -9,8 +9,13 @@ import (
 	"io"
 	"testing"
 
+	"github.com/404minds/avl-receiver/internal/store"
 	"github.com/404minds/avl-receiver/internal/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestFM1200Login(t *testing.T) {
@@ -46,7 +51,7 @@ func TestDataPacketParsing(t *testing.T) {
 
 	var writeBuffer bytes.Buffer
 	writer := io.Writer(&writeBuffer)
-	asyncStore := make(chan types.DeviceStatus, 200)
+	asyncStore := &store.JsonLinesStore{ProcessChan: make(chan *types.DeviceStatus, 200)}
 
 	teltonika := FM1200Protocol{Imei: "something"}
 
@@ -55,21 +60,23 @@ func TestDataPacketParsing(t *testing.T) {
 
 	assert.Equal(t, []byte{0x00, 0x00, 0x00, 0x03}, writeBuffer.Bytes(), "Incorrect ack from consume data")
 
-	assert.Len(t, asyncStore, 3, "Incorrect number of records sent to store")
+	assert.Len(t, asyncStore.ProcessChan, 3, "Incorrect number of records sent to store")
 
-	var entry types.DeviceStatus = <-asyncStore
+	// records are stored oldest first, the packet has the newest first
+
+	var entry *types.DeviceStatus = <-asyncStore.ProcessChan
 	var firstRecord Record
 	_ = json.Unmarshal(entry.GetTeltonikaPacket().GetRawData(), &firstRecord)
 
 	assert.Equal(t, firstRecord.IMEI, "something", "Incorrect IMEI")
 	assert.Equal(t, firstRecord.Record.Priority, uint8(0), "Incorrect priority")
-	assert.Equal(t, firstRecord.Record.Timestamp, uint64(1374041465010), "Incorrect timestamp")
+	assert.Equal(t, firstRecord.Record.Timestamp, uint64(1370440115770), "Incorrect timestamp")
 	assert.NotNil(t, firstRecord.Record.GPSElement, "Incorrect gps element")
 	assert.NotNil(t, firstRecord.Record.IOElement, "Incorrect gps element")
-	assert.Equal(t, firstRecord.Record.IOElement.EventID, uint8(0), "Incorrect event id")
-	assert.Equal(t, firstRecord.Record.IOElement.NumProperties, uint8(23), "Incorrect number of IO elements")
+	assert.Equal(t, firstRecord.Record.IOElement.EventID, uint16(0), "Incorrect event id")
+	assert.Equal(t, firstRecord.Record.IOElement.NumProperties, uint16(0), "Incorrect number of IO elements")
 
-	entry = <-asyncStore
+	entry = <-asyncStore.ProcessChan
 	var secondRecord Record
 	json.Unmarshal(entry.GetTeltonikaPacket().GetRawData(), &secondRecord)
 
@@ -78,20 +85,36 @@ func TestDataPacketParsing(t *testing.T) {
 	assert.Equal(t, secondRecord.Record.Timestamp, uint64(1370440716750), "Incorrect timestamp")
 	assert.NotNil(t, secondRecord.Record.GPSElement, "Incorrect gps element")
 	assert.NotNil(t, secondRecord.Record.IOElement, "Incorrect gps element")
-	assert.Equal(t, secondRecord.Record.IOElement.EventID, uint8(0), "Incorrect event id")
-	assert.Equal(t, secondRecord.Record.IOElement.NumProperties, uint8(0), "Incorrect number of IO elements")
+	assert.Equal(t, secondRecord.Record.IOElement.EventID, uint16(0), "Incorrect event id")
+	assert.Equal(t, secondRecord.Record.IOElement.NumProperties, uint16(0), "Incorrect number of IO elements")
 
-	entry = <-asyncStore
+	entry = <-asyncStore.ProcessChan
 	var thirdRecord Record
 	json.Unmarshal(entry.GetTeltonikaPacket().GetRawData(), &thirdRecord)
 
 	assert.Equal(t, thirdRecord.IMEI, "something", "Incorrect IMEI")
 	assert.Equal(t, thirdRecord.Record.Priority, uint8(0), "Incorrect priority")
-	assert.Equal(t, thirdRecord.Record.Timestamp, uint64(1370440115770), "Incorrect timestamp")
+	assert.Equal(t, thirdRecord.Record.Timestamp, uint64(1374041465010), "Incorrect timestamp")
 	assert.NotNil(t, thirdRecord.Record.GPSElement, "Incorrect gps element")
 	assert.NotNil(t, thirdRecord.Record.IOElement, "Incorrect gps element")
-	assert.Equal(t, thirdRecord.Record.IOElement.EventID, uint8(0), "Incorrect event id")
-	assert.Equal(t, thirdRecord.Record.IOElement.NumProperties, uint8(0), "Incorrect number of IO elements")
+	assert.Equal(t, thirdRecord.Record.IOElement.EventID, uint16(0), "Incorrect event id")
+	assert.Equal(t, thirdRecord.Record.IOElement.NumProperties, uint16(23), "Incorrect number of IO elements")
+}
+
+func TestParserLogsWithDeviceLogger(t *testing.T) {
+	buf, _ := hex.DecodeString("00000000000000A608030000013FEB40E0B2000F0EC760209A6B000062000006000000170A010002000300B300B4004501F00150041503C80008B50012B6000A423024180000CD0386CE0001431057440000044600000112C700000000F10000601A4800000000014E00000000000000000000013F14A1D1CE000F0EB790209A778000AB010C0500000000000000000000013F1498A63A000F0EB790209A77800095010C0400000000000000000300003390")
+	core, logs := observer.New(zapcore.DebugLevel)
+	teltonika := FM1200Protocol{Imei: "357454075177072"}
+	teltonika.SetLogger(zap.New(core).With(zap.String("imei", "357454075177072")))
+
+	asyncStore := &store.JsonLinesStore{ProcessChan: make(chan *types.DeviceStatus, 200)}
+	err := teltonika.ConsumeStream(bufio.NewReader(bytes.NewReader(buf)), io.Discard, asyncStore)
+	assert.ErrorIs(t, err, io.EOF)
+
+	require.NotZero(t, logs.Len(), "the parser logs through the device's logger")
+	for _, entry := range logs.All() {
+		assert.Equal(t, "357454075177072", entry.ContextMap()["imei"], entry.Message)
+	}
 }
 
 func TestGpsParsing(t *testing.T) {
@@ -376,7 +399,7 @@ func TestConvertDecimalToHexAndReverse(t *testing.T) {
 		},
 		{
 			decimalValue: 0,
-			expectedHex:  "0000000000000000", // Edge case for 0
+			expectedHex:  "", // 0 is no value
 		},
 	}
 
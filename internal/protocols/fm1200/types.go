@@ -20,6 +20,10 @@ const (
 	codec14 CodecID = 0x0E
 )
 
+// maxDataLength is the longest data a packet's length is believed for, the devices send a few kB
+// at most and anything longer would only be allocated for the read to fail
+const maxDataLength = 64 * 1024
+
 type DeviceResponse struct {
 	CodecID           byte   // Codec ID (always 0x0C for Codec12)
 	ResponseQuantity1 byte   // Response Quantity 1
@@ -254,7 +258,7 @@ func (r *Response) ToProtobufDeviceResponse() *types.DeviceResponse {
 	asciiMessage := string(r.Reply)
 
 	// Log the entire decoded message as a single string, not character by character
-	logger.Sugar().Infof("Full Response: %s", asciiMessage)
+	logger.Sugar().Debugw("Full Response", "imei", r.IMEI, "response", asciiMessage)
 
 	return &types.DeviceResponse{
 		Imei:     r.IMEI,
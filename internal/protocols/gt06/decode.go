@@ -0,0 +1,84 @@
+package gt06
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/crc"
+	"github.com/404minds/avl-receiver/internal/decode"
+	errs "github.com/404minds/avl-receiver/internal/errors"
+	"github.com/404minds/avl-receiver/internal/types"
+)
+
+// DecodeFrame decodes the packet at the start of data. The login's imei and timezone are kept for
+// the packets after it, the timestamps are read as utc until then.
+func (p *GT06Protocol) DecodeFrame(data []byte) (*decode.Frame, error) {
+	size, err := frameSize(data)
+	if err != nil {
+		return nil, err
+	}
+	if p.LoginInformation == nil {
+		p.LoginInformation = &LoginData{Timezone: time.UTC}
+	}
+
+	packet, err := p.parsePacket(bufio.NewReader(bytes.NewReader(data[:size])))
+	if err != nil {
+		return nil, err
+	}
+	frame := &decode.Frame{
+		Kind:   packet.MessageType.String(),
+		Packet: packet,
+		Size:   size,
+		Checksum: &decode.Checksum{
+			Name:       "crc16",
+			Sent:       uint32(packet.Crc),
+			Calculated: uint32(crc.CrcWanway(data[2 : size-4])), // length to serial number
+		},
+	}
+	if login, ok := packet.Information.(*LoginData); ok {
+		p.LoginInformation = login
+		return frame, nil
+	}
+	if reply, ok := packet.Information.(*CommandReply); ok {
+		frame.Responses = []*types.DeviceResponse{{Imei: p.LoginInformation.TerminalID, Response: reply.Content}}
+		return frame, nil
+	}
+	frame.Statuses = []*types.DeviceStatus{packet.ToProtobufDeviceStatus(p.LoginInformation.TerminalID, p.DeviceType)}
+	return frame, nil
+}
+
+// frameSize is the size of the packet at the start of data, start and stop bits included
+func frameSize(data []byte) (int, error) {
+	if len(data) < 2 {
+		return 0, decode.ErrIncomplete
+	}
+	var size int
+	switch binary.BigEndian.Uint16(data) {
+	case 0x7878:
+		if len(data) < 3 {
+			return 0, decode.ErrIncomplete
+		}
+		size = 3 + int(data[2]) + 2
+	case StartBitValue:
+		if len(data) < 4 {
+			return 0, decode.ErrIncomplete
+		}
+		length := binary.BigEndian.Uint16(data[2:])
+		if length > MaxPacketLength {
+			return 0, fmt.Errorf("%w: gt06 packet length %d", errs.ErrFrameTooLarge, length)
+		}
+		size = 4 + int(length) + 2
+	default:
+		return 0, fmt.Errorf("%w: invalid start bit %#04x", errs.ErrTR06BadDataPacket, binary.BigEndian.Uint16(data))
+	}
+	if size < 10 {
+		return 0, fmt.Errorf("%w: packet length too short", errs.ErrTR06BadDataPacket)
+	}
+	if len(data) < size {
+		return 0, decode.ErrIncomplete
+	}
+	return size, nil
+}
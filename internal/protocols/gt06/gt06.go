@@ -7,6 +7,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"runtime"
 	"time"
 
 	"github.com/404minds/avl-receiver/internal/store"
@@ -24,17 +25,30 @@ var logger = configuredLogger.Logger
 type GT06Protocol struct {
 	LoginInformation *LoginData
 	DeviceType       types.DeviceType
+
+	deviceLogger *zap.Logger
+}
+
+func (p *GT06Protocol) SetLogger(l *zap.Logger) {
+	p.deviceLogger = l
+}
+
+func (p *GT06Protocol) log() *zap.Logger {
+	if p.deviceLogger != nil {
+		return p.deviceLogger
+	}
+	return logger
 }
 
 func (p *GT06Protocol) GetDeviceID() string {
-	logger.Sugar().Info(p.LoginInformation)
+	p.log().Sugar().Debug(p.LoginInformation)
 	if p.LoginInformation == nil {
-		logger.Error("LoginInformation is nil in GetDeviceID")
+		p.log().Error("LoginInformation is nil in GetDeviceID")
 		return ""
 	}
 
 	if p.LoginInformation.TerminalID == "" {
-		logger.Error("Login Information does not have TerminalID in GetDeviceID")
+		p.log().Error("Login Information does not have TerminalID in GetDeviceID")
 	}
 
 	return p.LoginInformation.TerminalID
@@ -60,39 +74,39 @@ func (p *GT06Protocol) Login(reader *bufio.Reader) (ack []byte, byteToSkip int,
 	// This should have been a TR06 device
 	packet, err := p.parsePacket(reader)
 	if err != nil {
-		logger.Error("failed to parse TR06 packet", zap.Error(err))
+		p.log().Error("failed to parse TR06 packet", zap.Error(err))
 		return nil, 0, err
 	}
 
 	if packet.MessageType == MSG_LoginData {
 		if packet.Information == nil {
-			logger.Error("packet information is nil", zap.Error(errs.ErrTR06InvalidLoginInfo))
+			p.log().Error("packet information is nil", zap.Error(errs.ErrTR06InvalidLoginInfo))
 			return nil, 0, errs.ErrTR06InvalidLoginInfo
 		}
 
 		loginData, ok := packet.Information.(*LoginData)
 		if !ok {
-			logger.Error("packet information is not of type *LoginData", zap.Error(errs.ErrTR06InvalidLoginInfo))
+			p.log().Error("packet information is not of type *LoginData", zap.Error(errs.ErrTR06InvalidLoginInfo))
 			return nil, 0, errs.ErrTR06InvalidLoginInfo
 		}
 
 		if loginData == nil {
-			logger.Error("loginData is nil", zap.Error(errs.ErrTR06InvalidLoginInfo))
+			p.log().Error("loginData is nil", zap.Error(errs.ErrTR06InvalidLoginInfo))
 			return nil, 0, errs.ErrTR06InvalidLoginInfo
 		}
-		logger.Sugar().Info("from Login LoginData: ", loginData)
+		p.log().Sugar().Debug("from Login LoginData: ", loginData)
 		p.LoginInformation = loginData
 
 		byteBuffer := bytes.NewBuffer([]byte{})
 		err = p.sendResponse(packet, byteBuffer)
 		if err != nil {
-			logger.Error("failed to send response for TR06 packet", zap.Error(err))
+			p.log().Error("failed to send response for TR06 packet", zap.Error(err))
 			return nil, 0, err
 		}
 
 		return byteBuffer.Bytes(), 0, nil // nothing to skip since the stream is already consumed
 	} else {
-		logger.Error("packet message type is not MSG_LoginData", zap.Error(errs.ErrTR06InvalidLoginInfo))
+		p.log().Error("packet message type is not MSG_LoginData", zap.Error(errs.ErrTR06InvalidLoginInfo))
 		return nil, 0, errs.ErrTR06InvalidLoginInfo
 	}
 }
@@ -101,15 +115,25 @@ func (p *GT06Protocol) ConsumeStream(reader *bufio.Reader, writer io.Writer, dat
 	for {
 		packet, err := p.parsePacket(reader)
 		if err != nil {
-			logger.Sugar().Info("Consume Stream :", err)
+			p.log().Sugar().Debug("Consume Stream :", err)
 			return err
 		}
 		if packet.MessageType == MSG_HeartbeatData {
 			err = p.sendResponse(packet, writer)
 			if err != nil {
-				logger.Sugar().Info("error while sending response", err)
+				p.log().Sugar().Debug("error while sending response", err)
+				return err
+			}
+		}
+
+		if reply, ok := packet.Information.(*CommandReply); ok {
+			err = p.sendResponse(packet, writer)
+			if err != nil {
+				p.log().Sugar().Debug("error while sending response", err)
 				return err
 			}
+			dataStore.GetResponseChan() <- &types.DeviceResponse{Imei: p.GetDeviceID(), Response: reply.Content}
+			continue
 		}
 
 		asyncStore := dataStore.GetProcessChan()
@@ -123,7 +147,7 @@ func (p *GT06Protocol) sendResponse(parsedPacket *Packet, writer io.Writer) erro
 	defer func() {
 		if condition := recover(); condition != nil {
 			err := fmt.Errorf("panic: %v", condition)
-			logger.Error("Failed to write response packet", zap.Error(err))
+			p.log().Error("Failed to write response packet", zap.Error(err))
 		}
 	}()
 
@@ -147,16 +171,12 @@ func (p *GT06Protocol) sendResponse(parsedPacket *Packet, writer io.Writer) erro
 func (p *GT06Protocol) parsePacket(reader *bufio.Reader) (packet *Packet, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			if rErr, ok := r.(error); ok {
-				err = rErr
-			} else {
-				err = fmt.Errorf("parse packet unknown panic: %v", r)
-			}
-			if err != io.EOF {
+			err = recovered(r)
+			if err != io.EOF && !errors.Is(err, errs.ErrParserPanic) {
 				err = errors.Wrapf(errs.ErrTR06BadDataPacket, "from parsePAcket")
-				logger.Sugar().Info("parse packet 0 ", err)
+				p.log().Sugar().Debug("parse packet 0 ", err)
 			}
-			logger.Sugar().Errorf("parse packet Recovered from panic: %v", err)
+			p.log().Sugar().Errorf("parse packet Recovered from panic: %v", err)
 		}
 	}()
 
@@ -165,7 +185,7 @@ func (p *GT06Protocol) parsePacket(reader *bufio.Reader) (packet *Packet, err er
 	// Start bit
 	err = binary.Read(reader, binary.BigEndian, &packet.StartBit)
 	if err != nil {
-		logger.Sugar().Errorf("parse packet Failed to read start bit: %v", err)
+		p.log().Sugar().Errorf("parse packet Failed to read start bit: %v", err)
 		return nil, err
 	}
 
@@ -174,63 +194,71 @@ func (p *GT06Protocol) parsePacket(reader *bufio.Reader) (packet *Packet, err er
 		var packetLength uint16
 		err = binary.Read(reader, binary.BigEndian, &packetLength)
 		if err != nil {
-			logger.Sugar().Errorf("parse packet Failed to read packet length: %v", err)
+			p.log().Sugar().Errorf("parse packet Failed to read packet length: %v", err)
 			return nil, err
 		}
-		packet.PacketLength = byte(packetLength)
+		if packetLength > MaxPacketLength {
+			return nil, errors.Wrapf(errs.ErrFrameTooLarge, "gt06 packet length %d", packetLength)
+		}
+		packet.PacketLength = packetLength
 
 	} else if packet.StartBit == 0x7878 {
 		var packetLength uint8
 		err = binary.Read(reader, binary.BigEndian, &packetLength)
 		if err != nil {
-			logger.Sugar().Errorf("parse packet Failed to read packet length: %v", err)
+			p.log().Sugar().Errorf("parse packet Failed to read packet length: %v", err)
 			return nil, err
 		}
-		packet.PacketLength = packetLength
+		packet.PacketLength = uint16(packetLength)
 	} else {
 		return nil, errors.Wrapf(errs.ErrTR06BadDataPacket, "from parsePacket Invalid StartBit packet.StartBit: %d", packet.StartBit) // Invalid start bit
 	}
 
+	// protocol number, serial number and crc at least, a shorter length would wrap around below
+	if packet.PacketLength < 5 {
+		return nil, errors.Wrapf(errs.ErrGT06BadDataPacket, "from parsePacket packet length %d", packet.PacketLength)
+	}
+
 	// Packet data
 	packetData := make([]byte, packet.PacketLength-4) // 2 for CRC, 2 for serial number
 
 	_, err = io.ReadFull(reader, packetData)
 	if err != nil {
-		logger.Sugar().Errorf("parse packet Failed to read packet data: %v", err)
+		p.log().Sugar().Errorf("parse packet Failed to read packet data: %v", err)
 		return nil, err
 	}
 
 	// Packet data to packet
 	err = p.parsePacketData(bufio.NewReader(bytes.NewReader(packetData)), packet)
 	if err != nil {
-		logger.Sugar().Errorf("parse packet Failed to parse packet data: %v", err)
+		p.log().Sugar().Errorf("parse packet Failed to parse packet data: %v", err)
 		return nil, err
 	}
 
 	// Information serial number
 	err = binary.Read(reader, binary.BigEndian, &packet.InformationSerialNumber)
 	if err != nil {
-		logger.Sugar().Errorf("parse packet Failed to read information serial number: %v", err)
+		p.log().Sugar().Errorf("parse packet Failed to read information serial number: %v", err)
 		return nil, err
 	}
 
 	// CRC
 	err = binary.Read(reader, binary.BigEndian, &packet.Crc)
 	if err != nil {
-		logger.Sugar().Errorf("parse packet Failed to read CRC: %v", err)
+		p.log().Sugar().Errorf("parse packet Failed to read CRC: %v", err)
 		return nil, err
 	}
 
 	// Stop bits
 	err = binary.Read(reader, binary.BigEndian, &packet.StopBits)
 	if err != nil {
-		logger.Sugar().Errorf("parse packet Failed to read stop bits: %v", err)
+		p.log().Sugar().Errorf("parse packet Failed to read stop bits: %v", err)
 		return nil, err
 	}
 
 	if packet.StopBits != 0x0d0a {
 		err = errors.Wrapf(errs.ErrTR06BadDataPacket, "from parsePacket 3")
-		logger.Sugar().Errorf("parse packet Invalid stop bits: %x  parse packet 1 ERRTRO6 %v", packet.StopBits, err)
+		p.log().Sugar().Errorf("parse packet Invalid stop bits: %x  parse packet 1 ERRTRO6 %v", packet.StopBits, err)
 		return nil, err
 	}
 
@@ -246,7 +274,7 @@ func (p *GT06Protocol) parsePacket(reader *bufio.Reader) (packet *Packet, err er
 	//	),
 	//)
 	//if expectedCrc != packet.Crc {
-	//	logger.Sugar().Errorf("parse packet Invalid CRC. Expected %x, got %x", expectedCrc, packet.Crc)
+	//	p.log().Sugar().Errorf("parse packet Invalid CRC. Expected %x, got %x", expectedCrc, packet.Crc)
 	//	return nil, errs.ErrBadCrc
 	//}
 
@@ -259,13 +287,13 @@ func (p *GT06Protocol) parsePacketData(reader *bufio.Reader, packet *Packet) err
 	msgType := MessageType(protocolNumByte)
 
 	if msgType == MSG_Invalid {
-		logger.Sugar().Errorf("Invalid message type: %x", protocolNumByte)
+		p.log().Sugar().Errorf("Invalid message type: %x", protocolNumByte)
 		remainingData, err := p.consumePacket(reader)
 		if err != nil {
 			return err
 		}
-		logger.Sugar().Errorln("Invalid message type: ", hex.Dump(remainingData))
-		logger.Sugar().Info("error from parsePacketData ", err)
+		p.log().Sugar().Errorln("Invalid message type: ", hex.Dump(remainingData))
+		p.log().Sugar().Debug("error from parsePacketData ", err)
 		return errors.Wrapf(errs.ErrTR06BadDataPacket, "from parsePacketData")
 	}
 
@@ -316,6 +344,9 @@ func (p *GT06Protocol) parsePacketInformation(reader *bufio.Reader, messageType
 	} else if messageType == MSG_TransmissionInstruction {
 		parsedInfo, err := p.parseInformationTransmissionPacket(reader)
 		return parsedInfo, err
+	} else if messageType == MSG_StringInformation {
+		parsedInfo, err := p.parseCommandReply(reader)
+		return parsedInfo, err
 	} else {
 		return nil, errors.Wrapf(errs.ErrTR06BadDataPacket, "from parsePAcketInformation")
 	}
@@ -327,23 +358,23 @@ func (p *GT06Protocol) parseLoginInformation(reader *bufio.Reader) (interface{},
 	var imeiBytes [8]byte
 	err := binary.Read(reader, binary.BigEndian, &imeiBytes)
 	if err != nil {
-		logger.Error("failed to read IMEI bytes", zap.Error(err))
+		p.log().Error("failed to read IMEI bytes", zap.Error(err))
 		return nil, errs.ErrTR06InvalidLoginInfo
 	}
-	logger.Sugar().Info("parseLoginInformation imeiBytes: ", imeiBytes[:])
+	p.log().Sugar().Debug("parseLoginInformation imeiBytes: ", imeiBytes[:])
 	loginInfo.TerminalID = hex.EncodeToString(imeiBytes[:])[1:] // IMEI is 15 chars
-	logger.Sugar().Info("parseLoginInformation loginInfo: ", loginInfo)
-	logger.Sugar().Info("parseLoginInformation loginInfo.TerminalID: ", loginInfo.TerminalID)
+	p.log().Sugar().Debug("parseLoginInformation loginInfo: ", loginInfo)
+	p.log().Sugar().Debug("parseLoginInformation loginInfo.TerminalID: ", loginInfo.TerminalID)
 	err = binary.Read(reader, binary.BigEndian, &loginInfo.TerminalType)
 	if err != nil {
-		logger.Error("failed to read terminal type", zap.Error(err))
+		p.log().Error("failed to read terminal type", zap.Error(err))
 		return nil, errs.ErrTR06InvalidLoginInfo
 	}
 
 	var timezoneOffset int16
 	err = binary.Read(reader, binary.BigEndian, &timezoneOffset)
 	if err != nil {
-		logger.Error("failed to read timezone offset", zap.Error(err))
+		p.log().Error("failed to read timezone offset", zap.Error(err))
 		return nil, errs.ErrTR06InvalidLoginInfo
 	}
 	timezonePart := int(timezoneOffset >> 4)
@@ -357,16 +388,16 @@ func (p *GT06Protocol) parseLoginInformation(reader *bufio.Reader) (interface{},
 	}
 	loginInfo.Timezone = time.FixedZone("", int(zoneOffset)*(hours*60*60+minutes*60))
 
-	logger.Sugar().Info("parseLoginInformation loginInfo: ", loginInfo)
+	p.log().Sugar().Debug("parseLoginInformation loginInfo: ", loginInfo)
 	return &loginInfo, nil
 }
 
 func (p *GT06Protocol) parsePositioningData(reader *bufio.Reader) (positionInfo interface{}, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			err = r.(error)
-			if err != io.EOF {
-				logger.Sugar().Info("from parsePositioningData err: ", err)
+			err = recovered(r)
+			if err != io.EOF && !errors.Is(err, errs.ErrParserPanic) {
+				p.log().Sugar().Debug("from parsePositioningData err: ", err)
 				err = errors.Wrapf(errs.ErrTR06BadDataPacket, "from parsePositioningData")
 			}
 		}
@@ -385,7 +416,7 @@ func (p *GT06Protocol) parsePositioningData(reader *bufio.Reader) (positionInfo
 	// ACC
 	var b byte
 	checkErr(binary.Read(reader, binary.BigEndian, &b))
-	logger.Sugar().Infoln("[AccHIGH] ", b)
+	p.log().Sugar().Debugln("[AccHIGH] ", b)
 	parsed.ACCHigh = b == 0x01 // 00 is low, 01 is high
 
 	// data reporting mode
@@ -402,9 +433,9 @@ func (p *GT06Protocol) parsePositioningData(reader *bufio.Reader) (positionInfo
 func (p *GT06Protocol) parseAlarmData(reader *bufio.Reader) (alarmInfo AlarmInformation, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			err = r.(error)
-			if err != io.EOF {
-				logger.Sugar().Info("error from parseAlarmData err: ", err)
+			err = recovered(r)
+			if err != io.EOF && !errors.Is(err, errs.ErrParserPanic) {
+				p.log().Sugar().Debug("error from parseAlarmData err: ", err)
 				err = errors.Wrapf(errs.ErrTR06BadDataPacket, "from parseAlarmData")
 			}
 		}
@@ -422,13 +453,12 @@ func (p *GT06Protocol) parseAlarmData(reader *bufio.Reader) (alarmInfo AlarmInfo
 	return
 }
 
-func (p *GT06Protocol) parseHeartbeatData(reader *bufio.Reader) (interface{}, error) {
-	var err error
+func (p *GT06Protocol) parseHeartbeatData(reader *bufio.Reader) (info interface{}, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			err := r.(error)
-			if err != io.EOF {
-				logger.Sugar().Info("error from parseHeartbeatData 1 err: ", err)
+			info, err = nil, recovered(r)
+			if err != io.EOF && !errors.Is(err, errs.ErrParserPanic) {
+				p.log().Sugar().Debug("error from parseHeartbeatData 1 err: ", err)
 				err = errors.Wrapf(errs.ErrTR06BadDataPacket, "from parseHeartbeatData")
 			}
 		}
@@ -473,8 +503,8 @@ func (p *GT06Protocol) parseHeartbeatData(reader *bufio.Reader) (interface{}, er
 
 	// Check for extra bytes
 	if _, err := reader.Peek(1); err != io.EOF {
-		logger.Sugar().Errorf("parseHeartbeatData Extra bytes detected in packet")
-		logger.Sugar().Info("error from parseHeartbeatData 2")
+		p.log().Sugar().Errorf("parseHeartbeatData Extra bytes detected in packet")
+		p.log().Sugar().Debug("error from parseHeartbeatData 2")
 		return nil, errors.Wrapf(errs.ErrTR06BadDataPacket, "from parseHeartbeatData 2")
 	}
 
@@ -485,9 +515,9 @@ func (p *GT06Protocol) parseHeartbeatData(reader *bufio.Reader) (interface{}, er
 func (p *GT06Protocol) parseInformationTransmissionPacket(reader *bufio.Reader) (packet InformationTransmissionPacket, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			err = r.(error)
-			if err != io.EOF {
-				logger.Sugar().Info("error from parseInformationTransmissionPacket: ", err)
+			err = recovered(r)
+			if err != io.EOF && !errors.Is(err, errs.ErrParserPanic) {
+				p.log().Sugar().Debug("error from parseInformationTransmissionPacket: ", err)
 				err = errors.New("TR06 Bad Data Packet")
 			}
 		}
@@ -495,35 +525,35 @@ func (p *GT06Protocol) parseInformationTransmissionPacket(reader *bufio.Reader)
 
 	var informationType byte
 	if err := binary.Read(reader, binary.BigEndian, &informationType); err != nil {
-		logger.Sugar().Info("parseInformationTransmissionPacket: Failed to read information type", err)
+		p.log().Sugar().Debug("parseInformationTransmissionPacket: Failed to read information type", err)
 		return packet, err
 	}
 
 	packet.InformationContent.InformationType = InformationType(informationType)
-	logger.Sugar().Info("parseInformationTransmissionPacket: ", packet.InformationContent.InformationType)
+	p.log().Sugar().Debug("parseInformationTransmissionPacket: ", packet.InformationContent.InformationType)
 
 	dataContent := make([]byte, 2)
 	if _, err := io.ReadFull(reader, dataContent); err != nil {
-		logger.Sugar().Info("parseInformationTransmissionPacket: Failed to read data content ", err)
+		p.log().Sugar().Debug("parseInformationTransmissionPacket: Failed to read data content ", err)
 		return packet, err
 	}
 
-	logger.Sugar().Info("parseInformationTransmissionPacket: Parsing data content based on information type ", informationType)
+	p.log().Sugar().Debug("parseInformationTransmissionPacket: Parsing data content based on information type ", informationType)
 	switch InformationType(informationType) {
 	case ExternalPowerVoltage:
 		if len(dataContent) < 2 {
-			logger.Sugar().Info("parseInformationTransmissionPacket: Insufficient data for ExternalPowerVoltage")
+			p.log().Sugar().Debug("parseInformationTransmissionPacket: Insufficient data for ExternalPowerVoltage")
 			return packet, errors.New("Insufficient data for ExternalPowerVoltage")
 		}
 		voltage := binary.BigEndian.Uint16(dataContent)
-		logger.Sugar().Info("voltage: ", voltage)
+		p.log().Sugar().Debug("voltage: ", voltage)
 		packet.InformationContent.DataContent = (voltage) / 100
 	case TerminalStatusSync:
 		status := packet.InformationContent.DataContent
 		packet.InformationContent.DataContent = status
 	case DoorStatus:
 		if len(dataContent) < 1 {
-			logger.Sugar().Info("parseInformationTransmissionPacket: Insufficient data for DoorStatus")
+			p.log().Sugar().Debug("parseInformationTransmissionPacket: Insufficient data for DoorStatus")
 			return packet, errors.New("Insufficient data for DoorStatus")
 		}
 		doorStatus := packet.InformationContent.DataContent
@@ -533,12 +563,12 @@ func (p *GT06Protocol) parseInformationTransmissionPacket(reader *bufio.Reader)
 	}
 
 	if remain, err := reader.Peek(1); err != io.EOF {
-		logger.Sugar().Info("parseInformationTransmissionPacket remaining bytes: ", remain)
-		logger.Sugar().Info("parseInformationTransmissionPacket: Extra bytes detected in packet")
+		p.log().Sugar().Debug("parseInformationTransmissionPacket remaining bytes: ", remain)
+		p.log().Sugar().Debug("parseInformationTransmissionPacket: Extra bytes detected in packet")
 		return packet, errors.New("TR06 Bad Data Packet")
 	}
 
-	logger.Sugar().Info("parseInformationTransmissionPacket: Successfully parsed packet")
+	p.log().Sugar().Debug("parseInformationTransmissionPacket: Successfully parsed packet")
 	return packet, nil
 }
 
@@ -548,6 +578,17 @@ func checkErr(err error) {
 	}
 }
 
+// recovered is the error for what a parser recovered from, checkErr's errors as they are and
+// anything else as errs.ErrParserPanic, so that bugs don't pass for bad packets
+func recovered(r interface{}) error {
+	if _, ok := r.(runtime.Error); !ok {
+		if err, ok := r.(error); ok {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: %v", errs.ErrParserPanic, r)
+}
+
 func (p *GT06Protocol) parseGPSInformation(reader *bufio.Reader) (gpsInfo GPSInformation, err error) {
 	timestamp, err := p.parseTimestamp(reader)
 	checkErr(err)
@@ -613,12 +654,18 @@ func (p *GT06Protocol) parseTimestamp(reader *bufio.Reader) (timestamp time.Time
 	second, err := reader.ReadByte()
 	checkErr(err)
 
-	logger.Sugar().Infof("Read day byte: %d", day)
-	logger.Sugar().Infof("Read hour byte: %d", hour)
-	logger.Sugar().Infof("Read minute byte: %d", minute)
-	logger.Sugar().Infof("Read  p.LoginInformation.Timezone byte: %d", p.LoginInformation.Timezone)
+	p.log().Sugar().Debugf("Read day byte: %d", day)
+	p.log().Sugar().Debugf("Read hour byte: %d", hour)
+	p.log().Sugar().Debugf("Read minute byte: %d", minute)
+
+	// a packet before the login, which a device shouldn't send, is read as utc
+	timezone := time.UTC
+	if p.LoginInformation != nil && p.LoginInformation.Timezone != nil {
+		timezone = p.LoginInformation.Timezone
+	}
+	p.log().Sugar().Debugf("Read timezone: %s", timezone)
 
-	timestamp = time.Date(yearInt, time.Month(month), int(day), int(hour), int(minute), int(second), 0, p.LoginInformation.Timezone)
+	timestamp = time.Date(yearInt, time.Month(month), int(day), int(hour), int(minute), int(second), 0, timezone)
 	return timestamp, nil
 }
 
@@ -695,8 +742,39 @@ func (p *GT06Protocol) IsValidHeader(reader *bufio.Reader) bool {
 	return false
 }
 
+// parseCommandReply reads the command length, the server flag and the ascii content, the length
+// covers the server flag too. Some firmwares add a language after the content, it's left unread.
+func (p *GT06Protocol) parseCommandReply(reader *bufio.Reader) (*CommandReply, error) {
+	length, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if length < 4 {
+		return nil, errors.Wrapf(errs.ErrTR06BadDataPacket, "command reply length %d", length)
+	}
+
+	reply := &CommandReply{}
+	err = binary.Read(reader, binary.BigEndian, &reply.ServerFlag)
+	if err != nil {
+		return nil, err
+	}
+	content := make([]byte, length-4)
+	_, err = io.ReadFull(reader, content)
+	if err != nil {
+		return nil, err
+	}
+	reply.Content = string(content)
+	return reply, nil
+}
+
 // send command to device
+// the command is sent as an online command (0x80) packet, the device answers with a 0x15 string packet
+// that ConsumeStream hands on as the response
 func (p *GT06Protocol) SendCommandToDevice(writer io.Writer, command string) error {
-	// Command in HEX for "getinfo"
-	return nil
+	packet, err := crc.WanwayCommandPacket(command)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(packet)
+	return errors.Wrapf(err, "failed to write command packet")
 }
@@ -4,10 +4,13 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/hex"
+	"io"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -160,3 +163,24 @@ func TestParseGpsLocationPacket(t *testing.T) {
 	assert.Equal(t, float32(41601048/1800000), gpsData.Latitude, "latitude should match")
 	assert.Equal(t, float32(52719804416/1800000), gpsData.Longitude, "latitude should match")
 }
+
+func TestConsumeStreamCommandReply(t *testing.T) {
+	// "DYD=Success!" in reply to a command, with the language after it
+	bytestr := strings.ReplaceAll("78 78 18 15 10 00 00 00 00 44 59 44 3D 53 75 63 63 65 73 73 21 00 02 00 03 69 D9 0D 0A", " ", "")
+	data, _ := hex.DecodeString(bytestr)
+
+	p := GT06Protocol{LoginInformation: &LoginData{TerminalID: "355172106660428"}}
+	dataStore := &store.JsonLinesStore{ProcessChan: make(chan *types.DeviceStatus, 1), ResponseChan: make(chan *types.DeviceResponse, 1)}
+	var ack bytes.Buffer
+
+	err := p.ConsumeStream(bufio.NewReader(bytes.NewReader(data)), &ack, dataStore)
+	assert.ErrorIs(t, err, io.EOF, "the stream should only end once the data runs out")
+
+	assert.Equal(t, "7878051500031c3a0d0a", hex.EncodeToString(ack.Bytes()), "the reply should be acked")
+	if assert.Len(t, dataStore.ResponseChan, 1) {
+		response := <-dataStore.ResponseChan
+		assert.Equal(t, "355172106660428", response.Imei)
+		assert.Equal(t, "DYD=Success!", response.Response)
+	}
+	assert.Empty(t, dataStore.ProcessChan, "a reply isn't a status")
+}
@@ -13,6 +13,9 @@ import (
 const (
 	StartBitValue = 0x7979
 	StopBitValue  = 0x0D0A
+	// longest 0x7979 packet length believed, the information transmission ones are a few hundred
+	// bytes at most
+	MaxPacketLength = 1024
 )
 
 type InformationType byte
@@ -52,7 +55,7 @@ type InformationContent struct {
 
 type Packet struct {
 	StartBit                uint16
-	PacketLength            byte
+	PacketLength            uint16 // a byte for 0x7878 packets
 	MessageType             MessageType
 	Information             interface{}
 	InformationSerialNumber uint16
@@ -66,6 +69,13 @@ type LoginData struct {
 	Timezone     *time.Location
 }
 
+// CommandReply is the 0x15 packet the device answers an online command (0x80) with, the server
+// flag is the one the command was sent with
+type CommandReply struct {
+	ServerFlag uint32
+	Content    string
+}
+
 type HeartbeatData struct {
 	TerminalInformation TerminalInformation
 	BatteryLevel        BatteryLevel
@@ -297,7 +307,7 @@ func (packet *Packet) ToProtobufDeviceStatus(imei string, deviceType types.Devic
 		MessageType:   packet.MessageType.String(),
 	}
 
-	logger.Sugar().Info("message type: ", info.MessageType)
+	logger.Sugar().Debugw("message type", "imei", imei, "messageType", info.MessageType)
 
 	// Variables for shared data
 	var ignition bool
@@ -342,7 +352,7 @@ func (packet *Packet) ToProtobufDeviceStatus(imei string, deviceType types.Devic
 		ignition = v.TerminalInformation.ACCHigh
 		info.VehicleStatus.Ignition = &ignition
 		//Set battery and GSM signal
-		logger.Sugar().Info(v.BatteryLevel, "  ", v.GSMSignalStrength)
+		logger.Sugar().Debugw("battery and gsm signal", "imei", imei, "battery", v.BatteryLevel, "gsm", v.GSMSignalStrength)
 		info.BatteryLevel = resolveBatteryLevel(int32(v.BatteryLevel))
 		info.GsmNetwork = int32(v.GSMSignalStrength)
 
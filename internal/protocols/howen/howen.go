@@ -9,6 +9,7 @@ import (
 	"github.com/404minds/avl-receiver/internal/types"
 	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
+	"go.uber.org/zap"
 	"io"
 )
 
@@ -16,6 +17,19 @@ var logger = configuredLogger.Logger
 
 type HOWENWS struct {
 	DeviceType types.DeviceType
+
+	deviceLogger *zap.Logger
+}
+
+func (p *HOWENWS) SetLogger(l *zap.Logger) {
+	p.deviceLogger = l
+}
+
+func (p *HOWENWS) log() *zap.Logger {
+	if p.deviceLogger != nil {
+		return p.deviceLogger
+	}
+	return logger
 }
 
 func (p *HOWENWS) GetDeviceID() string {
@@ -49,19 +63,19 @@ func (p *HOWENWS) SendCommandToDevice(writer io.Writer, command string) error {
 }
 
 func (p *HOWENWS) ConsumeConnection(conn *websocket.Conn, dataStore store.Store) error {
-	logger.Sugar().Info("consume connection called")
+	p.log().Sugar().Debug("consume connection called")
 	for {
 		if conn == nil {
-			logger.Sugar().Error("Connection is nil, stopping consumption.")
+			p.log().Sugar().Error("Connection is nil, stopping consumption.")
 			return errors.New("connection is nil")
 		}
 
 		err := p.ConsumeMessage(conn, dataStore)
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err) {
-				logger.Sugar().Error("WebSocket closed unexpectedly:", err)
+				p.log().Sugar().Error("WebSocket closed unexpectedly:", err)
 			} else {
-				logger.Sugar().Info("WebSocket read error:", err)
+				p.log().Sugar().Debug("WebSocket read error:", err)
 			}
 			return err
 		}
@@ -72,11 +86,11 @@ func (p *HOWENWS) ConsumeMessage(conn *websocket.Conn, dataStore store.Store) er
 	// Read message from WebSocket
 	_, message, err := conn.ReadMessage()
 	if err != nil {
-		logger.Sugar().Error("Error reading WebSocket message:", err)
+		p.log().Sugar().Error("Error reading WebSocket message:", err)
 		return errors.Wrap(err, "error reading WebSocket message")
 	}
 
-	logger.Sugar().Info("Received WebSocket message:", string(message))
+	p.log().Sugar().Debug("Received WebSocket message:", string(message))
 
 	// Unmarshal the message to check the action type
 	var actionData ActionData
@@ -102,7 +116,7 @@ func (p *HOWENWS) ConsumeMessage(conn *websocket.Conn, dataStore store.Store) er
 		protoReply := alarmPacket.ToProtobufDeviceStatusAlarm()
 		asyncStore <- protoReply
 	default:
-		logger.Sugar().Infof("Unhandled action type: %s", actionData.Action)
+		p.log().Sugar().Debugf("Unhandled action type: %s", actionData.Action)
 	}
 
 	return nil
@@ -474,7 +474,7 @@ func parseToFloat32(value string) float32 {
 	}
 	f, err := strconv.ParseFloat(value, 32)
 	if err != nil {
-		logger.Sugar().Info("error parsing float32: %v\n", err)
+		logger.Sugar().Debug("error parsing float32: %v\n", err)
 		return 0
 	}
 	return float32(f)
@@ -483,7 +483,7 @@ func parseToFloat32(value string) float32 {
 func parseToInt32(value string) int32 {
 	i, err := strconv.Atoi(value)
 	if err != nil {
-		logger.Sugar().Info("error parsing int32: %v\n", err)
+		logger.Sugar().Debug("error parsing int32: %v\n", err)
 		return 0
 	}
 	return int32(i)
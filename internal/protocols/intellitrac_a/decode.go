@@ -0,0 +1,149 @@
+package intellitrac_a
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"github.com/404minds/avl-receiver/internal/decode"
+	"github.com/404minds/avl-receiver/internal/types"
+)
+
+// AsciiLogin is the FA F8 heartbeat an ascii device logs in with
+type AsciiLogin struct {
+	SequenceID uint16
+	ModemID    uint32
+}
+
+// AsciiLine is a comma separated line of an ascii device
+type AsciiLine struct {
+	Fields []string
+}
+
+type TextMessage struct {
+	TransactionID uint16
+	ModemID       uint64
+	Text          string
+}
+
+type ATResponse struct {
+	TransactionID uint16
+	Response      string
+}
+
+// DecodeFrame decodes the message at the start of data. The first heartbeat logs the device in,
+// its modem id is the imei of the statuses after it and an FA F8 one switches to ascii lines.
+func (t *IntelliTracAProtocol) DecodeFrame(data []byte) (*decode.Frame, error) {
+	if t.Imei != "" && !t.IsBinary {
+		return t.decodeAsciiLine(data)
+	}
+	if len(data) >= 2 && data[0] == 0xFA && data[1] == 0xF8 {
+		if len(data) < ASCIIHeartbeatSize {
+			return nil, decode.ErrIncomplete
+		}
+		login := &AsciiLogin{SequenceID: binary.BigEndian.Uint16(data[2:4]), ModemID: binary.BigEndian.Uint32(data[4:8])}
+		t.Imei = padIMEI(fmt.Sprintf("%d", login.ModemID))
+		t.IsBinary = false
+		return &decode.Frame{Kind: "ascii login", Packet: login, Size: ASCIIHeartbeatSize}, nil
+	}
+	if len(data) < PreambleSize {
+		return nil, decode.ErrIncomplete
+	}
+
+	transactionID := binary.BigEndian.Uint16(data[0:2])
+	switch encoding, msgType := data[2], data[3]; {
+	case encoding == MsgEncodingBinaryPos && msgType == MsgTypeAsync:
+		return t.decodeBinaryPosition(data, transactionID)
+	case encoding == MsgEncodingText && msgType == MsgTypeAsync:
+		// length, modem id, text and 12 bytes of timestamps
+		if len(data) < PreambleSize+10 {
+			return nil, decode.ErrIncomplete
+		}
+		textLen := int(binary.BigEndian.Uint16(data[4:6]))
+		size := PreambleSize + 10 + textLen + 12
+		if len(data) < size {
+			return nil, decode.ErrIncomplete
+		}
+		message := &TextMessage{
+			TransactionID: transactionID,
+			ModemID:       binary.BigEndian.Uint64(data[6:14]),
+			Text:          string(data[14 : 14+textLen]),
+		}
+		return &decode.Frame{Kind: "text message", Packet: message, Size: size}, nil
+	case encoding == MsgEncodingATCommand && msgType == MsgTypeResponse:
+		if len(data) < PreambleSize+2 {
+			return nil, decode.ErrIncomplete
+		}
+		size := PreambleSize + 2 + int(binary.BigEndian.Uint16(data[4:6]))
+		if len(data) < size {
+			return nil, decode.ErrIncomplete
+		}
+		response := &ATResponse{TransactionID: transactionID, Response: string(data[6:size])}
+		return &decode.Frame{
+			Kind:      "at response",
+			Packet:    response,
+			Responses: []*types.DeviceResponse{{Imei: t.Imei, Response: response.Response}},
+			Size:      size,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: encoding %#02x type %#02x", ErrUnsupportedMsgType, data[2], data[3])
+}
+
+// decodeBinaryPosition decodes a heartbeat or position, a heartbeat logs the device in if it isn't
+func (t *IntelliTracAProtocol) decodeBinaryPosition(data []byte, transactionID uint16) (*decode.Frame, error) {
+	if len(data) < PreambleSize+BinaryPositionHeaderSize {
+		return nil, decode.ErrIncomplete
+	}
+	header := data[PreambleSize : PreambleSize+BinaryPositionHeaderSize]
+	modemID := padIMEI(fmt.Sprintf("%d", binary.BigEndian.Uint64(header[0:8])))
+	messageID := binary.BigEndian.Uint16(header[8:10])
+	dataLen := binary.BigEndian.Uint16(header[10:12])
+	size := PreambleSize + BinaryPositionHeaderSize + int(dataLen)
+	if len(data) < size {
+		return nil, decode.ErrIncomplete
+	}
+	positionData := data[PreambleSize+BinaryPositionHeaderSize : size]
+
+	if messageID == 0xAB {
+		heartbeat := &Heartbeat{
+			TransactionID: transactionID,
+			ModemID:       binary.BigEndian.Uint64(header[0:8]),
+			MessageID:     messageID,
+			DataLength:    dataLen,
+		}
+		if dataLen >= 6 {
+			heartbeat.RTC = parseDateTime(positionData[3], positionData[4], positionData[5], positionData[0], positionData[1], positionData[2])
+		}
+		if t.Imei == "" {
+			t.Imei = modemID
+			t.IsBinary = true
+		}
+		return &decode.Frame{Kind: "heartbeat", Packet: heartbeat, Size: size}, nil
+	}
+	if dataLen < 46 {
+		return nil, fmt.Errorf("%w: %d bytes, at least 46 expected", ErrInvalidPositionData, dataLen)
+	}
+	position := parsePositionRecord(positionData, modemID, dataLen, messageID, transactionID)
+	return &decode.Frame{
+		Kind:     "position",
+		Packet:   position,
+		Statuses: []*types.DeviceStatus{position.ToDeviceStatus(t.Imei)},
+		Size:     size,
+	}, nil
+}
+
+// decodeAsciiLine decodes a line the way consumeASCIIStream does, only the imei goes in the status
+func (t *IntelliTracAProtocol) decodeAsciiLine(data []byte) (*decode.Frame, error) {
+	end := bytes.IndexByte(data, '\n')
+	if end < 0 {
+		return nil, decode.ErrIncomplete
+	}
+	line := &AsciiLine{Fields: strings.Split(strings.TrimSpace(string(data[:end])), ",")}
+	frame := &decode.Frame{Kind: "ascii line", Packet: line, Size: end + 1}
+	if len(line.Fields) >= 15 {
+		frame.Kind = "ascii position"
+		frame.Statuses = []*types.DeviceStatus{{Imei: t.Imei}}
+	}
+	return frame, nil
+}
@@ -47,7 +47,7 @@ func (t *IntelliTracAProtocol) Login(reader *bufio.Reader) ([]byte, int, error)
 	if err != nil {
 		return nil, 0, err
 	}
-	logger.Sugar().Infoln("peeked", peeked)
+	t.log().Sugar().Debugln("peeked", peeked)
 
 	var transactionID uint16
 	var modemID uint64
@@ -69,21 +69,21 @@ func (t *IntelliTracAProtocol) Login(reader *bufio.Reader) ([]byte, int, error)
 		messageID := binary.BigEndian.Uint16(data[12:14])
 		dataLength := binary.BigEndian.Uint16(data[14:16])
 
-		logger.Sugar().Infof("Login - Modem ID: %d, Message ID: 0x%04X, Data Length: %d",
+		t.log().Sugar().Debugf("Login - Modem ID: %d, Message ID: 0x%04X, Data Length: %d",
 			modemID, messageID, dataLength)
 
 		// Verify heartbeat message ID (0xAB)
 		if messageID != 0xAB {
-			logger.Sugar().Warnf("Expected heartbeat message ID 0xAB, got 0x%X", messageID)
+			t.log().Sugar().Warnf("Expected heartbeat message ID 0xAB, got 0x%X", messageID)
 		}
 
 		// Verify data length (should be 6 for RTC data)
 		if dataLength != 6 {
-			logger.Sugar().Warnf("Expected data length 6, got %d", dataLength)
+			t.log().Sugar().Warnf("Expected data length 6, got %d", dataLength)
 		}
 
 		t.IsBinary = true
-		logger.Sugar().Infof("Binary heartbeat - Transaction ID: %d, Modem ID: %d", transactionID, modemID)
+		t.log().Sugar().Debugf("Binary heartbeat - Transaction ID: %d, Modem ID: %d", transactionID, modemID)
 
 	} else if len(peeked) >= 2 && peeked[0] == 0xFA && peeked[1] == 0xF8 {
 		// ASCII heartbeat (page 11)
@@ -97,7 +97,7 @@ func (t *IntelliTracAProtocol) Login(reader *bufio.Reader) ([]byte, int, error)
 		transactionID = binary.BigEndian.Uint16(data[2:4])
 		modemID = uint64(binary.BigEndian.Uint32(data[4:8]))
 		t.IsBinary = false
-		logger.Sugar().Infof("ASCII heartbeat - Transaction ID: %d, Modem ID: %d", transactionID, modemID)
+		t.log().Sugar().Debugf("ASCII heartbeat - Transaction ID: %d, Modem ID: %d", transactionID, modemID)
 	} else {
 		return nil, 0, errs.ErrUnknownProtocol
 	}
@@ -168,7 +168,7 @@ func (t *IntelliTracAProtocol) handleBinaryPosition(reader *bufio.Reader, writer
 	messageID := binary.BigEndian.Uint16(header[8:10])
 	dataLen := binary.BigEndian.Uint16(header[10:12])
 
-	logger.Sugar().Infof("Position - Modem ID: %d, Message ID: 0x%04X, Data Length: %d",
+	t.log().Sugar().Debugf("Position - Modem ID: %d, Message ID: 0x%04X, Data Length: %d",
 		modemID, messageID, dataLen)
 
 	data := make([]byte, dataLen)
@@ -177,10 +177,10 @@ func (t *IntelliTracAProtocol) handleBinaryPosition(reader *bufio.Reader, writer
 	}
 
 	if messageID == 0xAB {
-		logger.Sugar().Debugw("Heartbeat received", "modem", modemID, "data_len", dataLen)
+		t.log().Sugar().Debugw("Heartbeat received", "modem", modemID, "data_len", dataLen)
 		// Consider actually processing heartbeats
 	} else if dataLen < 46 {
-		logger.Sugar().Warnf("Positional data too short (%d < 46), dropping", dataLen)
+		t.log().Sugar().Warnf("Positional data too short (%d < 46), dropping", dataLen)
 	} else {
 		t.handlePositionalData(data, modemID, dataLen, messageID, transactionID, store)
 	}
@@ -196,7 +196,8 @@ func (t *IntelliTracAProtocol) handleBinaryPosition(reader *bufio.Reader, writer
 	return err
 }
 
-func (t *IntelliTracAProtocol) handlePositionalData(data []byte, modemID string, dataLen uint16, messageID uint16, transactionID uint16, store store.Store) {
+// parsePositionRecord reads the 46 or more bytes of a binary position report
+func parsePositionRecord(data []byte, modemID string, dataLen uint16, messageID uint16, transactionID uint16) *PositionRecord {
 	position := &PositionRecord{
 		TransactionID: transactionID,
 		ModemID:       modemID,
@@ -267,8 +268,14 @@ func (t *IntelliTracAProtocol) handlePositionalData(data []byte, modemID string,
 
 	position.RawData = fmt.Sprintf("%v", data)
 
+	return position
+}
+
+func (t *IntelliTracAProtocol) handlePositionalData(data []byte, modemID string, dataLen uint16, messageID uint16, transactionID uint16, store store.Store) {
+	position := parsePositionRecord(data, modemID, dataLen, messageID, transactionID)
+
 	// after you’ve populated `position`…
-	logger.Sugar().Infow("position",
+	t.log().Sugar().Debugw("position",
 		// envelope
 		"transactionID", position.TransactionID,
 		"modemID", position.ModemID,
@@ -330,7 +337,7 @@ func (t *IntelliTracAProtocol) handlePositionalData(data []byte, modemID string,
 // }
 
 func (t *IntelliTracAProtocol) consumeASCIIStream(reader *bufio.Reader, writer io.Writer, store store.Store) error {
-	line, err := reader.ReadString('\n')
+	line, err := readLine(reader)
 	if err != nil {
 		return err
 	}
@@ -338,7 +345,7 @@ func (t *IntelliTracAProtocol) consumeASCIIStream(reader *bufio.Reader, writer i
 	line = strings.TrimSpace(line)
 	parts := strings.Split(line, ",")
 
-	logger.Sugar().Infoln("full ascii", line)
+	t.log().Sugar().Debugln("full ascii", line)
 
 	// Position message format (page 13)
 	if len(parts) >= 15 {
@@ -376,6 +383,22 @@ func (t *IntelliTracAProtocol) consumeASCIIStream(reader *bufio.Reader, writer i
 	return nil
 }
 
+// readLine reads a line of up to MaxASCIILineSize bytes, ReadString would keep buffering a line
+// that never ends
+func readLine(reader *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > MaxASCIILineSize {
+			return "", fmt.Errorf("%w: intellitrac ascii line longer than %d bytes", errs.ErrFrameTooLarge, MaxASCIILineSize)
+		}
+		if err != bufio.ErrBufferFull {
+			return string(line), err
+		}
+	}
+}
+
 func (t *IntelliTracAProtocol) isImeiAuthorized(imei string) bool {
 	// Implement your authorization logic here
 	return true
@@ -437,7 +460,7 @@ func (t *IntelliTracAProtocol) handleTextMessage(reader *bufio.Reader, writer io
 	}
 
 	// Log the text message
-	logger.Sugar().Infof("Received text message from device %s: %s", t.Imei, string(textData))
+	t.log().Sugar().Debugf("Received text message from device %s: %s", t.Imei, string(textData))
 
 	// Send acknowledgment
 	ack := make([]byte, BinaryAckSize)
@@ -467,11 +490,11 @@ func (t *IntelliTracAProtocol) handleATResponse(reader *bufio.Reader, writer io.
 	responseStr := string(responseData)
 	switch {
 	case strings.HasPrefix(responseStr, "OK"):
-		logger.Sugar().Debugf("Device %s acknowledged command", t.Imei)
+		t.log().Sugar().Debugf("Device %s acknowledged command", t.Imei)
 	case strings.HasPrefix(responseStr, "ERROR"):
-		logger.Sugar().Warnf("Device %s returned error: %s", t.Imei, responseStr)
+		t.log().Sugar().Warnf("Device %s returned error: %s", t.Imei, responseStr)
 	default:
-		logger.Sugar().Infof("Received AT response from device %s: %s", t.Imei, responseStr)
+		t.log().Sugar().Debugf("Received AT response from device %s: %s", t.Imei, responseStr)
 	}
 
 	// Send to response channel if needed
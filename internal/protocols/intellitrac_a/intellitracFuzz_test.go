@@ -0,0 +1,98 @@
+package intellitrac_a
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"go.uber.org/zap/zapcore"
+)
+
+// seeds from devices in the field, each a heartbeat logging in and what the device sends after it
+var fuzzSeeds = []string{
+	"0001000200030F49CC7CC4CB00AB00060A1E0018030F" + // binary heartbeat
+		"0002000200030F49CC7CC4CB0001002E0A1E0018030F0013CB0800766661000384006F02B1000000000909000103313800000A1E0018030F0A1E0018030F" + // position
+		"0004000200030F49CC7CC4CB00C7002E0A1E0018030F0013CB0800766661000384006F02B1000000000909000103313800000A1E0018030F0A1E0018030F" + // impact
+		"0005000200030F49CC7CC4CB00AB00060A1E0018030F",
+	"0001000200030F49CC7CC4CB00AB00060A1E0018030F" +
+		"00060202000500030F49CC7CC4CB48454C4C4F0A1E0018030F0A1E0018030F" + // text message
+		"0007010100024F4B", // at command response
+	"FAF8000121D950CB" + // ascii heartbeat
+		hex.EncodeToString([]byte("567890123,20240315103000,77.594566,12.971599,40,69,900,9,1,1,0,12.60,0.00,0,0.9\r\n")) +
+		hex.EncodeToString([]byte("567890123,20240315103001,77.594612,12.971645,42,69,901,9,199,1,0,12.60,0.00,0,0.9\r\n")),
+}
+
+// fuzzSetup adds the seeds and turns the protocol's logging off, it slows the fuzzer down a lot
+func fuzzSetup(f *testing.F) {
+	configuredLogger.SetLevel(zapcore.FatalLevel)
+	for _, seed := range fuzzSeeds {
+		data, err := hex.DecodeString(strings.ToLower(seed))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+}
+
+// fuzzStore takes what the protocol stores until the test is done
+func fuzzStore(t *testing.T) store.Store {
+	s := &store.JsonLinesStore{ProcessChan: make(chan *types.DeviceStatus), ResponseChan: make(chan *types.DeviceResponse)}
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	go func() {
+		for {
+			select {
+			case <-s.ProcessChan:
+			case <-s.ResponseChan:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return s
+}
+
+func FuzzLogin(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		protocol := &IntelliTracAProtocol{}
+		_, _, _ = protocol.Login(bufio.NewReader(bytes.NewReader(data)))
+	})
+}
+
+// FuzzConsumeStream logs in the way the tcp handler does, the heartbeat decides between the binary
+// and ascii streams
+func FuzzConsumeStream(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		protocol := &IntelliTracAProtocol{}
+		reader := bufio.NewReader(bytes.NewReader(data))
+		if _, _, err := protocol.Login(reader); err != nil {
+			return
+		}
+		_ = protocol.ConsumeStream(reader, io.Discard, fuzzStore(t))
+	})
+}
+
+func FuzzDecodeFrame(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		protocol := &IntelliTracAProtocol{}
+		for len(data) > 0 {
+			frame, err := protocol.DecodeFrame(data)
+			if err != nil {
+				return
+			}
+			if frame.Size <= 0 || frame.Size > len(data) {
+				t.Fatalf("frame of %d bytes out of %d", frame.Size, len(data))
+			}
+			data = data[frame.Size:]
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/404minds/avl-receiver/internal/types"
+	"go.uber.org/zap"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
@@ -14,6 +15,7 @@ const (
 	ASCIIHeartbeatSize       = 8
 	BinaryAckSize            = 6
 	BinaryPositionHeaderSize = 12
+	MaxASCIILineSize         = 1024 // a position line is under 200 bytes
 )
 
 // Message types
@@ -88,6 +90,19 @@ type IntelliTracAProtocol struct {
 	Imei       string
 	DeviceType types.DeviceType
 	IsBinary   bool
+
+	deviceLogger *zap.Logger
+}
+
+func (t *IntelliTracAProtocol) SetLogger(l *zap.Logger) {
+	t.deviceLogger = l
+}
+
+func (t *IntelliTracAProtocol) log() *zap.Logger {
+	if t.deviceLogger != nil {
+		return t.deviceLogger
+	}
+	return logger
 }
 
 func (p *PositionRecord) ToDeviceStatus(imei string) *types.DeviceStatus {
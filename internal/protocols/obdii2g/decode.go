@@ -0,0 +1,40 @@
+package obdii2g
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+
+	"github.com/404minds/avl-receiver/internal/decode"
+	"github.com/404minds/avl-receiver/internal/types"
+)
+
+// DecodeFrame decodes the line at the start of data the way ConsumeStream does, with the xor
+// checksum after the * that the receiver doesn't check
+func (a *AquilaOBDII2GProtocol) DecodeFrame(data []byte) (*decode.Frame, error) {
+	end := bytes.IndexByte(data, '\n')
+	if end < 0 {
+		return nil, decode.ErrIncomplete
+	}
+	raw := string(data[:end+1])
+	packet, err := ParsePacket(raw)
+	if err != nil {
+		return nil, err
+	}
+	if a.Imei == "" {
+		a.Imei = packet.IMEI
+	}
+	status, err := packet.ToProtobuf()
+	if err != nil {
+		return nil, err
+	}
+
+	frame := &decode.Frame{Kind: "message " + packet.MessageCode, Packet: packet, Statuses: []*types.DeviceStatus{status}, Size: end + 1}
+	if body, sent, ok := strings.Cut(strings.TrimSpace(raw), checksumSeparator); ok {
+		if b, err := hex.DecodeString(sent); err == nil && len(b) == 1 {
+			packet.Checksum = b[0]
+			frame.Checksum = &decode.Checksum{Name: "xor", Sent: uint32(b[0]), Calculated: uint32(calculateChecksum(body))}
+		}
+	}
+	return frame, nil
+}
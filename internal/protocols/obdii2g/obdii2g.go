@@ -21,6 +21,19 @@ var logger = configuredLogger.Logger
 type AquilaOBDII2GProtocol struct {
 	Imei       string
 	DeviceType types.DeviceType
+
+	deviceLogger *zap.Logger
+}
+
+func (a *AquilaOBDII2GProtocol) SetLogger(l *zap.Logger) {
+	a.deviceLogger = l
+}
+
+func (a *AquilaOBDII2GProtocol) log() *zap.Logger {
+	if a.deviceLogger != nil {
+		return a.deviceLogger
+	}
+	return logger
 }
 
 const (
@@ -48,7 +61,7 @@ func (a *AquilaOBDII2GProtocol) GetProtocolType() types.DeviceProtocolType {
 func (a *AquilaOBDII2GProtocol) Login(reader *bufio.Reader) ([]byte, int, error) {
 	// Peek first 2 bytes to verify header
 	header, err := reader.Peek(2)
-	logger.Sugar().Infoln(header) //  INFO    obdii2g/obdii2g.go:47   [36 36]
+	a.log().Sugar().Debugln(header) //  INFO    obdii2g/obdii2g.go:47   [36 36]
 	if err != nil {
 		return nil, 0, fmt.Errorf("header peek failed: %w", err)
 	}
@@ -95,26 +108,42 @@ func (a *AquilaOBDII2GProtocol) ConsumeStream(reader *bufio.Reader, writer io.Wr
 		select {
 		case <-ticker.C:
 			if err := a.setReadTimeout(writer, readTimeout); err != nil {
-				logger.Error("Failed to refresh read deadline", zap.Error(err))
+				a.log().Error("Failed to refresh read deadline", zap.Error(err))
 			}
 		default:
-			packet, err := reader.ReadString('\n')
+			packet, err := readLine(reader)
 			if err != nil {
 				if errors.Is(err, io.EOF) {
-					logger.Info("Connection closed gracefully")
+					a.log().Info("Connection closed gracefully")
 					return nil
 				}
 				return a.handleStreamError(err, reader)
 			}
 
 			if err := a.processPacket(packet, store); err != nil {
-				logger.Warn("Packet processing failed", zap.Error(err))
+				a.log().Warn("Packet processing failed", zap.Error(err))
 				continue
 			}
 		}
 	}
 }
 
+// readLine reads a line of up to maxPacketSize bytes, ReadString would keep buffering a line that
+// never ends
+func readLine(reader *bufio.Reader) (string, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > maxPacketSize {
+			return "", fmt.Errorf("%w: obdii2g line longer than %d bytes", errs.ErrFrameTooLarge, maxPacketSize)
+		}
+		if err != bufio.ErrBufferFull {
+			return string(line), err
+		}
+	}
+}
+
 func (a *AquilaOBDII2GProtocol) processPacket(raw string, store store.Store) error {
 	// Validate and parse packet
 	pkt, err := ParsePacket(raw)
@@ -132,7 +161,7 @@ func (a *AquilaOBDII2GProtocol) processPacket(raw string, store store.Store) err
 	select {
 	case store.GetProcessChan() <- status:
 	case <-time.After(100 * time.Millisecond):
-		logger.Warn("Dropping packet due to store buffer full")
+		a.log().Warn("Dropping packet due to store buffer full")
 	}
 
 	return nil
@@ -140,14 +169,14 @@ func (a *AquilaOBDII2GProtocol) processPacket(raw string, store store.Store) err
 
 func (a *AquilaOBDII2GProtocol) handleStreamError(err error, reader *bufio.Reader) error {
 	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
-		logger.Warn("Connection timeout, closing")
+		a.log().Warn("Connection timeout, closing")
 		return nil
 	}
 
 	// Log remaining data for debugging
 	if buf := reader.Buffered(); buf > 0 {
 		peeked, _ := reader.Peek(min(buf, 256))
-		logger.Debug("Remaining buffer content",
+		a.log().Debug("Remaining buffer content",
 			zap.ByteString("data", peeked),
 			zap.Int("bytes", buf))
 	}
@@ -156,7 +185,7 @@ func (a *AquilaOBDII2GProtocol) handleStreamError(err error, reader *bufio.Reade
 }
 
 func (a *AquilaOBDII2GProtocol) SendCommandToDevice(writer io.Writer, command string) error {
-	cmd := fmt.Sprintf("#%s\\r\\n", command)
+	cmd := fmt.Sprintf("#%s\r\n", command)
 	_, err := writer.Write([]byte(cmd))
 	return err
 }
@@ -0,0 +1,80 @@
+package obdii2g
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"testing"
+
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"go.uber.org/zap/zapcore"
+)
+
+// seeds from devices in the field, as the device sends them
+var fuzzSeeds = []string{
+	"$$CLIENT_1NS,861234567890123,15,28.613939,77.209023,240315103000,A,28,0,1520,0,11,0.9,0,0,0,0,12400,4100,0,0,1|010C:04410C0000|010D:03410D00*17\r\n", // login
+	"$$CLIENT_1NS,861234567890123,1,28.614501,77.210544,240315103130,A,27,42,1521,68,12,0.8,0,0,0,1,12380,4100,90,0,1|010C:04410C2260|010D:03410D2A|0105:03410582|012F:03412FA0*11\r\n",
+	"$$CLIENT_1NS,861234567890123,23,28.615003,77.211987,240315103145,A,25,38,1521,122,12,1.1,0,0,0,8,12380,4090,105,0,1|0104:03410480|010F:03410F46|011F:04411F0384|0133:03413365|0142:04414230D4|017F:037F0112*58\r\n", // harsh cornering, a negative obd response
+	"$$CLIENT_1NS,861234567890123,1,0.000000,0.000000,240315103200,V,9,0,1521,0,0,99.9,0,0,0,0,11900,3950,120,0,0*03\r\n",
+}
+
+// fuzzSetup adds the seeds and turns the protocol's logging off, it slows the fuzzer down a lot
+func fuzzSetup(f *testing.F) {
+	configuredLogger.SetLevel(zapcore.FatalLevel)
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+}
+
+// fuzzStore takes what the protocol stores until the test is done
+func fuzzStore(t *testing.T) store.Store {
+	s := &store.JsonLinesStore{ProcessChan: make(chan *types.DeviceStatus), ResponseChan: make(chan *types.DeviceResponse)}
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	go func() {
+		for {
+			select {
+			case <-s.ProcessChan:
+			case <-s.ResponseChan:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return s
+}
+
+func FuzzLogin(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		protocol := &AquilaOBDII2GProtocol{}
+		_, _, _ = protocol.Login(bufio.NewReader(bytes.NewReader(data)))
+	})
+}
+
+func FuzzConsumeStream(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		protocol := &AquilaOBDII2GProtocol{Imei: "861234567890123"}
+		_ = protocol.ConsumeStream(bufio.NewReader(bytes.NewReader(data)), io.Discard, fuzzStore(t))
+	})
+}
+
+func FuzzDecodeFrame(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		protocol := &AquilaOBDII2GProtocol{}
+		for len(data) > 0 {
+			frame, err := protocol.DecodeFrame(data)
+			if err != nil {
+				return
+			}
+			if frame.Size <= 0 || frame.Size > len(data) {
+				t.Fatalf("frame of %d bytes out of %d", frame.Size, len(data))
+			}
+			data = data[frame.Size:]
+		}
+	})
+}
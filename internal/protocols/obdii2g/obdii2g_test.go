@@ -0,0 +1,15 @@
+package obdii2g
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendCommandToDevice(t *testing.T) {
+	var written bytes.Buffer
+	require.NoError(t, (&AquilaOBDII2GProtocol{}).SendCommandToDevice(&written, "SET_INTERVAL,30"))
+	assert.Equal(t, []byte{'#', 'S', 'E', 'T', '_', 'I', 'N', 'T', 'E', 'R', 'V', 'A', 'L', ',', '3', '0', 0x0d, 0x0a}, written.Bytes())
+}
@@ -96,7 +96,7 @@ func (p *Packet) ToProtobuf() (*types.DeviceStatus, error) {
 		speed = obdSpeed
 	}
 
-	logger.Sugar().Infow("obdii2g_packet",
+	logger.Sugar().Debugw("obdii2g_packet",
 		// envelope
 		"imei", p.IMEI,
 		"message_code", p.MessageCode,
@@ -185,8 +185,7 @@ func (p *Packet) ToProtobuf() (*types.DeviceStatus, error) {
 func (p *Packet) parseVehicleStatus(messageCode string) *types.VehicleStatus {
 	vs := &types.VehicleStatus{}
 	flags := p.Vehicle.EventFlag
-	logger.Sugar().Infoln("flags", flags)
-	logger.Sugar().Infoln("messageCode", messageCode)
+	logger.Sugar().Debugw("vehicle status", "imei", p.IMEI, "flags", flags, "messageCode", messageCode)
 	vs.UnplugBattery = (flags & (1 << 2)) != 0
 	vs.OverSpeeding = (flags & (1 << 3)) != 0
 
@@ -296,7 +295,7 @@ func ParsePacket(raw string) (*Packet, error) {
 	pkt.Vehicle.ExternalBattery, _ = parseInt32(fields[17])
 	pkt.Vehicle.InternalBattery, _ = parseInt32(fields[18])
 	pkt.Vehicle.TripTime, _ = parseInt32(fields[19])
-	logger.Sugar().Infoln("fields[20]", fields[20])
+	logger.Sugar().Debugw("obd parameters", "imei", pkt.IMEI, "field", fields[20])
 	// ── OBD Parameters ────────────────────────────────────
 	pkt.OBD = make(map[string]OBDParameter)
 	// The last CSV field before '*' is something like "1|PID:HEX|PID:HEX|…"
@@ -418,6 +417,10 @@ func (o *OBDParameter) parseValue(pidCode string) {
 
 		}
 	case pidFuelLevelInput, pidWarmupsSinceClear, pidBarometricPressure:
+		if len(data) < 1 {
+			o.Valid = false
+			return
+		}
 		switch pidCode {
 		case pidFuelLevelInput:
 			o.Parsed = float64(data[0]) * 100 / 255
@@ -14,6 +14,7 @@ import (
 	"github.com/404minds/avl-receiver/internal/store"
 
 	"github.com/404minds/avl-receiver/internal/types"
+	"go.uber.org/zap"
 )
 
 var logger = configuredLogger.Logger
@@ -26,6 +27,9 @@ type DeviceProtocol interface {
 	Login(*bufio.Reader) ([]byte, int, error)
 	ConsumeStream(*bufio.Reader, io.Writer, store.Store) error
 	SendCommandToDevice(writer io.Writer, command string) error
+	// SetLogger is the logger the parser uses once the device logged in, with its imei so
+	// tracing the device covers the parser's logs
+	SetLogger(*zap.Logger)
 }
 
 func MakeProtocolForType(t types.DeviceProtocolType) DeviceProtocol {
@@ -34,27 +38,27 @@ func MakeProtocolForType(t types.DeviceProtocolType) DeviceProtocol {
 		return &fm1200.FM1200Protocol{}
 
 	case types.DeviceProtocolType_GT06:
-		logger.Sugar().Info("GT06 called the protocol is: ", &gt06.GT06Protocol{DeviceType: types.DeviceType_CONCOX})
+		logger.Sugar().Debug("GT06 called the protocol is: ", &gt06.GT06Protocol{DeviceType: types.DeviceType_CONCOX})
 		return &gt06.GT06Protocol{DeviceType: types.DeviceType_CONCOX}
 
 	case types.DeviceProtocolType_OBDII2G:
-		logger.Sugar().Info("OBDII2G called the protocol is: ", &obdii2g.AquilaOBDII2GProtocol{DeviceType: types.DeviceType_AQUILA})
+		logger.Sugar().Debug("OBDII2G called the protocol is: ", &obdii2g.AquilaOBDII2GProtocol{DeviceType: types.DeviceType_AQUILA})
 		return &obdii2g.AquilaOBDII2GProtocol{DeviceType: types.DeviceType_AQUILA}
 
 	case types.DeviceProtocolType_INTELLITRAC_A:
-		logger.Sugar().Info("INTELLITRAC called the protocol is: ", &intellitrac_a.IntelliTracAProtocol{DeviceType: types.DeviceType_INTELLITRAC})
+		logger.Sugar().Debug("INTELLITRAC called the protocol is: ", &intellitrac_a.IntelliTracAProtocol{DeviceType: types.DeviceType_INTELLITRAC})
 		return &intellitrac_a.IntelliTracAProtocol{DeviceType: types.DeviceType_INTELLITRAC}
 
 	case types.DeviceProtocolType_TR06:
-		logger.Sugar().Info("TR06 called the protocol is: ", &tr06.TR06Protocol{DeviceType: types.DeviceType_WANWAY})
+		logger.Sugar().Debug("TR06 called the protocol is: ", &tr06.TR06Protocol{DeviceType: types.DeviceType_WANWAY})
 		return &tr06.TR06Protocol{DeviceType: types.DeviceType_WANWAY}
 
 	case types.DeviceProtocolType_HOWENWS:
-		logger.Sugar().Info("Howen called the protocol is: ", &howen.HOWENWS{DeviceType: types.DeviceType_HOWEN})
+		logger.Sugar().Debug("Howen called the protocol is: ", &howen.HOWENWS{DeviceType: types.DeviceType_HOWEN})
 		return &howen.HOWENWS{DeviceType: types.DeviceType_HOWEN}
 	default:
 
-		logger.Sugar().Info("MakeProtocolForType: ", t)
+		logger.Sugar().Debug("MakeProtocolForType: ", t)
 		return nil
 	}
 
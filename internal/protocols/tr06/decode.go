@@ -0,0 +1,76 @@
+package tr06
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/404minds/avl-receiver/internal/crc"
+	"github.com/404minds/avl-receiver/internal/decode"
+	errs "github.com/404minds/avl-receiver/internal/errors"
+	"github.com/404minds/avl-receiver/internal/types"
+)
+
+// DecodeFrame decodes the packet at the start of data, the login's imei is kept for the packets
+// after it. A packet with a bad crc is still decoded, the crc is reported in the frame.
+func (p *TR06Protocol) DecodeFrame(data []byte) (*decode.Frame, error) {
+	if len(data) < 2 {
+		return nil, decode.ErrIncomplete
+	}
+	var size, lengthSize int
+	switch binary.BigEndian.Uint16(data) {
+	case 0x7878:
+		if len(data) < 3 {
+			return nil, decode.ErrIncomplete
+		}
+		size, lengthSize = 3+int(data[2])+2, 1
+	case StartBitValue:
+		if len(data) < 4 {
+			return nil, decode.ErrIncomplete
+		}
+		length := binary.BigEndian.Uint16(data[2:])
+		if length > MaxPacketLength {
+			return nil, fmt.Errorf("%w: tr06 packet length %d", errs.ErrFrameTooLarge, length)
+		}
+		size, lengthSize = 4+int(length)+2, 2
+	default:
+		return nil, fmt.Errorf("%w: invalid start bit %#04x", errs.ErrGT06BadDataPacket, binary.BigEndian.Uint16(data))
+	}
+	if size < 9+lengthSize {
+		return nil, fmt.Errorf("%w: packet length too short", errs.ErrGT06BadDataPacket)
+	}
+	if len(data) < size {
+		return nil, decode.ErrIncomplete
+	}
+
+	checksum := &decode.Checksum{
+		Name:       "crc16",
+		Sent:       uint32(binary.BigEndian.Uint16(data[size-4:])),
+		Calculated: uint32(crc.CrcWanway(data[2 : size-4])), // length to serial number
+	}
+	// parsePacket rejects a bad crc, so it's given the right one to get at the rest
+	packetData := bytes.Clone(data[:size])
+	binary.BigEndian.PutUint16(packetData[size-4:], uint16(checksum.Calculated))
+	packet, err := p.parsePacket(bufio.NewReader(bytes.NewReader(packetData)))
+	if err != nil {
+		return nil, err
+	}
+	packet.Crc = uint16(checksum.Sent)
+
+	frame := &decode.Frame{Kind: packet.MessageType.String(), Packet: packet, Checksum: checksum, Size: size}
+	if login, ok := packet.Information.(*LoginData); ok {
+		p.LoginInformation = login
+		return frame, nil
+	}
+	imei := ""
+	if p.LoginInformation != nil {
+		imei = p.LoginInformation.TerminalID
+	}
+	if reply, ok := packet.Information.(*CommandReply); ok {
+		frame.Responses = []*types.DeviceResponse{{Imei: imei, Response: reply.Content}}
+		return frame, nil
+	}
+	frame.Statuses = []*types.DeviceStatus{packet.ToProtobufDeviceStatus(imei, p.DeviceType)}
+	return frame, nil
+}
@@ -7,8 +7,10 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
+	"runtime"
 	"slices"
 	"time"
+	"unicode/utf16"
 
 	"github.com/404minds/avl-receiver/internal/store"
 	"go.uber.org/zap"
@@ -25,16 +27,29 @@ var logger = configuredLogger.Logger
 type TR06Protocol struct {
 	LoginInformation *LoginData
 	DeviceType       types.DeviceType
+
+	deviceLogger *zap.Logger
+}
+
+func (p *TR06Protocol) SetLogger(l *zap.Logger) {
+	p.deviceLogger = l
+}
+
+func (p *TR06Protocol) log() *zap.Logger {
+	if p.deviceLogger != nil {
+		return p.deviceLogger
+	}
+	return logger
 }
 
 func (p *TR06Protocol) GetDeviceID() string {
 	if p.LoginInformation == nil {
-		logger.Error("LoginInformation is nil in GetDeviceID")
+		p.log().Error("LoginInformation is nil in GetDeviceID")
 		return ""
 	}
 
 	if p.LoginInformation.TerminalID == "" {
-		logger.Error("Login Information does not have TerminalID in GetDeviceID")
+		p.log().Error("Login Information does not have TerminalID in GetDeviceID")
 	}
 
 	return p.LoginInformation.TerminalID
@@ -58,44 +73,44 @@ func (p *TR06Protocol) Login(reader *bufio.Reader) (ack []byte, byteToSkip int,
 	}
 
 	data, _ := reader.Peek(reader.Buffered())
-	logger.Sugar().Info("Available data before reading IMEI: ", data)
+	p.log().Sugar().Debug("Available data before reading IMEI: ", data)
 
 	// This should have been a GT06 device
 	packet, err := p.parsePacket(reader)
 	if err != nil {
-		logger.Error("failed to parse GT06 packet", zap.Error(err))
+		p.log().Error("failed to parse GT06 packet", zap.Error(err))
 		return nil, 0, err
 	}
 
 	if packet.MessageType == MSG_LoginData {
 		if packet.Information == nil {
-			logger.Error("packet information is nil", zap.Error(errs.ErrGT06InvalidLoginInfo))
+			p.log().Error("packet information is nil", zap.Error(errs.ErrGT06InvalidLoginInfo))
 			return nil, 0, errs.ErrGT06InvalidLoginInfo
 		}
 
 		loginData, ok := packet.Information.(*LoginData)
 		if !ok {
-			logger.Error("packet information is not of type *LoginData", zap.Error(errs.ErrGT06InvalidLoginInfo))
+			p.log().Error("packet information is not of type *LoginData", zap.Error(errs.ErrGT06InvalidLoginInfo))
 			return nil, 0, errs.ErrGT06InvalidLoginInfo
 		}
 
 		if loginData == nil {
-			logger.Error("loginData is nil", zap.Error(errs.ErrGT06InvalidLoginInfo))
+			p.log().Error("loginData is nil", zap.Error(errs.ErrGT06InvalidLoginInfo))
 			return nil, 0, errs.ErrGT06InvalidLoginInfo
 		}
-		logger.Sugar().Info("from Login LoginData: ", loginData)
+		p.log().Sugar().Debug("from Login LoginData: ", loginData)
 		p.LoginInformation = loginData
 
 		byteBuffer := bytes.NewBuffer([]byte{})
 		err = p.sendResponse(packet, byteBuffer)
 		if err != nil {
-			logger.Error("failed to send response for GT06 packet", zap.Error(err))
+			p.log().Error("failed to send response for GT06 packet", zap.Error(err))
 			return nil, 0, err
 		}
 
 		return byteBuffer.Bytes(), 0, nil // nothing to skip since the stream is already consumed
 	} else {
-		logger.Error("packet message type is not MSG_LoginData", zap.Error(errs.ErrGT06InvalidLoginInfo))
+		p.log().Error("packet message type is not MSG_LoginData", zap.Error(errs.ErrGT06InvalidLoginInfo))
 		return nil, 0, errs.ErrGT06InvalidLoginInfo
 	}
 }
@@ -105,17 +120,27 @@ func (p *TR06Protocol) ConsumeStream(reader *bufio.Reader, writer io.Writer, dat
 
 		packet, err := p.parsePacket(reader)
 		if err != nil {
-			logger.Sugar().Info("Consume Stream :", err)
+			p.log().Sugar().Debug("Consume Stream :", err)
 			return err
 		}
 		if packet.MessageType == MSG_HeartbeatData {
 			err = p.sendResponse(packet, writer)
 			if err != nil {
-				logger.Sugar().Info("error while sending response", err)
+				p.log().Sugar().Debug("error while sending response", err)
 				return err
 			}
 		}
 
+		if reply, ok := packet.Information.(*CommandReply); ok {
+			err = p.sendResponse(packet, writer)
+			if err != nil {
+				p.log().Sugar().Debug("error while sending response", err)
+				return err
+			}
+			dataStore.GetResponseChan() <- &types.DeviceResponse{Imei: p.GetDeviceID(), Response: reply.Content}
+			continue
+		}
+
 		asyncStore := dataStore.GetProcessChan()
 		protoPacket := packet.ToProtobufDeviceStatus(p.GetDeviceID(), p.DeviceType)
 		asyncStore <- protoPacket
@@ -126,7 +151,7 @@ func (p *TR06Protocol) sendResponse(parsedPacket *Packet, writer io.Writer) erro
 	defer func() {
 		if condition := recover(); condition != nil {
 			err := fmt.Errorf("panic: %v", condition)
-			logger.Error("Failed to write response packet", zap.Error(err))
+			p.log().Error("Failed to write response packet", zap.Error(err))
 		}
 	}()
 
@@ -140,7 +165,7 @@ func (p *TR06Protocol) sendResponse(parsedPacket *Packet, writer io.Writer) erro
 
 	responsePacket.Crc = crc.CrcWanway(responsePacket.ToBytes()[2:6])
 
-	logger.Sugar().Info("Sending response packet: ", responsePacket.ToBytes())
+	p.log().Sugar().Debug("Sending response packet: ", responsePacket.ToBytes())
 	_, err := writer.Write(responsePacket.ToBytes())
 	if err != nil {
 		return errors.Wrapf(err, "failed to write response packet")
@@ -151,16 +176,12 @@ func (p *TR06Protocol) sendResponse(parsedPacket *Packet, writer io.Writer) erro
 func (p *TR06Protocol) parsePacket(reader *bufio.Reader) (packet *Packet, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			if rErr, ok := r.(error); ok {
-				err = rErr
-			} else {
-				err = fmt.Errorf("parse packet unknown panic: %v", r)
-			}
-			if err != io.EOF {
+			err = recovered(r)
+			if err != io.EOF && !errors.Is(err, errs.ErrParserPanic) {
 				err = errors.Wrapf(errs.ErrGT06BadDataPacket, "from parsePAcket")
-				logger.Sugar().Info("parse packet 0 ", err)
+				p.log().Sugar().Debug("parse packet 0 ", err)
 			}
-			logger.Sugar().Errorf("parse packet Recovered from panic: %v", err)
+			p.log().Sugar().Errorf("parse packet Recovered from panic: %v", err)
 		}
 	}()
 
@@ -168,89 +189,102 @@ func (p *TR06Protocol) parsePacket(reader *bufio.Reader) (packet *Packet, err er
 
 	// Start bit
 	err = binary.Read(reader, binary.BigEndian, &packet.StartBit)
-	logger.Sugar().Infof("parse packet Start bit: %x", packet.StartBit)
+	p.log().Sugar().Debugf("parse packet Start bit: %x", packet.StartBit)
 	if err != nil {
-		logger.Sugar().Errorf("parse packet Failed to read start bit: %v", err)
+		p.log().Sugar().Errorf("parse packet Failed to read start bit: %v", err)
 		return nil, err
 	}
 
 	// Determine packet length based on start bit
 	if packet.StartBit == 0x7979 {
-		//var packetLength uint16
-		//err = binary.Read(reader, binary.BigEndian, &packetLength)
-		//if err != nil {
-		//	logger.Sugar().Errorf("parse packet Failed to read packet length: %v", err)
-		//	return nil, err
-		//}
-		//packet.PacketLength = (packetLength)
-		//logger.Sugar().Infof("parse packet Packet length: %d", packet.PacketLength)
+		// the string information replies to commands come with the long header
+		var packetLength uint16
+		err = binary.Read(reader, binary.BigEndian, &packetLength)
+		if err != nil {
+			p.log().Sugar().Errorf("parse packet Failed to read packet length: %v", err)
+			return nil, err
+		}
+		if packetLength > MaxPacketLength {
+			return nil, errors.Wrapf(errs.ErrFrameTooLarge, "tr06 packet length %d", packetLength)
+		}
+		packet.PacketLength = packetLength
+		p.log().Sugar().Debugf("parse packet Packet length: %d", packet.PacketLength)
 
 	} else if packet.StartBit == 0x7878 {
 		var packetLength byte
 		err = binary.Read(reader, binary.BigEndian, &packetLength)
 		if err != nil {
-			logger.Sugar().Errorf("parse packet Failed to read packet length: %v", err)
+			p.log().Sugar().Errorf("parse packet Failed to read packet length: %v", err)
 			return nil, err
 		}
-		packet.PacketLength = packetLength
-		logger.Sugar().Infof("parse packet Packet length: %d", packet.PacketLength)
+		packet.PacketLength = uint16(packetLength)
+		p.log().Sugar().Debugf("parse packet Packet length: %d", packet.PacketLength)
 	} else {
 		return nil, errors.Wrapf(errs.ErrGT06BadDataPacket, "from parsePacket Invalid StartBit packet.StartBit: %d", packet.StartBit) // Invalid start bit
 	}
 
+	// protocol number, serial number and crc at least, a shorter length would wrap around below
+	if packet.PacketLength < 5 {
+		return nil, errors.Wrapf(errs.ErrTR06BadDataPacket, "from parsePacket packet length %d", packet.PacketLength)
+	}
+
 	// Packet data
 	packetData := make([]byte, packet.PacketLength-4) // 2 for CRC, 2 for serial number
-	logger.Sugar().Infof("parse packet packet data after removing 2 for CRC, 2 for serial number: %x", packetData)
+	p.log().Sugar().Debugf("parse packet packet data after removing 2 for CRC, 2 for serial number: %x", packetData)
 
 	_, err = io.ReadFull(reader, packetData)
 	if err != nil {
-		logger.Sugar().Errorf("parse packet Failed to read packet data: %v", err)
+		p.log().Sugar().Errorf("parse packet Failed to read packet data: %v", err)
 		return nil, err
 	}
-	logger.Sugar().Infof("parse packet Packet data: %x", packetData)
+	p.log().Sugar().Debugf("parse packet Packet data: %x", packetData)
 
 	// Packet data to packet
-	logger.Sugar().Info("Parse packet: ", packetData)
+	p.log().Sugar().Debug("Parse packet: ", packetData)
 	err = p.parsePacketData(bufio.NewReader(bytes.NewReader(packetData)), packet)
 	if err != nil {
-		logger.Sugar().Errorf("parse packet Failed to parse packet data: %v", err)
+		p.log().Sugar().Errorf("parse packet Failed to parse packet data: %v", err)
 		return nil, err
 	}
 
 	// Information serial number
 	err = binary.Read(reader, binary.BigEndian, &packet.InformationSerialNumber)
 	if err != nil {
-		logger.Sugar().Errorf("parse packet Failed to read information serial number: %v", err)
+		p.log().Sugar().Errorf("parse packet Failed to read information serial number: %v", err)
 		return nil, err
 	}
-	logger.Sugar().Infof("parse packet Information serial number: %x", packet.InformationSerialNumber)
+	p.log().Sugar().Debugf("parse packet Information serial number: %x", packet.InformationSerialNumber)
 
 	// CRC
 	err = binary.Read(reader, binary.BigEndian, &packet.Crc)
 	if err != nil {
-		logger.Sugar().Errorf("parse packet Failed to read CRC: %v", err)
+		p.log().Sugar().Errorf("parse packet Failed to read CRC: %v", err)
 		return nil, err
 	}
-	logger.Sugar().Infof("parse packet CRC: %x", packet.Crc)
+	p.log().Sugar().Debugf("parse packet CRC: %x", packet.Crc)
 
 	// Stop bits
 	err = binary.Read(reader, binary.BigEndian, &packet.StopBits)
 	if err != nil {
-		logger.Sugar().Errorf("parse packet Failed to read stop bits: %v", err)
+		p.log().Sugar().Errorf("parse packet Failed to read stop bits: %v", err)
 		return nil, err
 	}
-	logger.Sugar().Infof("parse packet Stop bits: %x", packet.StopBits)
+	p.log().Sugar().Debugf("parse packet Stop bits: %x", packet.StopBits)
 
 	if packet.StopBits != 0x0d0a {
 		err = errors.Wrapf(errs.ErrGT06BadDataPacket, "from parsePacket 3")
-		logger.Sugar().Errorf("parse packet Invalid stop bits: %x  parse packet 1 ERRTRO6 %v", packet.StopBits, err)
+		p.log().Sugar().Errorf("parse packet Invalid stop bits: %x  parse packet 1 ERRTRO6 %v", packet.StopBits, err)
 		return nil, err
 	}
 
 	//Validate CRC
+	lengthBytes := []byte{byte(packet.PacketLength)}
+	if packet.StartBit == 0x7979 {
+		lengthBytes = []byte{byte(packet.PacketLength >> 8), byte(packet.PacketLength)}
+	}
 	expectedCrc := crc.CrcWanway(
 		slices.Concat(
-			[]byte{byte(packet.PacketLength)},
+			lengthBytes,
 			packetData,
 			[]byte{
 				byte(packet.InformationSerialNumber >> 8),
@@ -259,7 +293,7 @@ func (p *TR06Protocol) parsePacket(reader *bufio.Reader) (packet *Packet, err er
 		),
 	)
 	if expectedCrc != packet.Crc {
-		logger.Sugar().Errorf("parse packet Invalid CRC. Expected %x, got %x", expectedCrc, packet.Crc)
+		p.log().Sugar().Errorf("parse packet Invalid CRC. Expected %x, got %x", expectedCrc, packet.Crc)
 		return nil, errs.ErrBadCrc
 	}
 
@@ -269,19 +303,19 @@ func (p *TR06Protocol) parsePacket(reader *bufio.Reader) (packet *Packet, err er
 func (p *TR06Protocol) parsePacketData(reader *bufio.Reader, packet *Packet) error {
 
 	protocolNumByte, err := reader.ReadByte()
-	logger.Sugar().Info("parsePacketData protocol number byte: ", protocolNumByte)
+	p.log().Sugar().Debug("parsePacketData protocol number byte: ", protocolNumByte)
 
 	msgType := MessageType(protocolNumByte)
-	logger.Sugar().Info("message type ", msgType)
+	p.log().Sugar().Debug("message type ", msgType)
 
 	if msgType == MSG_Invalid {
-		logger.Sugar().Errorf("Invalid message type: %x", protocolNumByte)
+		p.log().Sugar().Errorf("Invalid message type: %x", protocolNumByte)
 		remainingData, err := p.consumePacket(reader)
 		if err != nil {
 			return err
 		}
-		logger.Sugar().Errorln("Invalid message type: ", hex.Dump(remainingData))
-		logger.Sugar().Info("error from parsePacketData ", err)
+		p.log().Sugar().Errorln("Invalid message type: ", hex.Dump(remainingData))
+		p.log().Sugar().Debug("error from parsePacketData ", err)
 		return errors.Wrapf(errs.ErrGT06BadDataPacket, "from parsePacketData")
 	}
 
@@ -325,7 +359,7 @@ func (p *TR06Protocol) parsePacketInformation(reader *bufio.Reader, messageType
 		return parsedInfo, err
 	} else if messageType == MSG_HeartbeatData {
 		parsedInfo, err := p.parseHeartbeatData(reader)
-		logger.Sugar().Info("parsePacketInformation error: ", err)
+		p.log().Sugar().Debug("parsePacketInformation error: ", err)
 		return parsedInfo, err
 	} else if messageType == MSG_EG_HeartbeatData {
 		parsedInfo, err := p.parseHeartbeatData(reader)
@@ -333,8 +367,11 @@ func (p *TR06Protocol) parsePacketInformation(reader *bufio.Reader, messageType
 	} else if messageType == MSG_TransmissionInstruction {
 		parsedInfo, err := p.parseInformationTransmissionPacket(reader)
 		return parsedInfo, err
+	} else if messageType == MSG_StringInformation {
+		parsedInfo, err := p.parseCommandReply(reader)
+		return parsedInfo, err
 	} else {
-		logger.Sugar().Info("error from parsePacketInformation")
+		p.log().Sugar().Debug("error from parsePacketInformation")
 		return nil, errors.Wrapf(errs.ErrGT06BadDataPacket, "from parsePAcketInformation")
 	}
 }
@@ -345,21 +382,21 @@ func (p *TR06Protocol) parseLoginInformation(reader *bufio.Reader) (interface{},
 	var imeiBytes [8]byte
 	err := binary.Read(reader, binary.BigEndian, &imeiBytes)
 	if err != nil {
-		logger.Error("failed to read IMEI bytes", zap.Error(err))
+		p.log().Error("failed to read IMEI bytes", zap.Error(err))
 		return nil, errs.ErrGT06InvalidLoginInfo
 	}
-	logger.Sugar().Info("parseLoginInformation imeiBytes: ", imeiBytes[:])
+	p.log().Sugar().Debug("parseLoginInformation imeiBytes: ", imeiBytes[:])
 	loginInfo.TerminalID = hex.EncodeToString(imeiBytes[:])[1:] // IMEI is 15 chars
-	logger.Sugar().Info("parseLoginInformation loginInfo.TerminalID: ", loginInfo.TerminalID)
-	logger.Sugar().Info("parseLoginInformation loginInfo: ", loginInfo)
+	p.log().Sugar().Debug("parseLoginInformation loginInfo.TerminalID: ", loginInfo.TerminalID)
+	p.log().Sugar().Debug("parseLoginInformation loginInfo: ", loginInfo)
 	return &loginInfo, nil
 }
 func (p *TR06Protocol) parsePositioningData(reader *bufio.Reader) (positionInfo interface{}, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			err = r.(error)
-			if err != io.EOF {
-				logger.Sugar().Info("from parsePositioningData err: ", err)
+			err = recovered(r)
+			if err != io.EOF && !errors.Is(err, errs.ErrParserPanic) {
+				p.log().Sugar().Debug("from parsePositioningData err: ", err)
 				err = errors.Wrapf(errs.ErrGT06BadDataPacket, "from parsePositioningData")
 			}
 		}
@@ -370,14 +407,14 @@ func (p *TR06Protocol) parsePositioningData(reader *bufio.Reader) (positionInfo
 	// Date Time
 	timestamp, err := p.parseTimestamp(reader)
 	if err != nil {
-		logger.Sugar().Errorf("parsePositioningData failed to parse timestamp: %v", err)
+		p.log().Sugar().Errorf("parsePositioningData failed to parse timestamp: %v", err)
 		return nil, errors.Wrap(err, "failed to parse timestamp")
 	}
 	parsed.GpsInformation.Timestamp = timestamp
 	// Quantity of GPS information and number of satellites
 	var gpsInfo byte
 	checkErr(binary.Read(reader, binary.BigEndian, &gpsInfo))
-	logger.Sugar().Infof("parsePositioningData GPS info: %x", gpsInfo)
+	p.log().Sugar().Debugf("parsePositioningData GPS info: %x", gpsInfo)
 	parsed.GpsInformation.GPSInfoLength = gpsInfo >> 4
 	parsed.GpsInformation.NumberOfSatellites = gpsInfo & 0x0F
 
@@ -385,39 +422,39 @@ func (p *TR06Protocol) parsePositioningData(reader *bufio.Reader) (positionInfo
 	var latitude uint32
 	checkErr(binary.Read(reader, binary.BigEndian, &latitude))
 	parsed.GpsInformation.Latitude = float32(latitude) / 30000 / 60
-	logger.Sugar().Infof("parsePositioningData Latitude: %x", latitude)
+	p.log().Sugar().Debugf("parsePositioningData Latitude: %x", latitude)
 
 	// Longitude
 	var longitude uint32
 	checkErr(binary.Read(reader, binary.BigEndian, &longitude))
 	parsed.GpsInformation.Longitude = float32(longitude) / 30000 / 60
-	logger.Sugar().Infof("parsePositioningData Longitude: %x", longitude)
+	p.log().Sugar().Debugf("parsePositioningData Longitude: %x", longitude)
 
 	// Speed
 	checkErr(binary.Read(reader, binary.BigEndian, &parsed.GpsInformation.Speed))
-	logger.Sugar().Infof("parsePositioningData Speed: %x", parsed.GpsInformation.Speed)
+	p.log().Sugar().Debugf("parsePositioningData Speed: %x", parsed.GpsInformation.Speed)
 
 	// Course and Status
 	var courseAndStatus [2]byte
 	checkErr(binary.Read(reader, binary.BigEndian, &courseAndStatus))
-	logger.Sugar().Infof("parsePositioningData Course and Status: %x", courseAndStatus)
+	p.log().Sugar().Debugf("parsePositioningData Course and Status: %x", courseAndStatus)
 	parsed.GpsInformation.Course = parseCourseAndStatus(courseAndStatus)
 
 	// MCC
 	checkErr(binary.Read(reader, binary.BigEndian, &parsed.LBSInfo.MCC))
-	logger.Sugar().Infof("parsePositioningData MCC: %x", parsed.LBSInfo.MCC)
+	p.log().Sugar().Debugf("parsePositioningData MCC: %x", parsed.LBSInfo.MCC)
 
 	// MNC
 	checkErr(binary.Read(reader, binary.BigEndian, &parsed.LBSInfo.MNC))
-	logger.Sugar().Infof("parsePositioningData MNC: %x", parsed.LBSInfo.MNC)
+	p.log().Sugar().Debugf("parsePositioningData MNC: %x", parsed.LBSInfo.MNC)
 
 	// LAC
 	checkErr(binary.Read(reader, binary.BigEndian, &parsed.LBSInfo.LAC))
-	logger.Sugar().Infof("parsePositioningData LAC: %x", parsed.LBSInfo.LAC)
+	p.log().Sugar().Debugf("parsePositioningData LAC: %x", parsed.LBSInfo.LAC)
 
 	// Cell ID
 	checkErr(binary.Read(reader, binary.BigEndian, &parsed.LBSInfo.CellID))
-	logger.Sugar().Infof("parsePositioningData Cell ID: %x", parsed.LBSInfo.CellID)
+	p.log().Sugar().Debugf("parsePositioningData Cell ID: %x", parsed.LBSInfo.CellID)
 
 	return &parsed, nil
 }
@@ -436,9 +473,9 @@ func parseCourseAndStatus(courseAndStatus [2]byte) GPSCourse {
 func (p *TR06Protocol) parseAlarmData(reader *bufio.Reader) (alarmInfo AlarmInformation, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			err = r.(error)
-			if err != io.EOF {
-				logger.Sugar().Info("error from parseAlarmData err: ", err)
+			err = recovered(r)
+			if err != io.EOF && !errors.Is(err, errs.ErrParserPanic) {
+				p.log().Sugar().Debug("error from parseAlarmData err: ", err)
 				err = errors.Wrapf(errs.ErrGT06BadDataPacket, "from parseAlarmData")
 			}
 		}
@@ -459,9 +496,9 @@ func (p *TR06Protocol) parseAlarmData(reader *bufio.Reader) (alarmInfo AlarmInfo
 func (p *TR06Protocol) parseHeartbeatData(reader *bufio.Reader) (heartbeat HeartbeatData, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			err = r.(error)
-			if err != io.EOF {
-				logger.Sugar().Info("error from parseHeartbeatData 1 err: ", err)
+			err = recovered(r)
+			if err != io.EOF && !errors.Is(err, errs.ErrParserPanic) {
+				p.log().Sugar().Debug("error from parseHeartbeatData 1 err: ", err)
 				err = errors.Wrapf(errs.ErrGT06BadDataPacket, "from parseHeartbeatData")
 			}
 		}
@@ -471,7 +508,7 @@ func (p *TR06Protocol) parseHeartbeatData(reader *bufio.Reader) (heartbeat Heart
 	if err := binary.Read(reader, binary.BigEndian, &terminalInfoByte); err != nil {
 		return heartbeat, err
 	}
-	logger.Sugar().Infof("parseHeartbeatData Terminal Info Byte: %x", terminalInfoByte)
+	p.log().Sugar().Debugf("parseHeartbeatData Terminal Info Byte: %x", terminalInfoByte)
 	heartbeat.TerminalInformation, err = p.parseTerminalInfoFromByte(terminalInfoByte)
 	if err != nil {
 		return heartbeat, err
@@ -481,7 +518,7 @@ func (p *TR06Protocol) parseHeartbeatData(reader *bufio.Reader) (heartbeat Heart
 	if err := binary.Read(reader, binary.BigEndian, &batteryLevelByte); err != nil {
 		return heartbeat, err
 	}
-	logger.Sugar().Infof("parseHeartbeatData  Battery Level Byte: %x", batteryLevelByte)
+	p.log().Sugar().Debugf("parseHeartbeatData  Battery Level Byte: %x", batteryLevelByte)
 	heartbeat.BatteryLevel = BatteryLevel(batteryLevelByte)
 	if heartbeat.BatteryLevel == VL_Invalid {
 		return heartbeat, errs.ErrGT06InvalidVoltageLevel
@@ -491,7 +528,7 @@ func (p *TR06Protocol) parseHeartbeatData(reader *bufio.Reader) (heartbeat Heart
 	if err := binary.Read(reader, binary.BigEndian, &gsmSignalStrengthByte); err != nil {
 		return heartbeat, err
 	}
-	logger.Sugar().Infof("parseHeartbeatData GSM Signal Strength Byte: %x", gsmSignalStrengthByte)
+	p.log().Sugar().Debugf("parseHeartbeatData GSM Signal Strength Byte: %x", gsmSignalStrengthByte)
 	heartbeat.GSMSignalStrength = GSMSignalStrength(gsmSignalStrengthByte)
 	if heartbeat.GSMSignalStrength == GSM_Invalid {
 		return heartbeat, errs.ErrGT06InvalidGSMSignalStrength
@@ -500,11 +537,11 @@ func (p *TR06Protocol) parseHeartbeatData(reader *bufio.Reader) (heartbeat Heart
 	if err := binary.Read(reader, binary.BigEndian, &heartbeat.ExtendedPortStatus); err != nil {
 		return heartbeat, err
 	}
-	logger.Sugar().Infof("parseHeartbeatData Extended Port Status Byte: %x", heartbeat.ExtendedPortStatus)
+	p.log().Sugar().Debugf("parseHeartbeatData Extended Port Status Byte: %x", heartbeat.ExtendedPortStatus)
 
 	if _, err := reader.Peek(1); err != io.EOF {
-		logger.Sugar().Errorf("parseHeartbeatData Extra bytes detected in packet")
-		logger.Sugar().Info("error from parseHeartbeatData 2")
+		p.log().Sugar().Errorf("parseHeartbeatData Extra bytes detected in packet")
+		p.log().Sugar().Debug("error from parseHeartbeatData 2")
 		return heartbeat, errors.Wrapf(errs.ErrGT06BadDataPacket, "from parseHeartbeatData 2")
 	}
 
@@ -514,9 +551,9 @@ func (p *TR06Protocol) parseHeartbeatData(reader *bufio.Reader) (heartbeat Heart
 func (p *TR06Protocol) parseInformationTransmissionPacket(reader *bufio.Reader) (packet InformationTransmissionPacket, err error) {
 	defer func() {
 		if r := recover(); r != nil {
-			err = r.(error)
-			if err != io.EOF {
-				logger.Sugar().Info("error from parseInformationTransmissionPacket: ", err)
+			err = recovered(r)
+			if err != io.EOF && !errors.Is(err, errs.ErrParserPanic) {
+				p.log().Sugar().Debug("error from parseInformationTransmissionPacket: ", err)
 				err = errors.New("GT06 Bad Data Packet")
 			}
 		}
@@ -524,36 +561,36 @@ func (p *TR06Protocol) parseInformationTransmissionPacket(reader *bufio.Reader)
 
 	var informationType byte
 	if err := binary.Read(reader, binary.BigEndian, &informationType); err != nil {
-		logger.Sugar().Info("parseInformationTransmissionPacket: Failed to read information type", err)
+		p.log().Sugar().Debug("parseInformationTransmissionPacket: Failed to read information type", err)
 		return packet, err
 	}
 
 	packet.InformationContent.InformationType = InformationType(informationType)
-	logger.Sugar().Info("parseInformationTransmissionPacket: ", packet.InformationContent.InformationType)
+	p.log().Sugar().Debug("parseInformationTransmissionPacket: ", packet.InformationContent.InformationType)
 
 	dataContent := make([]byte, 2)
-	logger.Sugar().Info("parseInformationTransmissionPacket: Reading data content: ", dataContent)
+	p.log().Sugar().Debug("parseInformationTransmissionPacket: Reading data content: ", dataContent)
 	if _, err := io.ReadFull(reader, dataContent); err != nil {
-		logger.Sugar().Info("parseInformationTransmissionPacket: Failed to read data content ", err)
+		p.log().Sugar().Debug("parseInformationTransmissionPacket: Failed to read data content ", err)
 		return packet, err
 	}
 
-	logger.Sugar().Info("parseInformationTransmissionPacket: Parsing data content based on information type ", informationType)
+	p.log().Sugar().Debug("parseInformationTransmissionPacket: Parsing data content based on information type ", informationType)
 	switch InformationType(informationType) {
 	case ExternalPowerVoltage:
 		if len(dataContent) < 2 {
-			logger.Sugar().Info("parseInformationTransmissionPacket: Insufficient data for ExternalPowerVoltage")
+			p.log().Sugar().Debug("parseInformationTransmissionPacket: Insufficient data for ExternalPowerVoltage")
 			return packet, errors.New("Insufficient data for ExternalPowerVoltage")
 		}
 		voltage := binary.BigEndian.Uint16(dataContent)
-		logger.Sugar().Info("voltage: ", voltage)
+		p.log().Sugar().Debug("voltage: ", voltage)
 		packet.InformationContent.DataContent = (voltage) / 100
 	case TerminalStatusSync:
 		status := packet.InformationContent.DataContent
 		packet.InformationContent.DataContent = status
 	case DoorStatus:
 		if len(dataContent) < 1 {
-			logger.Sugar().Info("parseInformationTransmissionPacket: Insufficient data for DoorStatus")
+			p.log().Sugar().Debug("parseInformationTransmissionPacket: Insufficient data for DoorStatus")
 			return packet, errors.New("Insufficient data for DoorStatus")
 		}
 		doorStatus := packet.InformationContent.DataContent
@@ -563,12 +600,12 @@ func (p *TR06Protocol) parseInformationTransmissionPacket(reader *bufio.Reader)
 	}
 
 	if remain, err := reader.Peek(1); err != io.EOF {
-		logger.Sugar().Info("parseInformationTransmissionPacket remaining bytes: ", remain)
-		logger.Sugar().Info("parseInformationTransmissionPacket: Extra bytes detected in packet")
+		p.log().Sugar().Debug("parseInformationTransmissionPacket remaining bytes: ", remain)
+		p.log().Sugar().Debug("parseInformationTransmissionPacket: Extra bytes detected in packet")
 		return packet, errors.New("GT06 Bad Data Packet")
 	}
 
-	logger.Sugar().Info("parseInformationTransmissionPacket: Successfully parsed packet")
+	p.log().Sugar().Debug("parseInformationTransmissionPacket: Successfully parsed packet")
 	return packet, nil
 }
 
@@ -578,6 +615,17 @@ func checkErr(err error) {
 	}
 }
 
+// recovered is the error for what a parser recovered from, checkErr's errors as they are and
+// anything else as errs.ErrParserPanic, so that bugs don't pass for bad packets
+func recovered(r interface{}) error {
+	if _, ok := r.(runtime.Error); !ok {
+		if err, ok := r.(error); ok {
+			return err
+		}
+	}
+	return fmt.Errorf("%w: %v", errs.ErrParserPanic, r)
+}
+
 func (p *TR06Protocol) parseGPSInformation(reader *bufio.Reader) (gpsInfo GPSInformation, err error) {
 	timestamp, err := p.parseTimestamp(reader)
 	checkErr(err)
@@ -599,7 +647,7 @@ func (p *TR06Protocol) parseGPSInformation(reader *bufio.Reader) (gpsInfo GPSInf
 
 	// speed
 	checkErr(binary.Read(reader, binary.BigEndian, &gpsInfo.Speed))
-	logger.Sugar().Info("speed from parseGPSInformation: ", gpsInfo.Speed)
+	p.log().Sugar().Debug("speed from parseGPSInformation: ", gpsInfo.Speed)
 
 	// TODO: parse the 16-bit course to detailed fields
 	// course/heading
@@ -647,7 +695,7 @@ func (p *TR06Protocol) parseTimestamp(reader *bufio.Reader) (timestamp time.Time
 	var timezone = time.UTC
 
 	timestamp = time.Date(yearInt, time.Month(month), int(day), int(hour), int(minute), int(second), 0, timezone)
-	logger.Sugar().Info("timestamp: ", timestamp)
+	p.log().Sugar().Debug("timestamp: ", timestamp)
 	return timestamp, nil
 }
 
@@ -724,8 +772,46 @@ func (p *TR06Protocol) IsValidHeader(reader *bufio.Reader) bool {
 	return false
 }
 
+// parseCommandReply reads the server flag, the content's encoding and the content, the content is
+// ascii unless the encoding is 0x02 for utf-16
+func (p *TR06Protocol) parseCommandReply(reader *bufio.Reader) (*CommandReply, error) {
+	reply := &CommandReply{}
+	err := binary.Read(reader, binary.BigEndian, &reply.ServerFlag)
+	if err != nil {
+		return nil, err
+	}
+	encoding, err := reader.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if encoding == 0x02 {
+		if len(content)%2 != 0 {
+			return nil, errors.Wrapf(errs.ErrGT06BadDataPacket, "odd utf-16 reply length %d", len(content))
+		}
+		units := make([]uint16, len(content)/2)
+		for i := range units {
+			units[i] = binary.BigEndian.Uint16(content[2*i:])
+		}
+		reply.Content = string(utf16.Decode(units))
+	} else {
+		reply.Content = string(content)
+	}
+	return reply, nil
+}
+
 // send command to device
+// the command is sent as an online command (0x80) packet, the device answers with a string information
+// (0x21) packet that ConsumeStream hands on as the response
 func (p *TR06Protocol) SendCommandToDevice(writer io.Writer, command string) error {
-	// Command in HEX for "getinfo"
-	return nil
+	packet, err := crc.WanwayCommandPacket(command)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(packet)
+	return errors.Wrapf(err, "failed to write command packet")
 }
@@ -0,0 +1,99 @@
+package tr06
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	errs "github.com/404minds/avl-receiver/internal/errors"
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"go.uber.org/zap/zapcore"
+)
+
+// seeds from the protocol document and devices in the field, as the device sends them
+var fuzzSeeds = []string{
+	"78 78 11 01 07 52 53 36 78 90 02 42 70 00 32 01 00 05 12 79 0D 0A", // login
+	"78 78 0D 01 01 23 45 67 89 01 23 45 00 01 8C DD 0D 0A",
+	"78 78 0A 13 40 04 04 00 01 00 0F DC EE 0D 0A",                                                                // heartbeat
+	"78 78 1F 12 0B 08 1D 11 2E 10 CC 02 7A C7 EB 0C 46 58 49 00 14 8F 01 CC 00 28 7D 00 1F B8 00 03 80 81 0D 0A", // position
+	"79 79 00 14 21 00 00 00 01 01 52 45 4C 41 59 3D 31 2C 4F 4B 00 04 73 CD 0D 0A",                               // command reply
+}
+
+// fuzzSetup adds the seeds and turns the protocol's logging off, it slows the fuzzer down a lot
+func fuzzSetup(f *testing.F) {
+	configuredLogger.SetLevel(zapcore.FatalLevel)
+	for _, seed := range fuzzSeeds {
+		data, err := hex.DecodeString(strings.ReplaceAll(seed, " ", ""))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+}
+
+// fuzzStore takes what the protocol stores until the test is done
+func fuzzStore(t *testing.T) store.Store {
+	s := &store.JsonLinesStore{ProcessChan: make(chan *types.DeviceStatus), ResponseChan: make(chan *types.DeviceResponse)}
+	done := make(chan struct{})
+	t.Cleanup(func() { close(done) })
+	go func() {
+		for {
+			select {
+			case <-s.ProcessChan:
+			case <-s.ResponseChan:
+			case <-done:
+				return
+			}
+		}
+	}()
+	return s
+}
+
+// checkRecovered fails on a panic the parser recovered from
+func checkRecovered(t *testing.T, err error) {
+	if errors.Is(err, errs.ErrParserPanic) {
+		t.Fatal(err)
+	}
+}
+
+func FuzzLogin(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		protocol := &TR06Protocol{}
+		_, _, err := protocol.Login(bufio.NewReader(bytes.NewReader(data)))
+		checkRecovered(t, err)
+	})
+}
+
+func FuzzConsumeStream(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		protocol := &TR06Protocol{LoginInformation: &LoginData{TerminalID: "355172106660428"}}
+		err := protocol.ConsumeStream(bufio.NewReader(bytes.NewReader(data)), io.Discard, fuzzStore(t))
+		checkRecovered(t, err)
+	})
+}
+
+func FuzzDecodeFrame(f *testing.F) {
+	fuzzSetup(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		protocol := &TR06Protocol{}
+		for len(data) > 0 {
+			frame, err := protocol.DecodeFrame(data)
+			checkRecovered(t, err)
+			if err != nil {
+				return
+			}
+			if frame.Size <= 0 || frame.Size > len(data) {
+				t.Fatalf("frame of %d bytes out of %d", frame.Size, len(data))
+			}
+			data = data[frame.Size:]
+		}
+	})
+}
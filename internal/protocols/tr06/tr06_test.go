@@ -0,0 +1,60 @@
+package tr06
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumeStreamCommandReply(t *testing.T) {
+	cases := []struct {
+		name     string
+		packet   string
+		ack      string
+		response string
+	}{
+		{"ascii", "79 79 00 14 21 00 00 00 01 01 52 45 4C 41 59 3D 31 2C 4F 4B 00 04 73 CD 0D 0A", "7878052100048d4a0d0a", "RELAY=1,OK"},
+		{"utf-16", "79 79 00 0E 21 00 00 00 01 02 00 4F 00 4B 00 05 52 AE 0D 0A", "7878052100059cc30d0a", "OK"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(strings.ReplaceAll(c.packet, " ", ""))
+			p := TR06Protocol{LoginInformation: &LoginData{TerminalID: "355172106660428"}}
+			dataStore := &store.JsonLinesStore{ProcessChan: make(chan *types.DeviceStatus, 1), ResponseChan: make(chan *types.DeviceResponse, 1)}
+			var ack bytes.Buffer
+
+			err := p.ConsumeStream(bufio.NewReader(bytes.NewReader(data)), &ack, dataStore)
+			assert.ErrorIs(t, err, io.EOF, "the stream should only end once the data runs out")
+
+			assert.Equal(t, c.ack, hex.EncodeToString(ack.Bytes()), "the reply should be acked")
+			if assert.Len(t, dataStore.ResponseChan, 1) {
+				response := <-dataStore.ResponseChan
+				assert.Equal(t, "355172106660428", response.Imei)
+				assert.Equal(t, c.response, response.Response)
+			}
+			assert.Empty(t, dataStore.ProcessChan, "a reply isn't a status")
+		})
+	}
+}
+
+func TestDecodeFrameCommandReply(t *testing.T) {
+	data, _ := hex.DecodeString(strings.ReplaceAll("79 79 00 14 21 00 00 00 01 01 52 45 4C 41 59 3D 31 2C 4F 4B 00 04 73 CD 0D 0A", " ", ""))
+	p := TR06Protocol{LoginInformation: &LoginData{TerminalID: "355172106660428"}}
+
+	frame, err := p.DecodeFrame(data)
+	if assert.NoError(t, err) {
+		assert.Equal(t, len(data), frame.Size)
+		assert.Empty(t, frame.Statuses)
+		if assert.Len(t, frame.Responses, 1) {
+			assert.Equal(t, "RELAY=1,OK", frame.Responses[0].Response)
+		}
+	}
+}
@@ -13,6 +13,8 @@ import (
 const (
 	StartBitValue = 0x7979
 	StopBitValue  = 0x0D0A
+	// longest 0x7979 packet length believed, command replies are a few hundred bytes at most
+	MaxPacketLength = 1024
 )
 
 type InformationType byte
@@ -52,7 +54,7 @@ type InformationContent struct {
 
 type Packet struct {
 	StartBit                uint16
-	PacketLength            byte
+	PacketLength            uint16 // a byte for 0x7878 packets
 	MessageType             MessageType
 	Information             interface{}
 	InformationSerialNumber uint16
@@ -64,6 +66,13 @@ type LoginData struct {
 	TerminalID string
 }
 
+// CommandReply is the string information (0x21) packet the device answers an online command
+// (0x80) with
+type CommandReply struct {
+	ServerFlag uint32
+	Content    string
+}
+
 type HeartbeatData struct {
 	TerminalInformation TerminalInformation
 	BatteryLevel        BatteryLevel
@@ -291,7 +300,7 @@ func (packet *Packet) ToProtobufDeviceStatus(imei string, deviceType types.Devic
 	info.VehicleStatus = &types.VehicleStatus{}
 	info.Position = &types.GPSPosition{}
 	info.MessageType = packet.MessageType.String()
-	logger.Sugar().Info("message type: ", info.MessageType)
+	logger.Sugar().Debugw("message type", "imei", imei, "messageType", info.MessageType)
 
 	// location info
 	switch v := packet.Information.(type) {
@@ -323,7 +332,7 @@ func (packet *Packet) ToProtobufDeviceStatus(imei string, deviceType types.Devic
 		info.VehicleStatus.OverSpeeding = v.StatusInformation.Alarm == ALV_OverSpeed
 	case *HeartbeatData:
 		//Set battery and GSM signal
-		logger.Sugar().Info(v.BatteryLevel, "  ", v.GSMSignalStrength)
+		logger.Sugar().Debugw("battery and gsm signal", "imei", imei, "battery", v.BatteryLevel, "gsm", v.GSMSignalStrength)
 		info.BatteryLevel = resolveBatteryLevel(int32(v.BatteryLevel))
 		info.GsmNetwork = int32(v.GSMSignalStrength)
 
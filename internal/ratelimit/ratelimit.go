@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often buckets back to full are dropped, a full bucket is the same as none
+const sweepInterval = time.Minute
+
+// Limit is a token bucket's refill rate and size
+type Limit struct {
+	Rate  float64 // tokens per second, 0 is unlimited
+	Burst int     // tokens a full bucket holds, at least 1
+}
+
+func (l Limit) unlimited() bool {
+	return l.Rate <= 0
+}
+
+func (l Limit) burst() float64 {
+	return float64(max(l.Burst, 1))
+}
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Buckets are token buckets with the same limit keyed by e.g. a client's ip, made on first use
+type Buckets struct {
+	now func() time.Time
+
+	mu        sync.Mutex
+	limit     Limit
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func New(limit Limit) *Buckets {
+	return &Buckets{
+		now:     time.Now,
+		limit:   limit,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Allow takes a token from the key's bucket, false if it's empty
+func (b *Buckets) Allow(key string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.limit.unlimited() {
+		return true
+	}
+
+	now := b.now()
+	b.sweep(now)
+	bkt, ok := b.buckets[key]
+	if !ok {
+		bkt = &bucket{tokens: b.limit.burst(), updated: now}
+		b.buckets[key] = bkt
+	}
+	b.refill(bkt, now)
+	if bkt.tokens < 1 {
+		return false
+	}
+	bkt.tokens--
+	return true
+}
+
+// Limit is the buckets' limit
+func (b *Buckets) Limit() Limit {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.limit
+}
+
+// SetLimit changes the limit of the buckets, the tokens they have are kept up to the new burst
+func (b *Buckets) SetLimit(limit Limit) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	for _, bkt := range b.buckets {
+		b.refill(bkt, now)
+		bkt.tokens = min(bkt.tokens, limit.burst())
+	}
+	b.limit = limit
+	if limit.unlimited() {
+		clear(b.buckets)
+	}
+}
+
+// Len is the number of keys with tokens taken
+func (b *Buckets) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.buckets)
+}
+
+func (b *Buckets) refill(bkt *bucket, now time.Time) {
+	if elapsed := now.Sub(bkt.updated).Seconds(); elapsed > 0 {
+		bkt.tokens = min(bkt.tokens+elapsed*b.limit.Rate, b.limit.burst())
+	}
+	bkt.updated = now
+}
+
+// sweep drops the buckets that refilled, so keys seen once like a scanner's ips don't pile up
+func (b *Buckets) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < sweepInterval {
+		return
+	}
+	b.lastSweep = now
+	for key, bkt := range b.buckets {
+		b.refill(bkt, now)
+		if bkt.tokens >= b.limit.burst() {
+			delete(b.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestBuckets(limit Limit) (*Buckets, *clock) {
+	c := &clock{now: time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)}
+	b := New(limit)
+	b.now = func() time.Time { return c.now }
+	return b, c
+}
+
+func TestAllowBurstThenRate(t *testing.T) {
+	b, c := newTestBuckets(Limit{Rate: 2, Burst: 3})
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, b.Allow("10.0.0.1"), "burst %d", i)
+	}
+	assert.False(t, b.Allow("10.0.0.1"))
+	assert.True(t, b.Allow("10.0.0.2"), "keys have their own buckets")
+
+	c.advance(500 * time.Millisecond)
+	assert.True(t, b.Allow("10.0.0.1"))
+	assert.False(t, b.Allow("10.0.0.1"))
+
+	c.advance(time.Hour)
+	for i := 0; i < 3; i++ {
+		assert.True(t, b.Allow("10.0.0.1"), "refilled up to the burst only, %d", i)
+	}
+	assert.False(t, b.Allow("10.0.0.1"))
+}
+
+func TestAllowUnlimited(t *testing.T) {
+	b, _ := newTestBuckets(Limit{})
+	for i := 0; i < 100; i++ {
+		assert.True(t, b.Allow("10.0.0.1"))
+	}
+	assert.Equal(t, 0, b.Len())
+}
+
+func TestSweepDropsFullBuckets(t *testing.T) {
+	b, c := newTestBuckets(Limit{Rate: 0.1, Burst: 10})
+	b.Allow("10.0.0.1")
+	for i := 0; i < 10; i++ {
+		b.Allow("10.0.0.2")
+	}
+	assert.Equal(t, 2, b.Len())
+
+	// a minute later 10.0.0.1 is full again and 10.0.0.2 has 6 of its 10
+	c.advance(sweepInterval)
+	b.Allow("10.0.0.3")
+	assert.Equal(t, 2, b.Len())
+	assert.True(t, b.Allow("10.0.0.2"))
+}
+
+func TestSetLimit(t *testing.T) {
+	b, c := newTestBuckets(Limit{Rate: 1, Burst: 5})
+	b.Allow("10.0.0.1")
+
+	b.SetLimit(Limit{Rate: 1, Burst: 1})
+	assert.True(t, b.Allow("10.0.0.1"))
+	assert.False(t, b.Allow("10.0.0.1"))
+
+	c.advance(time.Second)
+	b.SetLimit(Limit{})
+	assert.Equal(t, 0, b.Len())
+	assert.True(t, b.Allow("10.0.0.1"))
+}
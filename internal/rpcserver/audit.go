@@ -0,0 +1,89 @@
+package rpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/peer"
+)
+
+// AuditEntry is one command issued through the api, written as a json line to the audit log
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	Principal string    `json:"principal"`
+	Role      Role      `json:"role,omitempty"`
+	Peer      string    `json:"peer,omitempty"`
+	Method    string    `json:"method"`
+	Imei      string    `json:"imei,omitempty"`
+	Command   string    `json:"command,omitempty"`
+	RequestId string    `json:"requestId,omitempty"`
+	Status    string    `json:"status"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// AuditLog appends who issued which command to which device and how it went. A nil AuditLog
+// only logs the entries.
+type AuditLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func OpenAuditLog(path string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{file: file}, nil
+}
+
+// Record fills in the caller from the context and writes the entry
+func (a *AuditLog) Record(ctx context.Context, entry AuditEntry) {
+	if entry.Time.IsZero() {
+		entry.Time = time.Now().UTC()
+	}
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		entry.Principal = principal.Name
+		entry.Role = principal.Role
+	} else {
+		entry.Principal = "anonymous"
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		entry.Peer = p.Addr.String()
+	}
+
+	logger.Info("audit", zap.String("principal", entry.Principal), zap.String("method", entry.Method),
+		zap.String("imei", entry.Imei), zap.String("command", entry.Command), zap.String("requestId", entry.RequestId),
+		zap.String("status", entry.Status))
+	if a == nil {
+		return
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		logger.Error("failed to encode audit entry", zap.Error(err))
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := a.file.Write(append(line, '\n')); err != nil {
+		logger.Error("failed to write audit entry", zap.Error(err))
+		return
+	}
+	// the audit trail has to survive a crash right after a command went out
+	if err := a.file.Sync(); err != nil {
+		logger.Error("failed to sync audit log", zap.Error(err))
+	}
+}
+
+func (a *AuditLog) Close() error {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.file.Close()
+}
@@ -0,0 +1,37 @@
+package rpcserver
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditLogRecordsCaller(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	audit, err := OpenAuditLog(path)
+	require.NoError(t, err)
+
+	ctx, err := testAuthorizer(t).authorize(withToken("dispatch-secret"), sendCommand)
+	require.NoError(t, err)
+	audit.Record(ctx, AuditEntry{Method: "SendCommand", Imei: "350424063817426", Command: "engine_cut", Status: "COMMAND_ACK"})
+	require.NoError(t, audit.Close())
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var entry AuditEntry
+	require.NoError(t, json.Unmarshal(raw, &entry))
+	assert.Equal(t, "dispatcher", entry.Principal)
+	assert.Equal(t, RoleCommand, entry.Role)
+	assert.Equal(t, "350424063817426", entry.Imei)
+	assert.Equal(t, "engine_cut", entry.Command)
+	assert.Equal(t, "COMMAND_ACK", entry.Status)
+	assert.False(t, entry.Time.IsZero())
+
+	// a nil audit log only logs
+	var disabled *AuditLog
+	disabled.Record(ctx, AuditEntry{Method: "SendCommand"})
+}
@@ -0,0 +1,307 @@
+package rpcserver
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Role is what a caller of the api is allowed to do, a higher role includes the lower ones
+type Role string
+
+const (
+	RolePublic  Role = "public"  // anyone, e.g. health checks
+	RoleRead    Role = "read"    // look at connections, queues and positions
+	RoleCommand Role = "command" // send, queue and cancel commands, disconnect devices
+)
+
+var roleRanks = map[Role]int{RolePublic: 0, RoleRead: 1, RoleCommand: 2}
+
+func ParseRole(s string) (Role, error) {
+	role := Role(strings.ToLower(s))
+	if _, ok := roleRanks[role]; !ok || role == RolePublic {
+		return "", fmt.Errorf("unknown role %q, expected read or command", s)
+	}
+	return role, nil
+}
+
+// Includes is true if the role may call methods requiring the other role
+func (r Role) Includes(other Role) bool {
+	return roleRanks[r] >= roleRanks[other]
+}
+
+// Principal is the authenticated caller of an api method
+type Principal struct {
+	Name string
+	Role Role
+	Via  string // "token" or "cert"
+}
+
+type principalKey struct{}
+
+// PrincipalFromContext returns the caller of the method, false if auth is disabled or the method is public
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Authorizer authenticates api callers by bearer token or client certificate and checks their role
+// against the role the method requires. Methods missing from the method roles require RoleCommand,
+// so new rpcs are locked down until they are listed.
+type Authorizer struct {
+	tokens      map[[sha256.Size]byte]Principal
+	certs       map[string]Principal // by certificate common name
+	methodRoles map[string]Role
+}
+
+func NewAuthorizer(principals []PrincipalEntry, methodRoles map[string]Role) *Authorizer {
+	a := &Authorizer{
+		tokens:      make(map[[sha256.Size]byte]Principal),
+		certs:       make(map[string]Principal),
+		methodRoles: methodRoles,
+	}
+	for _, entry := range principals {
+		if entry.TokenHash == nil {
+			a.certs[entry.Name] = Principal{Name: entry.Name, Role: entry.Role, Via: "cert"}
+		} else {
+			a.tokens[*entry.TokenHash] = Principal{Name: entry.Name, Role: entry.Role, Via: "token"}
+		}
+	}
+	return a
+}
+
+// PrincipalEntry is one line of the auth file, TokenHash is nil for client certificate principals
+type PrincipalEntry struct {
+	Name      string
+	Role      Role
+	TokenHash *[sha256.Size]byte
+}
+
+// LoadPrincipals reads the auth file. Each line is "<name> <role> [token]", a token of the form
+// sha256:<hex> is matched by its hash. Lines without a token are for clients presenting a
+// certificate with the name as its common name. Empty lines and lines starting with # are skipped.
+func LoadPrincipals(path string) ([]PrincipalEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []PrincipalEntry
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("%s:%d: expected <name> <role> [token]", path, lineNo)
+		}
+		role, err := ParseRole(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+		entry := PrincipalEntry{Name: fields[0], Role: role}
+		if len(fields) == 3 {
+			hash, err := tokenHash(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNo, err)
+			}
+			entry.TokenHash = &hash
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+func tokenHash(token string) ([sha256.Size]byte, error) {
+	var hash [sha256.Size]byte
+	if hexHash, ok := strings.CutPrefix(token, "sha256:"); ok {
+		decoded, err := hex.DecodeString(hexHash)
+		if err != nil || len(decoded) != sha256.Size {
+			return hash, errors.New("invalid sha256 token hash")
+		}
+		copy(hash[:], decoded)
+		return hash, nil
+	}
+	return sha256.Sum256([]byte(token)), nil
+}
+
+func (a *Authorizer) requiredRole(method string) Role {
+	if strings.HasPrefix(method, "/grpc.health.v1.Health/") {
+		return RolePublic
+	}
+	if strings.HasPrefix(method, "/grpc.reflection.") {
+		return RoleRead
+	}
+	if role, ok := a.methodRoles[method]; ok {
+		return role
+	}
+	return RoleCommand
+}
+
+func (a *Authorizer) authenticate(ctx context.Context) (Principal, error) {
+	var authorization string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			authorization = values[0]
+		}
+	}
+	var state *tls.ConnectionState
+	if p, ok := peer.FromContext(ctx); ok {
+		if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			state = &tlsInfo.State
+		}
+	}
+	return a.principal(authorization, state)
+}
+
+// principal is the caller presenting the authorization header or, without one, the client certificate
+func (a *Authorizer) principal(authorization string, state *tls.ConnectionState) (Principal, error) {
+	if authorization != "" {
+		token, ok := strings.CutPrefix(authorization, "Bearer ")
+		if !ok {
+			return Principal{}, status.Error(codes.Unauthenticated, "expected a bearer token")
+		}
+		return a.tokenPrincipal(token)
+	}
+
+	if state != nil && len(state.VerifiedChains) > 0 {
+		name := state.VerifiedChains[0][0].Subject.CommonName
+		if principal, ok := a.certs[name]; ok {
+			return principal, nil
+		}
+		return Principal{}, status.Errorf(codes.PermissionDenied, "certificate %q has no role", name)
+	}
+	return Principal{}, status.Error(codes.Unauthenticated, "missing bearer token or client certificate")
+}
+
+func (a *Authorizer) tokenPrincipal(token string) (Principal, error) {
+	presented := sha256.Sum256([]byte(token))
+	var found *Principal
+	// compare against every token so the time taken doesn't depend on which one matched
+	for hash, principal := range a.tokens {
+		if subtle.ConstantTimeCompare(presented[:], hash[:]) == 1 {
+			p := principal
+			found = &p
+		}
+	}
+	if found == nil {
+		return Principal{}, status.Error(codes.Unauthenticated, "invalid token")
+	}
+	return *found, nil
+}
+
+func (a *Authorizer) authorize(ctx context.Context, method string) (context.Context, error) {
+	required := a.requiredRole(method)
+	if required == RolePublic {
+		return ctx, nil
+	}
+	principal, err := a.authenticate(ctx)
+	if err != nil {
+		return ctx, err
+	}
+	if !principal.Role.Includes(required) {
+		return ctx, status.Errorf(codes.PermissionDenied, "%s needs the %s role, %s has %s", method, required, principal.Name, principal.Role)
+	}
+	return context.WithValue(ctx, principalKey{}, principal), nil
+}
+
+func (a *Authorizer) unary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := a.authorize(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (a *Authorizer) stream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := a.authorize(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authorizedStream{ServerStream: ss, ctx: ctx})
+}
+
+// HttpHandler puts an http api behind the same principals. methodRoles is the role each http method
+// needs, methods missing from it need RoleCommand.
+func (a *Authorizer) HttpHandler(next http.Handler, methodRoles map[string]Role) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		required, ok := methodRoles[r.Method]
+		if !ok {
+			required = RoleCommand
+		}
+		if required == RolePublic {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		principal, err := a.principal(r.Header.Get("Authorization"), r.TLS)
+		if err == nil && !principal.Role.Includes(required) {
+			err = status.Errorf(codes.PermissionDenied, "%s %s needs the %s role, %s has %s", r.Method, r.URL.Path, required, principal.Name, principal.Role)
+		}
+		if err != nil {
+			code := http.StatusForbidden
+			if status.Code(err) == codes.Unauthenticated {
+				code = http.StatusUnauthorized
+				w.Header().Set("WWW-Authenticate", "Bearer")
+			}
+			http.Error(w, status.Convert(err).Message(), code)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, principal)))
+	})
+}
+
+type authorizedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authorizedStream) Context() context.Context {
+	return s.ctx
+}
+
+// ServerTLSConfig loads the api's certificate. With a client CA, clients presenting a certificate
+// are verified against it so they can authenticate with the certificate instead of a token.
+func ServerTLSConfig(certFile, keyFile, clientCAFile string) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if clientCAFile != "" {
+		pem, err := os.ReadFile(clientCAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", clientCAFile)
+		}
+		config.ClientCAs = pool
+		// token callers and health checks don't have a certificate, the authorizer rejects callers with neither
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return config, nil
+}
@@ -0,0 +1,134 @@
+package rpcserver
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	sendCommand     = "/store.AvlReceiverService/SendCommand"
+	listConnections = "/store.AvlReceiverService/ListConnections"
+)
+
+func testAuthorizer(t *testing.T) *Authorizer {
+	hash := sha256.Sum256([]byte("dispatch-secret"))
+	path := filepath.Join(t.TempDir(), "auth")
+	require.NoError(t, os.WriteFile(path, []byte(`
+# name role token
+dashboard read dashboard-secret
+dispatcher command sha256:`+hex.EncodeToString(hash[:])+`
+fleet-api command
+`), 0o600))
+
+	principals, err := LoadPrincipals(path)
+	require.NoError(t, err)
+	require.Len(t, principals, 3)
+	return NewAuthorizer(principals, map[string]Role{
+		sendCommand:     RoleCommand,
+		listConnections: RoleRead,
+	})
+}
+
+func withToken(token string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+func withCert(commonName string) context.Context {
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 40000},
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{cert}},
+		}},
+	})
+}
+
+func TestAuthorizerRoles(t *testing.T) {
+	a := testAuthorizer(t)
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		method string
+		code   codes.Code
+		caller string
+	}{
+		{"read token lists connections", withToken("dashboard-secret"), listConnections, codes.OK, "dashboard"},
+		{"read token can't send commands", withToken("dashboard-secret"), sendCommand, codes.PermissionDenied, ""},
+		{"hashed command token sends commands", withToken("dispatch-secret"), sendCommand, codes.OK, "dispatcher"},
+		{"command token reads too", withToken("dispatch-secret"), listConnections, codes.OK, "dispatcher"},
+		{"unknown token", withToken("guess"), listConnections, codes.Unauthenticated, ""},
+		{"no credentials", context.Background(), listConnections, codes.Unauthenticated, ""},
+		{"client certificate", withCert("fleet-api"), sendCommand, codes.OK, "fleet-api"},
+		{"certificate without a role", withCert("someone"), listConnections, codes.PermissionDenied, ""},
+		{"health is public", context.Background(), "/grpc.health.v1.Health/Check", codes.OK, ""},
+		{"unlisted methods need the command role", withToken("dashboard-secret"), "/store.AvlReceiverService/New", codes.PermissionDenied, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := a.authorize(tt.ctx, tt.method)
+			assert.Equal(t, tt.code, status.Code(err))
+			principal, ok := PrincipalFromContext(ctx)
+			assert.Equal(t, tt.caller != "", ok)
+			assert.Equal(t, tt.caller, principal.Name)
+		})
+	}
+}
+
+func TestAuthorizerHttpHandler(t *testing.T) {
+	a := testAuthorizer(t)
+	handler := a.HttpHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ := PrincipalFromContext(r.Context())
+		w.Write([]byte(principal.Name))
+	}), map[string]Role{http.MethodGet: RoleRead})
+
+	tests := []struct {
+		method string
+		token  string
+		code   int
+		caller string
+	}{
+		{http.MethodGet, "dashboard-secret", http.StatusOK, "dashboard"},
+		{http.MethodPut, "dashboard-secret", http.StatusForbidden, ""},
+		{http.MethodDelete, "dispatch-secret", http.StatusOK, "dispatcher"},
+		{http.MethodGet, "guess", http.StatusUnauthorized, ""},
+		{http.MethodGet, "", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(tt.method, "/api/devices/350424063817363", nil)
+		if tt.token != "" {
+			r.Header.Set("Authorization", "Bearer "+tt.token)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		assert.Equal(t, tt.code, w.Code, "%s with %q", tt.method, tt.token)
+		if tt.caller != "" {
+			assert.Equal(t, tt.caller, w.Body.String())
+		}
+	}
+}
+
+func TestLoadPrincipalsRejectsUnknownRoles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "auth")
+	require.NoError(t, os.WriteFile(path, []byte("dashboard admin secret\n"), 0o600))
+	_, err := LoadPrincipals(path)
+	assert.ErrorContains(t, err, "unknown role")
+}
@@ -0,0 +1,142 @@
+package rpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+const defaultProbeTimeout = 3 * time.Second
+
+// Probe checks one dependency of the receiver, e.g. the tcp listener or a store
+type Probe struct {
+	Name  string // also the health service name the probe's status is reported under
+	Check func(ctx context.Context) error
+}
+
+// HealthChecker runs the probes periodically and reports them through the standard grpc health
+// service. The overall status (empty service name) and the services listed in NewHealthChecker are
+// SERVING only while every probe passes, each probe also has its own status under its name.
+type HealthChecker struct {
+	Server   *health.Server
+	interval time.Duration
+	services []string
+	probes   []Probe
+
+	mu     sync.Mutex
+	failed map[string]error
+}
+
+func NewHealthChecker(interval time.Duration, services ...string) *HealthChecker {
+	h := &HealthChecker{
+		Server:   health.NewServer(),
+		interval: interval,
+		services: append([]string{""}, services...),
+		failed:   make(map[string]error),
+	}
+	// not serving until the first round of probes passed
+	for _, service := range h.services {
+		h.Server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+	}
+	return h
+}
+
+func (h *HealthChecker) AddProbe(probe Probe) {
+	h.probes = append(h.probes, probe)
+}
+
+func (h *HealthChecker) Register(s *grpc.Server) {
+	healthpb.RegisterHealthServer(s, h.Server)
+}
+
+// Run checks the probes every interval until ctx is done
+func (h *HealthChecker) Run(ctx context.Context) {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+	for {
+		h.CheckNow(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			h.Server.Shutdown()
+			return
+		}
+	}
+}
+
+func (h *HealthChecker) CheckNow(ctx context.Context) {
+	healthy := true
+	for _, probe := range h.probes {
+		probeCtx, cancel := context.WithTimeout(ctx, defaultProbeTimeout)
+		err := probe.Check(probeCtx)
+		cancel()
+
+		h.record(probe.Name, err)
+		if err != nil {
+			healthy = false
+			h.Server.SetServingStatus(probe.Name, healthpb.HealthCheckResponse_NOT_SERVING)
+		} else {
+			h.Server.SetServingStatus(probe.Name, healthpb.HealthCheckResponse_SERVING)
+		}
+	}
+
+	status := healthpb.HealthCheckResponse_SERVING
+	if !healthy {
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	for _, service := range h.services {
+		h.Server.SetServingStatus(service, status)
+	}
+}
+
+// record logs probes going down and coming back instead of every failed check
+func (h *HealthChecker) record(name string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	previous, wasFailing := h.failed[name]
+	switch {
+	case err != nil && !wasFailing:
+		logger.Error("health probe failing", zap.String("probe", name), zap.Error(err))
+		h.failed[name] = err
+	case err != nil && previous.Error() != err.Error():
+		logger.Warn("health probe still failing", zap.String("probe", name), zap.Error(err))
+		h.failed[name] = err
+	case err == nil && wasFailing:
+		logger.Info("health probe recovered", zap.String("probe", name))
+		delete(h.failed, name)
+	}
+}
+
+// FlagProbe passes while the flag is set, e.g. while a listener is accepting connections
+func FlagProbe(name string, flag *atomic.Bool) Probe {
+	return Probe{Name: name, Check: func(ctx context.Context) error {
+		if !flag.Load() {
+			return errors.New("not ready")
+		}
+		return nil
+	}}
+}
+
+// ClientConnProbe passes while the grpc connection is usable, an idle connection is woken up
+func ClientConnProbe(name string, conn *grpc.ClientConn) Probe {
+	return Probe{Name: name, Check: func(ctx context.Context) error {
+		switch state := conn.GetState(); state {
+		case connectivity.Ready:
+			return nil
+		case connectivity.Idle:
+			conn.Connect()
+			return nil
+		default:
+			return fmt.Errorf("connection %s", state)
+		}
+	}}
+}
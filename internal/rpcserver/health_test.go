@@ -0,0 +1,64 @@
+package rpcserver
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func servingStatus(t *testing.T, h *HealthChecker, service string) healthpb.HealthCheckResponse_ServingStatus {
+	resp, err := h.Server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+	require.NoError(t, err)
+	return resp.Status
+}
+
+func TestHealthCheckerReflectsProbes(t *testing.T) {
+	var listening atomic.Bool
+	var storeDown atomic.Bool
+	storeDown.Store(true)
+
+	h := NewHealthChecker(time.Hour, "store.AvlReceiverService")
+	h.AddProbe(FlagProbe("tcp", &listening))
+	h.AddProbe(Probe{Name: "store.remote", Check: func(ctx context.Context) error {
+		if storeDown.Load() {
+			return errors.New("connection refused")
+		}
+		return nil
+	}})
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, h, ""), "not serving before the first check")
+
+	listening.Store(true)
+	h.CheckNow(context.Background())
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, h, "tcp"))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, h, "store.remote"))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, h, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, h, "store.AvlReceiverService"))
+
+	storeDown.Store(false)
+	h.CheckNow(context.Background())
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, h, ""))
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, servingStatus(t, h, "store.AvlReceiverService"))
+
+	listening.Store(false)
+	h.CheckNow(context.Background())
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, h, ""))
+}
+
+func TestHealthCheckerTimesOutProbes(t *testing.T) {
+	h := NewHealthChecker(time.Hour)
+	h.AddProbe(Probe{Name: "stuck", Check: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	h.CheckNow(ctx)
+	assert.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, servingStatus(t, h, "stuck"))
+}
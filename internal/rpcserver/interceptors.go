@@ -0,0 +1,115 @@
+// Package rpcserver has the pieces shared by the receiver's grpc api: interceptors, auth, auditing and health checking.
+package rpcserver
+
+import (
+	"context"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+var logger = configuredLogger.Logger
+
+var (
+	handledTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "avl_grpc_server_handled_total",
+		Help: "Receiver api calls completed, by method and status code.",
+	}, []string{"method", "code"})
+	handlingSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "avl_grpc_server_handling_seconds",
+		Help:    "Time spent handling receiver api calls, streams included.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+	panicsTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "avl_grpc_server_panics_total",
+		Help: "Receiver api handlers that panicked.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(handledTotal, handlingSeconds, panicsTotal)
+}
+
+// ServerOptions installs recovery, logging and metrics on every unary and streaming call, and
+// authorization if auth isn't nil. Authorization runs last so rejected calls are logged and counted.
+func ServerOptions(auth *Authorizer) []grpc.ServerOption {
+	unary := []grpc.UnaryServerInterceptor{unaryRecovery, unaryObserve}
+	stream := []grpc.StreamServerInterceptor{streamRecovery, streamObserve}
+	if auth != nil {
+		unary = append(unary, auth.unary)
+		stream = append(stream, auth.stream)
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+}
+
+func recovered(method string, r any) error {
+	panicsTotal.Inc()
+	logger.Error("panic in grpc handler", zap.String("method", method), zap.Any("panic", r), zap.ByteString("stack", debug.Stack()))
+	return status.Errorf(codes.Internal, "internal error")
+}
+
+func unaryRecovery(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func streamRecovery(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(info.FullMethod, r)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func unaryObserve(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	observe(ctx, info.FullMethod, start, err)
+	return resp, err
+}
+
+func streamObserve(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	observe(ss.Context(), info.FullMethod, start, err)
+	return err
+}
+
+func observe(ctx context.Context, method string, start time.Time, err error) {
+	elapsed := time.Since(start)
+	code := status.Code(err)
+	handledTotal.WithLabelValues(method, code.String()).Inc()
+	handlingSeconds.WithLabelValues(method).Observe(elapsed.Seconds())
+
+	fields := []zap.Field{zap.String("method", method), zap.String("code", code.String()), zap.Duration("duration", elapsed)}
+	if p, ok := peer.FromContext(ctx); ok {
+		fields = append(fields, zap.String("peer", p.Addr.String()))
+	}
+	switch {
+	case code == codes.OK && strings.HasPrefix(method, "/grpc.health.v1.Health/"):
+		// probes poll every few seconds
+		logger.Debug("grpc call", fields...)
+	case code == codes.OK:
+		logger.Info("grpc call", fields...)
+	case code == codes.Internal || code == codes.Unknown || code == codes.DataLoss:
+		logger.Error("grpc call failed", append(fields, zap.Error(err))...)
+	default:
+		logger.Warn("grpc call failed", append(fields, zap.Error(err))...)
+	}
+}
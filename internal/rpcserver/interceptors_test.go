@@ -0,0 +1,34 @@
+package rpcserver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestUnaryInterceptorsRecoverAndCount(t *testing.T) {
+	info := &grpc.UnaryServerInfo{FullMethod: "/store.AvlReceiverService/Panics"}
+	chain := func(ctx context.Context, req any, handler grpc.UnaryHandler) (any, error) {
+		return unaryRecovery(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+			return unaryObserve(ctx, req, info, handler)
+		})
+	}
+	panicsBefore := testutil.ToFloat64(panicsTotal)
+
+	_, err := chain(context.Background(), nil, func(ctx context.Context, req any) (any, error) {
+		panic("boom")
+	})
+	assert.Equal(t, codes.Internal, status.Code(err))
+	assert.Equal(t, panicsBefore+1, testutil.ToFloat64(panicsTotal))
+
+	_, err = chain(context.Background(), nil, func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "no such device")
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	assert.Equal(t, float64(1), testutil.ToFloat64(handledTotal.WithLabelValues(info.FullMethod, codes.NotFound.String())))
+}
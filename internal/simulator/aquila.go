@@ -0,0 +1,103 @@
+package simulator
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	aquilaPosition        = "01"
+	aquilaLogin           = "15"
+	aquilaHarshCornering  = "23"
+	aquilaFlagOverspeed   = 1 << 3
+	aquilaFlagIgnition    = 1 << 11
+	aquilaExternalBattery = 4100 // mV
+	aquilaInternalBattery = 3900 // mV
+)
+
+// aquila is an OBDII 2G device sending $$CLIENT_1NS lines, the receiver never answers them
+type aquila struct {
+	imei    string
+	started time.Time
+}
+
+func newAquila(imei string) (Codec, error) {
+	if len(imei) != 15 {
+		return nil, fmt.Errorf("aquila imei must have 15 digits: %s", imei)
+	}
+	return &aquila{imei: imei}, nil
+}
+
+func (a *aquila) Imei() string {
+	return a.imei
+}
+
+func (a *aquila) Login(fix Fix) Frame {
+	a.started = fix.Time
+	return Frame{Data: a.line(aquilaLogin, fix, aquilaFlagIgnition)}
+}
+
+func (a *aquila) Positions(fixes []Fix) Frame {
+	var frame Frame
+	for _, fix := range fixes {
+		frame.Data = append(frame.Data, a.line(aquilaPosition, fix, aquilaFlagIgnition)...)
+	}
+	return frame
+}
+
+func (a *aquila) Alarm(fix Fix) Frame {
+	return Frame{Data: a.line(aquilaHarshCornering, fix, aquilaFlagIgnition|aquilaFlagOverspeed)}
+}
+
+// Heartbeat is nothing, the device only sends positions
+func (a *aquila) Heartbeat(Fix) Frame {
+	return Frame{}
+}
+
+// line is "$$CLIENT_1NS,imei,code,lat,lon,time,A,gsm,speed,odometer km,course,sats,hdop,..." followed
+// by the obd pids and "*" with the xor of everything before it
+func (a *aquila) line(code string, fix Fix, flags uint32) []byte {
+	speed := math.Round(fix.SpeedKmh)
+	fields := []string{
+		"$$CLIENT_1NS",
+		a.imei,
+		code,
+		fmt.Sprintf("%.6f", fix.Lat),
+		fmt.Sprintf("%.6f", fix.Lon),
+		fix.Time.UTC().Format("060102150405"),
+		"A",
+		"28", // gsm csq
+		fmt.Sprintf("%.0f", speed),
+		fmt.Sprintf("%d", int(fix.Odometer/1000)),
+		fmt.Sprintf("%.0f", fix.Course),
+		fmt.Sprintf("%d", fix.Satellites),
+		"0.9", // hdop
+		"0",
+		"0",
+		"0", // analog input
+		fmt.Sprintf("%d", flags),
+		fmt.Sprintf("%d", aquilaExternalBattery),
+		fmt.Sprintf("%d", aquilaInternalBattery),
+		fmt.Sprintf("%d", int(fix.Time.Sub(a.started).Seconds())),
+		"0",
+		strings.Join([]string{
+			"1",
+			fmt.Sprintf("010C:04410C%04X", 2200*4), // rpm
+			fmt.Sprintf("010D:03410D%02X", int(min(speed, 255))),
+			"0105:03410582", // coolant 90°C
+			"012F:03412FA0", // fuel level 63%
+		}, "|"),
+	}
+	body := strings.Join(fields, ",")
+	return []byte(fmt.Sprintf("%s*%02X\r\n", body, aquilaChecksum(body)))
+}
+
+func aquilaChecksum(s string) byte {
+	var sum byte
+	for i := 0; i < len(s); i++ {
+		sum ^= s[i]
+	}
+	return sum
+}
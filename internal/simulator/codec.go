@@ -0,0 +1,98 @@
+// Package simulator speaks the device protocols the receiver supports, to test it end to end and
+// put load on it.
+package simulator
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ErrBadAck is a reply from the receiver that isn't the ack the device expects
+var ErrBadAck = errors.New("bad ack")
+
+// Frame is what a device sends in one go
+type Frame struct {
+	Data []byte
+	// Ack reads and checks the receiver's replies to the frame, nil when the receiver doesn't
+	// answer it
+	Ack func(r *bufio.Reader) error
+}
+
+// Codec builds the frames of one simulated device. It isn't safe for concurrent use.
+type Codec interface {
+	// Login is the first frame on the connection, fix is where the device starts
+	Login(fix Fix) Frame
+	// Positions reports the fixes in as few frames as the protocol allows
+	Positions(fixes []Fix) Frame
+	// Alarm reports the fix as an alarm or event
+	Alarm(fix Fix) Frame
+	// Heartbeat is the keepalive, a frame without data if the protocol has none
+	Heartbeat(fix Fix) Frame
+	// Imei the receiver knows the device by
+	Imei() string
+}
+
+// NewCodec returns the codec for one of Protocols()
+type NewCodec func(imei string) (Codec, error)
+
+var codecs = map[string]NewCodec{
+	"codec8":            func(imei string) (Codec, error) { return newTeltonika(imei, codec8) },
+	"codec8e":           func(imei string) (Codec, error) { return newTeltonika(imei, codec8E) },
+	"gt06":              func(imei string) (Codec, error) { return newConcox(imei, gt06Dialect) },
+	"tr06":              func(imei string) (Codec, error) { return newConcox(imei, tr06Dialect) },
+	"aquila":            func(imei string) (Codec, error) { return newAquila(imei) },
+	"intellitrac":       func(imei string) (Codec, error) { return newIntellitrac(imei, false) },
+	"intellitrac-ascii": func(imei string) (Codec, error) { return newIntellitrac(imei, true) },
+}
+
+// Protocols are the names codecs are picked by
+func Protocols() []string {
+	var names []string
+	for name := range codecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func CodecFor(protocol, imei string) (Codec, error) {
+	newCodec, ok := codecs[strings.ToLower(protocol)]
+	if !ok {
+		return nil, fmt.Errorf("unknown protocol %s, expected one of %s", protocol, strings.Join(Protocols(), ", "))
+	}
+	return newCodec(imei)
+}
+
+// expectBytes is an Ack checking the reply is exactly want
+func expectBytes(want []byte) func(r *bufio.Reader) error {
+	return func(r *bufio.Reader) error {
+		got := make([]byte, len(want))
+		if _, err := io.ReadFull(r, got); err != nil {
+			return err
+		}
+		if !bytes.Equal(got, want) {
+			return fmt.Errorf("%w: expected % x, got % x", ErrBadAck, want, got)
+		}
+		return nil
+	}
+}
+
+// allAcks checks the replies to several frames sent together, in order
+func allAcks(acks []func(r *bufio.Reader) error) func(r *bufio.Reader) error {
+	if len(acks) == 0 {
+		return nil
+	}
+	return func(r *bufio.Reader) error {
+		for _, ack := range acks {
+			if err := ack(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
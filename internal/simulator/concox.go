@@ -0,0 +1,149 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+
+	"github.com/404minds/avl-receiver/internal/crc"
+)
+
+// concoxDialect is what sets GT06 and TR06 devices apart
+type concoxDialect struct {
+	terminalType [2]byte // sent in the login packet, the receiver doesn't look at it
+	position     byte
+}
+
+var (
+	// positions with acc, upload mode and mileage after the lbs
+	gt06Dialect = concoxDialect{terminalType: [2]byte{0x22, 0x06}, position: 0x22}
+	// positions end with the lbs, what the receiver's tr06 package parses
+	tr06Dialect = concoxDialect{terminalType: [2]byte{0x36, 0x08}, position: 0x12}
+)
+
+const (
+	concoxLogin     byte = 0x01
+	concoxHeartbeat byte = 0x13
+	concoxAlarm     byte = 0x26
+
+	concoxAlarmOverspeed byte = 0x06
+)
+
+// concox is a GT06 or TR06 device, packets are 78 78 len proto content serial crc 0d 0a and the
+// receiver acks the login and heartbeats with the packet's serial
+type concox struct {
+	imei    string
+	bcdImei []byte
+	dialect concoxDialect
+	serial  uint16
+}
+
+func newConcox(imei string, dialect concoxDialect) (Codec, error) {
+	if len(imei) != 15 {
+		return nil, fmt.Errorf("concox imei must have 15 digits: %s", imei)
+	}
+	bcdImei, err := hex.DecodeString("0" + imei)
+	if err != nil {
+		return nil, fmt.Errorf("concox imei must be digits only: %s", imei)
+	}
+	return &concox{imei: imei, bcdImei: bcdImei, dialect: dialect}, nil
+}
+
+func (c *concox) Imei() string {
+	return c.imei
+}
+
+func (c *concox) Login(Fix) Frame {
+	content := append([]byte{}, c.bcdImei...)
+	content = append(content, c.dialect.terminalType[:]...)
+	content = append(content, 0x00, 0x00) // timezone utc, the fixes are sent in utc
+	return c.packet(concoxLogin, content, true)
+}
+
+func (c *concox) Positions(fixes []Fix) Frame {
+	var frame Frame
+	for _, fix := range fixes {
+		var content bytes.Buffer
+		c.writeGps(&content, fix)
+		c.writeLbs(&content)
+		if c.dialect == gt06Dialect {
+			content.WriteByte(0x01) // acc high
+			content.WriteByte(0x00) // upload at fixed interval
+			content.WriteByte(0x00) // realtime
+			_ = binary.Write(&content, binary.BigEndian, uint32(fix.Odometer))
+		}
+		frame.Data = append(frame.Data, c.packet(c.dialect.position, content.Bytes(), false).Data...)
+	}
+	return frame
+}
+
+func (c *concox) Alarm(fix Fix) Frame {
+	var content bytes.Buffer
+	c.writeGps(&content, fix)
+	content.WriteByte(9) // lbs length, including itself
+	c.writeLbs(&content)
+	content.WriteByte(0x46) // terminal info: gps positioned, acc high
+	content.WriteByte(0x06) // voltage very high
+	content.WriteByte(0x04) // gsm strong
+	content.WriteByte(concoxAlarmOverspeed)
+	content.WriteByte(0x02) // english
+	return c.packet(concoxAlarm, content.Bytes(), false)
+}
+
+func (c *concox) Heartbeat(Fix) Frame {
+	content := []byte{
+		0x46,       // terminal info: gps positioned, acc high
+		0x06,       // voltage very high
+		0x04,       // gsm strong
+		0x00, 0x02, // english
+	}
+	return c.packet(concoxHeartbeat, content, true)
+}
+
+// packet frames the content with the next serial, acked when the receiver answers that type
+func (c *concox) packet(protocol byte, content []byte, acked bool) Frame {
+	c.serial++
+	body := []byte{byte(len(content) + 5), protocol} // proto, serial and crc are counted too
+	body = append(body, content...)
+	body = binary.BigEndian.AppendUint16(body, c.serial)
+
+	data := []byte{0x78, 0x78}
+	data = append(data, body...)
+	data = binary.BigEndian.AppendUint16(data, crc.CrcWanway(body))
+	data = append(data, 0x0d, 0x0a)
+	if !acked {
+		return Frame{Data: data}
+	}
+
+	ack := []byte{0x05, protocol}
+	ack = binary.BigEndian.AppendUint16(ack, c.serial)
+	ack = binary.BigEndian.AppendUint16(ack, crc.CrcWanway(ack))
+	return Frame{Data: data, Ack: expectBytes(append(append([]byte{0x78, 0x78}, ack...), 0x0d, 0x0a))}
+}
+
+func (c *concox) writeGps(w *bytes.Buffer, fix Fix) {
+	t := fix.Time.UTC()
+	w.Write([]byte{byte(t.Year() - 2000), byte(t.Month()), byte(t.Day()), byte(t.Hour()), byte(t.Minute()), byte(t.Second())})
+	w.WriteByte(0xc0 | byte(min(fix.Satellites, 15))) // 12 bytes of gps info
+	_ = binary.Write(w, binary.BigEndian, uint32(math.Round(math.Abs(fix.Lat)*1800000)))
+	_ = binary.Write(w, binary.BigEndian, uint32(math.Round(math.Abs(fix.Lon)*1800000)))
+	w.WriteByte(byte(min(fix.SpeedKmh, 255)))
+
+	course := uint16(0x1000) | uint16(fix.Course)&0x03ff // positioned
+	if fix.Lon < 0 {
+		course |= 0x0800
+	}
+	if fix.Lat >= 0 {
+		course |= 0x0400
+	}
+	_ = binary.Write(w, binary.BigEndian, course)
+}
+
+func (c *concox) writeLbs(w *bytes.Buffer) {
+	_ = binary.Write(w, binary.BigEndian, uint16(404)) // india
+	w.WriteByte(45)
+	_ = binary.Write(w, binary.BigEndian, uint16(0x1f4a))
+	w.Write([]byte{0x00, 0x3b, 0x7c})
+}
@@ -0,0 +1,171 @@
+package simulator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	intellitracPosition  uint16 = 0x01
+	intellitracImpact    uint16 = 0xC7
+	intellitracHeartbeat uint16 = 0xAB
+
+	intellitracIgnition = 1 << 0
+	intellitracMoving   = 1 << 1
+)
+
+// intellitrac is an IntelliTrac A device, either binary, logging in with a heartbeat and sending
+// positions acked with their transaction id, or ascii, logging in with FA F8 and sending comma
+// separated lines acked with transaction id 0
+type intellitrac struct {
+	imei    string
+	modemID uint64
+	ascii   bool
+	txID    uint16
+}
+
+func newIntellitrac(imei string, ascii bool) (Codec, error) {
+	modemID, err := strconv.ParseUint(imei, 10, 64)
+	if err != nil || len(imei) != 15 {
+		return nil, fmt.Errorf("intellitrac imei must have 15 digits: %s", imei)
+	}
+	if ascii {
+		// the ascii heartbeat only has 4 bytes for the modem id, the receiver pads it to 15 digits
+		modemID %= 1000000000
+		imei = fmt.Sprintf("%015d", modemID)
+	}
+	return &intellitrac{imei: imei, modemID: modemID, ascii: ascii}, nil
+}
+
+func (t *intellitrac) Imei() string {
+	return t.imei
+}
+
+func (t *intellitrac) Login(fix Fix) Frame {
+	txID := t.nextTxID()
+	if t.ascii {
+		data := []byte{0xFA, 0xF8}
+		data = binary.BigEndian.AppendUint16(data, txID)
+		data = binary.BigEndian.AppendUint32(data, uint32(t.modemID))
+		return Frame{Data: data, Ack: expectBytes(intellitracAck(txID))}
+	}
+	return t.binary(txID, intellitracHeartbeat, rtc(fix.Time))
+}
+
+func (t *intellitrac) Positions(fixes []Fix) Frame {
+	var frame Frame
+	var acks []func(r *bufio.Reader) error
+	for _, fix := range fixes {
+		var f Frame
+		if t.ascii {
+			f = t.line(fix, intellitracPosition)
+		} else {
+			f = t.binary(t.nextTxID(), intellitracPosition, positionData(fix))
+		}
+		frame.Data = append(frame.Data, f.Data...)
+		acks = append(acks, f.Ack)
+	}
+	frame.Ack = allAcks(acks)
+	return frame
+}
+
+func (t *intellitrac) Alarm(fix Fix) Frame {
+	if t.ascii {
+		return t.line(fix, intellitracImpact)
+	}
+	return t.binary(t.nextTxID(), intellitracImpact, positionData(fix))
+}
+
+// Heartbeat is the binary heartbeat again, ascii devices have none after the login
+func (t *intellitrac) Heartbeat(fix Fix) Frame {
+	if t.ascii {
+		return Frame{}
+	}
+	return t.binary(t.nextTxID(), intellitracHeartbeat, rtc(fix.Time))
+}
+
+// binary is txid, 00 02 (binary, async), modem id, message id, data length and the data
+func (t *intellitrac) binary(txID, messageID uint16, data []byte) Frame {
+	frame := binary.BigEndian.AppendUint16(nil, txID)
+	frame = append(frame, 0x00, 0x02)
+	frame = binary.BigEndian.AppendUint64(frame, t.modemID)
+	frame = binary.BigEndian.AppendUint16(frame, messageID)
+	frame = binary.BigEndian.AppendUint16(frame, uint16(len(data)))
+	frame = append(frame, data...)
+	return Frame{Data: frame, Ack: expectBytes(intellitracAck(txID))}
+}
+
+// line is modem id, time, lon, lat, speed, direction, altitude, satellites, message id, inputs,
+// outputs, analog 1 and 2, odometer and hdop
+func (t *intellitrac) line(fix Fix, messageID uint16) Frame {
+	fields := []string{
+		strconv.FormatUint(t.modemID, 10),
+		fix.Time.UTC().Format("20060102150405"),
+		fmt.Sprintf("%.6f", fix.Lon),
+		fmt.Sprintf("%.6f", fix.Lat),
+		fmt.Sprintf("%.0f", fix.SpeedKmh),
+		fmt.Sprintf("%.0f", fix.Course),
+		fmt.Sprintf("%.0f", fix.Altitude),
+		strconv.Itoa(fix.Satellites),
+		strconv.Itoa(int(messageID)),
+		strconv.Itoa(intellitracIgnition),
+		"0",
+		"12.60",
+		"0.00",
+		strconv.Itoa(int(fix.Odometer)),
+		"0.9",
+	}
+	// the receiver acks every line with transaction id 0
+	return Frame{Data: []byte(strings.Join(fields, ",") + "\r\n"), Ack: expectBytes(intellitracAck(0))}
+}
+
+func (t *intellitrac) nextTxID() uint16 {
+	t.txID++
+	if t.txID == 0 {
+		t.txID = 1
+	}
+	return t.txID
+}
+
+func intellitracAck(txID uint16) []byte {
+	ack := binary.BigEndian.AppendUint16(nil, txID)
+	return append(ack, 0x00, 0x03, 0x00, 0x00) // binary, acknowledge, success
+}
+
+// rtc is hour, minute, second, year, month, day
+func rtc(at time.Time) []byte {
+	at = at.UTC()
+	return []byte{byte(at.Hour()), byte(at.Minute()), byte(at.Second()), byte(at.Year() - 2000), byte(at.Month()), byte(at.Day())}
+}
+
+// positionData is the 46 bytes of a binary position report
+func positionData(fix Fix) []byte {
+	var w bytes.Buffer
+	w.Write(rtc(fix.Time)) // gps time
+	_ = binary.Write(&w, binary.BigEndian, int32(math.Round(fix.Lat*1e5)))
+	_ = binary.Write(&w, binary.BigEndian, int32(math.Round(fix.Lon*1e5)))
+	altitude := int32(fix.Altitude)
+	w.Write([]byte{byte(altitude >> 16), byte(altitude >> 8), byte(altitude)})
+	_ = binary.Write(&w, binary.BigEndian, uint16(math.Round(fix.SpeedKmh/3.6*10)))
+	_ = binary.Write(&w, binary.BigEndian, uint16(math.Round(fix.Course*10)))
+	_ = binary.Write(&w, binary.BigEndian, uint32(fix.Odometer))
+	w.WriteByte(9) // hdop x10
+	w.WriteByte(byte(fix.Satellites))
+	_ = binary.Write(&w, binary.BigEndian, uint16(intellitracIgnition))
+	status := byte(0x01) // engine on
+	if fix.SpeedKmh > 0 {
+		status |= intellitracMoving
+	}
+	w.WriteByte(status)
+	_ = binary.Write(&w, binary.BigEndian, uint16(12600)) // analog 1 in mV
+	_ = binary.Write(&w, binary.BigEndian, uint16(0))
+	w.Write(rtc(fix.Time))
+	w.Write(rtc(fix.Time)) // sending time
+	return w.Bytes()
+}
@@ -0,0 +1,218 @@
+package simulator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const earthRadius = 6371000.0 // meters
+
+// Waypoint is a corner of a route, the speed is kept until the next one
+type Waypoint struct {
+	Lat, Lon float64
+	SpeedKmh float64
+}
+
+// Route is a closed loop of waypoints, devices drive it round and round
+type Route struct {
+	Name      string
+	Waypoints []Waypoint
+	legs      []float64 // length of the leg starting at each waypoint, in meters
+	length    float64
+}
+
+// DefaultRoute is a loop around central Bengaluru, used when no route file is given
+var DefaultRoute = mustRoute("default", []Waypoint{
+	{Lat: 12.971599, Lon: 77.594566, SpeedKmh: 40},
+	{Lat: 12.975880, Lon: 77.605960, SpeedKmh: 35},
+	{Lat: 12.982500, Lon: 77.608690, SpeedKmh: 50},
+	{Lat: 12.991280, Lon: 77.598540, SpeedKmh: 60},
+	{Lat: 12.985560, Lon: 77.583070, SpeedKmh: 45},
+	{Lat: 12.974020, Lon: 77.580640, SpeedKmh: 30},
+})
+
+func mustRoute(name string, waypoints []Waypoint) *Route {
+	route, err := NewRoute(name, waypoints)
+	if err != nil {
+		panic(err)
+	}
+	return route
+}
+
+func NewRoute(name string, waypoints []Waypoint) (*Route, error) {
+	if len(waypoints) < 2 {
+		return nil, fmt.Errorf("route %s needs at least 2 waypoints", name)
+	}
+	r := &Route{Name: name, Waypoints: waypoints, legs: make([]float64, len(waypoints))}
+	for i, w := range waypoints {
+		if w.SpeedKmh <= 0 {
+			return nil, fmt.Errorf("route %s: waypoint %d has no speed", name, i+1)
+		}
+		r.legs[i] = distance(w, waypoints[(i+1)%len(waypoints)])
+		if r.legs[i] < 1 {
+			return nil, fmt.Errorf("route %s: waypoint %d is where the next one is", name, i+1)
+		}
+		r.length += r.legs[i]
+	}
+	return r, nil
+}
+
+// LoadRoute reads a route file of "lat,lon[,speed km/h]" lines, a missing speed is the previous
+// waypoint's. Blank lines and lines starting with # are skipped.
+func LoadRoute(path string) (*Route, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseRoute(path, f)
+}
+
+func ParseRoute(name string, r io.Reader) (*Route, error) {
+	var waypoints []Waypoint
+	speed := 40.0
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("%s:%d: expected lat,lon[,speed]", name, n)
+		}
+		var values [3]float64
+		for i, field := range fields {
+			v, err := strconv.ParseFloat(strings.TrimSpace(field), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", name, n, err)
+			}
+			values[i] = v
+		}
+		if len(fields) == 3 {
+			speed = values[2]
+		}
+		if math.Abs(values[0]) > 90 || math.Abs(values[1]) > 180 {
+			return nil, fmt.Errorf("%s:%d: coordinates out of range", name, n)
+		}
+		waypoints = append(waypoints, Waypoint{Lat: values[0], Lon: values[1], SpeedKmh: speed})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return NewRoute(name, waypoints)
+}
+
+// Length of the loop in meters
+func (r *Route) Length() float64 {
+	return r.length
+}
+
+// Fix is a simulated gps fix
+type Fix struct {
+	Time       time.Time
+	Lat, Lon   float64
+	Altitude   float64 // meters
+	SpeedKmh   float64
+	Course     float64 // degrees from north
+	Satellites int
+	Odometer   float64 // meters driven since the track started
+}
+
+// Track is a device's position on a route
+type Track struct {
+	route    *Route
+	leg      int
+	along    float64 // meters into the leg
+	odometer float64
+}
+
+// Track starts a device the given number of meters into the route, so devices on the same route
+// are spread along it
+func (r *Route) Track(offset float64) *Track {
+	t := &Track{route: r}
+	t.advance(math.Mod(offset, r.length))
+	t.odometer = 0
+	return t
+}
+
+// Next drives the track for the elapsed time and returns the fix at its end
+func (t *Track) Next(at time.Time, elapsed time.Duration) Fix {
+	remaining := elapsed.Seconds()
+	for remaining > 0 {
+		speed := t.route.Waypoints[t.leg].SpeedKmh / 3.6
+		left := t.route.legs[t.leg] - t.along
+		if speed*remaining < left {
+			t.advance(speed * remaining)
+			break
+		}
+		t.advance(left)
+		remaining -= left / speed
+	}
+	return t.fix(at)
+}
+
+// Fix is where the track is now, without moving it
+func (t *Track) Fix(at time.Time) Fix {
+	return t.fix(at)
+}
+
+func (t *Track) advance(meters float64) {
+	t.odometer += meters
+	for meters > 0 {
+		left := t.route.legs[t.leg] - t.along
+		if meters < left {
+			t.along += meters
+			return
+		}
+		meters -= left
+		t.leg = (t.leg + 1) % len(t.route.Waypoints)
+		t.along = 0
+	}
+}
+
+func (t *Track) fix(at time.Time) Fix {
+	from := t.route.Waypoints[t.leg]
+	to := t.route.Waypoints[(t.leg+1)%len(t.route.Waypoints)]
+	frac := 0.0
+	if t.route.legs[t.leg] > 0 {
+		frac = t.along / t.route.legs[t.leg]
+	}
+	return Fix{
+		Time:       at,
+		Lat:        from.Lat + (to.Lat-from.Lat)*frac,
+		Lon:        from.Lon + (to.Lon-from.Lon)*frac,
+		Altitude:   900,
+		SpeedKmh:   from.SpeedKmh,
+		Course:     bearing(from, to),
+		Satellites: 9,
+		Odometer:   t.odometer,
+	}
+}
+
+// distance between waypoints in meters, haversine
+func distance(a, b Waypoint) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLat, dLon := lat2-lat1, radians(b.Lon-a.Lon)
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(h))
+}
+
+// bearing from a to b in degrees from north
+func bearing(a, b Waypoint) float64 {
+	lat1, lat2 := radians(a.Lat), radians(b.Lat)
+	dLon := radians(b.Lon - a.Lon)
+	y := math.Sin(dLon) * math.Cos(lat2)
+	x := math.Cos(lat1)*math.Sin(lat2) - math.Sin(lat1)*math.Cos(lat2)*math.Cos(dLon)
+	return math.Mod(math.Atan2(y, x)*180/math.Pi+360, 360)
+}
+
+func radians(deg float64) float64 {
+	return deg * math.Pi / 180
+}
@@ -0,0 +1,247 @@
+package simulator
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
+	"go.uber.org/zap"
+)
+
+var logger = configuredLogger.Logger
+
+type Config struct {
+	Addr string
+	// Protocols are given to the connections round robin
+	Protocols   []string
+	Connections int
+	// ImeiBase is the first device's imei, the others count up from it
+	ImeiBase uint64
+	Route    *Route
+	// Interval between position reports of a device
+	Interval time.Duration
+	// Batch is the number of fixes in a position report, spread over the interval
+	Batch int
+	// HeartbeatEvery and AlarmEvery send a heartbeat or an alarm after every so many reports,
+	// never if 0
+	HeartbeatEvery int
+	AlarmEvery     int
+	// Reports a device sends before it disconnects, 0 to keep going until the context is done
+	Reports int
+	// Ramp spreads the connects over this long
+	Ramp time.Duration
+	// AckTimeout is how long the receiver has to ack a frame
+	AckTimeout time.Duration
+}
+
+func (c *Config) validate() error {
+	if c.Addr == "" {
+		return errors.New("no address to connect to")
+	}
+	if len(c.Protocols) == 0 {
+		return errors.New("no protocols")
+	}
+	for _, protocol := range c.Protocols {
+		if _, err := CodecFor(protocol, strconv.FormatUint(c.ImeiBase, 10)); err != nil {
+			return err
+		}
+	}
+	if c.ImeiBase+uint64(c.Connections) > 999999999999999 {
+		return fmt.Errorf("imeis from %d don't fit in 15 digits", c.ImeiBase)
+	}
+	if c.Connections <= 0 || c.Batch <= 0 || c.Interval <= 0 {
+		return errors.New("connections, batch and interval must be positive")
+	}
+	return nil
+}
+
+// Stats are counted by all the connections of a run
+type Stats struct {
+	Connected   atomic.Int64 // connections logged in and still sending
+	LoggedIn    atomic.Int64
+	Failed      atomic.Int64 // connections that ended with an error
+	Frames      atomic.Int64
+	Records     atomic.Int64
+	Acks        atomic.Int64
+	AckErrors   atomic.Int64 // acks missing or not what the device expected
+	ackNanos    atomic.Int64
+	maxAckNanos atomic.Int64
+}
+
+func (s *Stats) ack(latency time.Duration) {
+	s.Acks.Add(1)
+	s.ackNanos.Add(int64(latency))
+	for {
+		max := s.maxAckNanos.Load()
+		if int64(latency) <= max || s.maxAckNanos.CompareAndSwap(max, int64(latency)) {
+			return
+		}
+	}
+}
+
+// AckLatency is the mean and the max time the receiver took to ack
+func (s *Stats) AckLatency() (mean, max time.Duration) {
+	if acks := s.Acks.Load(); acks > 0 {
+		mean = time.Duration(s.ackNanos.Load() / acks)
+	}
+	return mean, time.Duration(s.maxAckNanos.Load())
+}
+
+func (s *Stats) String() string {
+	mean, max := s.AckLatency()
+	return fmt.Sprintf("connected=%d loggedIn=%d failed=%d frames=%d records=%d acks=%d ackErrors=%d ackLatency=%s/%s",
+		s.Connected.Load(), s.LoggedIn.Load(), s.Failed.Load(), s.Frames.Load(), s.Records.Load(),
+		s.Acks.Load(), s.AckErrors.Load(), mean.Round(time.Microsecond), max.Round(time.Microsecond))
+}
+
+// Run connects the devices and drives them along the route until they've sent their reports or the
+// context is done. Failing devices are counted in the stats and logged, not returned.
+func Run(ctx context.Context, cfg Config, stats *Stats) error {
+	if cfg.Route == nil {
+		cfg.Route = DefaultRoute
+	}
+	if cfg.AckTimeout <= 0 {
+		cfg.AckTimeout = 10 * time.Second
+	}
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.Connections; i++ {
+		imei := strconv.FormatUint(cfg.ImeiBase+uint64(i), 10)
+		protocol := cfg.Protocols[i%len(cfg.Protocols)]
+		codec, err := CodecFor(protocol, imei)
+		if err != nil {
+			return err
+		}
+		d := &device{
+			cfg:   &cfg,
+			stats: stats,
+			codec: codec,
+			track: cfg.Route.Track(cfg.Route.Length() * float64(i) / float64(cfg.Connections)),
+		}
+		delay := cfg.Ramp * time.Duration(i) / time.Duration(cfg.Connections)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			if err := d.run(ctx); err != nil {
+				stats.Failed.Add(1)
+				logger.Error("device failed", zap.String("imei", codec.Imei()), zap.String("protocol", protocol), zap.Error(err))
+			}
+		}()
+	}
+	wg.Wait()
+	return nil
+}
+
+// device is one simulated device on its own connection
+type device struct {
+	cfg   *Config
+	stats *Stats
+	codec Codec
+	track *Track
+
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func (d *device) run(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: d.cfg.AckTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", d.cfg.Addr)
+	if err != nil {
+		return failed(ctx, "dial", err)
+	}
+	d.conn, d.reader = conn, bufio.NewReader(conn)
+	defer conn.Close()
+	// unblocks a read or write in progress when the run is cancelled
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	last := time.Now()
+	if err := d.send(ctx, d.codec.Login(d.track.Fix(last)), 0); err != nil {
+		return failed(ctx, "login", err)
+	}
+	d.stats.LoggedIn.Add(1)
+	d.stats.Connected.Add(1)
+	defer d.stats.Connected.Add(-1)
+
+	ticker := time.NewTicker(d.cfg.Interval)
+	defer ticker.Stop()
+	for reports := 1; d.cfg.Reports == 0 || reports <= d.cfg.Reports; reports++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			fixes := make([]Fix, d.cfg.Batch)
+			step := now.Sub(last) / time.Duration(d.cfg.Batch)
+			for i := range fixes {
+				fixes[i] = d.track.Next(last.Add(step*time.Duration(i+1)), step)
+			}
+			last = now
+
+			if err := d.send(ctx, d.codec.Positions(fixes), len(fixes)); err != nil {
+				return failed(ctx, "positions", err)
+			}
+			if d.cfg.HeartbeatEvery > 0 && reports%d.cfg.HeartbeatEvery == 0 {
+				if err := d.send(ctx, d.codec.Heartbeat(d.track.Fix(now)), 0); err != nil {
+					return failed(ctx, "heartbeat", err)
+				}
+			}
+			if d.cfg.AlarmEvery > 0 && reports%d.cfg.AlarmEvery == 0 {
+				if err := d.send(ctx, d.codec.Alarm(d.track.Fix(now)), 1); err != nil {
+					return failed(ctx, "alarm", err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// send writes the frame and checks the receiver's ack, if the protocol has one
+func (d *device) send(ctx context.Context, frame Frame, records int) error {
+	if len(frame.Data) == 0 {
+		return nil
+	}
+	_ = d.conn.SetWriteDeadline(time.Now().Add(d.cfg.AckTimeout))
+	sent := time.Now()
+	if _, err := d.conn.Write(frame.Data); err != nil {
+		return err
+	}
+	d.stats.Frames.Add(1)
+	d.stats.Records.Add(int64(records))
+	if frame.Ack == nil {
+		return nil
+	}
+
+	_ = d.conn.SetReadDeadline(sent.Add(d.cfg.AckTimeout))
+	if err := frame.Ack(d.reader); err != nil {
+		if ctx.Err() == nil {
+			d.stats.AckErrors.Add(1)
+		}
+		return fmt.Errorf("ack: %w", err)
+	}
+	d.stats.ack(time.Since(sent))
+	return nil
+}
+
+// failed hides the error of a connection closed because the run was cancelled
+func failed(ctx context.Context, what string, err error) error {
+	if ctx.Err() != nil {
+		return nil
+	}
+	return fmt.Errorf("%s: %w", what, err)
+}
@@ -0,0 +1,220 @@
+package simulator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/handlers"
+	devices "github.com/404minds/avl-receiver/internal/protocols"
+	"github.com/404minds/avl-receiver/internal/store"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouteTrack(t *testing.T) {
+	route := DefaultRoute
+	track := route.Track(0)
+	start := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := track.Fix(start)
+	assert.Equal(t, route.Waypoints[0].Lat, first.Lat)
+	assert.Equal(t, route.Waypoints[0].Lon, first.Lon)
+
+	// a minute at 40 km/h is 666 m along the first leg
+	fix := track.Next(start.Add(time.Minute), time.Minute)
+	assert.InDelta(t, 40/3.6*60, fix.Odometer, 0.001)
+	assert.InDelta(t, 40/3.6*60, distance(route.Waypoints[0], Waypoint{Lat: fix.Lat, Lon: fix.Lon}), 5)
+	assert.Equal(t, start.Add(time.Minute), fix.Time)
+
+	// devices spread along the route start on different legs
+	assert.NotEqual(t, route.Track(0).Fix(start), route.Track(route.Length()/2).Fix(start))
+}
+
+func TestParseRoute(t *testing.T) {
+	route, err := ParseRoute("test", strings.NewReader("# loop\n12.97,77.59,30\n\n12.98,77.60\n12.99,77.58,50\n"))
+	require.NoError(t, err)
+	require.Len(t, route.Waypoints, 3)
+	assert.Equal(t, 30.0, route.Waypoints[1].SpeedKmh, "the speed carries over")
+	assert.Equal(t, 50.0, route.Waypoints[2].SpeedKmh)
+
+	_, err = ParseRoute("test", strings.NewReader("12.97,77.59\n"))
+	assert.Error(t, err)
+	_, err = ParseRoute("test", strings.NewReader("12.97,77.59\n12.97,77.59\n"))
+	assert.Error(t, err, "a leg without length")
+	_, err = ParseRoute("test", strings.NewReader("97,77.59\n12.97,77.59\n"))
+	assert.Error(t, err)
+}
+
+// collectingStore keeps the statuses the protocol decodes
+type collectingStore struct {
+	processChan       chan *types.DeviceStatus
+	responseChan      chan *types.DeviceResponse
+	closeChan         chan bool
+	closeResponseChan chan bool
+}
+
+func newCollectingStore() *collectingStore {
+	return &collectingStore{
+		processChan:       make(chan *types.DeviceStatus, 100),
+		responseChan:      make(chan *types.DeviceResponse, 100),
+		closeChan:         make(chan bool, 1),
+		closeResponseChan: make(chan bool, 1),
+	}
+}
+
+func (s *collectingStore) statuses() []*types.DeviceStatus {
+	var statuses []*types.DeviceStatus
+	for {
+		select {
+		case status := <-s.processChan:
+			statuses = append(statuses, status)
+		default:
+			return statuses
+		}
+	}
+}
+
+func (s *collectingStore) Process(ctx context.Context)                 {}
+func (s *collectingStore) Response(ctx context.Context)                {}
+func (s *collectingStore) GetProcessChan() chan *types.DeviceStatus    { return s.processChan }
+func (s *collectingStore) GetResponseChan() chan *types.DeviceResponse { return s.responseChan }
+func (s *collectingStore) GetCloseChan() chan bool                     { return s.closeChan }
+func (s *collectingStore) GetCloseResponseChan() chan bool             { return s.closeResponseChan }
+
+var protocolTypes = map[string]types.DeviceProtocolType{
+	"codec8":            types.DeviceProtocolType_FM1200,
+	"codec8e":           types.DeviceProtocolType_FM1200,
+	"gt06":              types.DeviceProtocolType_GT06,
+	"tr06":              types.DeviceProtocolType_TR06,
+	"aquila":            types.DeviceProtocolType_OBDII2G,
+	"intellitrac":       types.DeviceProtocolType_INTELLITRAC_A,
+	"intellitrac-ascii": types.DeviceProtocolType_INTELLITRAC_A,
+}
+
+// TestCodecsDecode runs every codec's frames through the receiver's protocol and checks the acks it
+// writes back are the ones the simulated device expects
+func TestCodecsDecode(t *testing.T) {
+	assert.Len(t, protocolTypes, len(Protocols()))
+	start := time.Now().UTC().Truncate(time.Second)
+
+	for _, name := range Protocols() {
+		t.Run(name, func(t *testing.T) {
+			codec, err := CodecFor(name, "861234567890123")
+			require.NoError(t, err)
+			track := DefaultRoute.Track(100)
+			fixes := []Fix{
+				track.Next(start.Add(10*time.Second), 10*time.Second),
+				track.Next(start.Add(20*time.Second), 10*time.Second),
+			}
+			frames := []Frame{
+				codec.Login(track.Fix(start)),
+				codec.Positions(fixes),
+				codec.Heartbeat(fixes[1]),
+				codec.Alarm(fixes[1]),
+			}
+			var stream []byte
+			for _, frame := range frames {
+				stream = append(stream, frame.Data...)
+			}
+
+			protocol := devices.MakeProtocolForType(protocolTypes[name])
+			require.NotNil(t, protocol)
+			reader := bufio.NewReader(bytes.NewReader(stream))
+			ack, bytesToSkip, err := protocol.Login(reader)
+			require.NoError(t, err)
+			_, err = reader.Discard(bytesToSkip)
+			require.NoError(t, err)
+			assert.Equal(t, codec.Imei(), protocol.GetDeviceID())
+
+			acks := bytes.NewBuffer(ack)
+			dataStore := newCollectingStore()
+			err = protocol.ConsumeStream(reader, acks, dataStore)
+			if err != nil {
+				require.ErrorIs(t, err, io.EOF)
+			}
+
+			ackReader := bufio.NewReader(acks)
+			for i, frame := range frames {
+				if frame.Ack != nil {
+					assert.NoError(t, frame.Ack(ackReader), "ack of frame %d", i)
+				}
+			}
+			assert.Zero(t, ackReader.Buffered(), "unexpected acks")
+
+			statuses := dataStore.statuses()
+			require.NotEmpty(t, statuses)
+			for _, status := range statuses {
+				assert.Equal(t, codec.Imei(), status.Imei)
+			}
+			if name == "intellitrac-ascii" {
+				return // the receiver doesn't decode ascii positions yet, only acks them
+			}
+			for _, fix := range fixes {
+				assert.True(t, slices.ContainsFunc(statuses, func(status *types.DeviceStatus) bool {
+					return status.Position != nil &&
+						math.Abs(float64(status.Position.Latitude)-fix.Lat) < 0.001 &&
+						math.Abs(float64(status.Position.Longitude)-fix.Lon) < 0.001
+				}), "no status at %f,%f", fix.Lat, fix.Lon)
+			}
+		})
+	}
+}
+
+// TestRunAgainstReceiver drives every protocol against a tcp handler with a local store
+func TestRunAgainstReceiver(t *testing.T) {
+	if testing.Short() {
+		t.Skip("talks to a receiver over tcp")
+	}
+	handler := handlers.NewTcpHandler(store.CustomAvlDataStoreClient{}, "local", handlers.Sinks{Local: store.RotationConfig{Dir: t.TempDir()}})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			if err == nil {
+				go handler.HandleConnection(conn)
+			}
+		}
+	}()
+
+	// tr06 devices log in as GT06, which drops them on their first 0x12 position
+	protocols := slices.DeleteFunc(Protocols(), func(name string) bool { return name == "tr06" })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	var stats Stats
+	err = Run(ctx, Config{
+		Addr:           listener.Addr().String(),
+		Protocols:      protocols,
+		Connections:    len(protocols),
+		ImeiBase:       861234567890000,
+		Interval:       50 * time.Millisecond,
+		Batch:          2,
+		HeartbeatEvery: 1,
+		AlarmEvery:     2,
+		Reports:        2,
+	}, &stats)
+	require.NoError(t, err)
+	require.NoError(t, ctx.Err())
+
+	n := int64(len(protocols))
+	assert.Equal(t, n, stats.LoggedIn.Load(), stats.String())
+	assert.Zero(t, stats.Failed.Load(), stats.String())
+	assert.Zero(t, stats.AckErrors.Load(), stats.String())
+	assert.Zero(t, stats.Connected.Load())
+	assert.Equal(t, n*2*2+n, stats.Records.Load(), "two reports of two fixes and an alarm each")
+	assert.Positive(t, stats.Acks.Load())
+}
@@ -0,0 +1,181 @@
+package simulator
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/404minds/avl-receiver/internal/crc"
+)
+
+const (
+	codec8  byte = 0x08
+	codec8E byte = 0x8E
+
+	// records per teltonika packet, the count is a byte
+	maxTeltonikaRecords = 255
+)
+
+// io element ids, see fm1200.IOProperty
+const (
+	ioGsmSignal       = 21
+	ioExternalVoltage = 66
+	ioOdometer        = 16
+	ioIgnition        = 239
+	ioMovement        = 240
+	ioOverspeeding    = 255
+)
+
+// teltonika is an FMxxxx device: the imei handshake acked with 0x01, then avl packets acked with
+// their record count
+type teltonika struct {
+	imei  string
+	codec byte
+}
+
+func newTeltonika(imei string, codec byte) (Codec, error) {
+	if len(imei) != 15 {
+		return nil, fmt.Errorf("teltonika imei must have 15 digits: %s", imei)
+	}
+	return &teltonika{imei: imei, codec: codec}, nil
+}
+
+func (t *teltonika) Imei() string {
+	return t.imei
+}
+
+func (t *teltonika) Login(Fix) Frame {
+	data := binary.BigEndian.AppendUint16(nil, uint16(len(t.imei)))
+	data = append(data, t.imei...)
+	return Frame{Data: data, Ack: func(r *bufio.Reader) error {
+		b, err := r.ReadByte()
+		if err != nil {
+			return err
+		}
+		if b != 0x01 {
+			return fmt.Errorf("%w: imei rejected with %#02x", ErrBadAck, b)
+		}
+		return nil
+	}}
+}
+
+func (t *teltonika) Positions(fixes []Fix) Frame {
+	var frame Frame
+	var acks []func(r *bufio.Reader) error
+	for len(fixes) > 0 {
+		n := min(len(fixes), maxTeltonikaRecords)
+		data, ack := t.packet(fixes[:n], false)
+		frame.Data = append(frame.Data, data...)
+		acks = append(acks, ack)
+		fixes = fixes[n:]
+	}
+	frame.Ack = allAcks(acks)
+	return frame
+}
+
+func (t *teltonika) Alarm(fix Fix) Frame {
+	data, ack := t.packet([]Fix{fix}, true)
+	return Frame{Data: data, Ack: ack}
+}
+
+// Heartbeat is nothing, teltonika devices keep the connection up with their records
+func (t *teltonika) Heartbeat(Fix) Frame {
+	return Frame{}
+}
+
+// packet is a codec 8 or 8E avl data packet, acked with the number of records as 4 bytes
+func (t *teltonika) packet(fixes []Fix, alarm bool) ([]byte, func(r *bufio.Reader) error) {
+	var body bytes.Buffer
+	body.WriteByte(t.codec)
+	body.WriteByte(byte(len(fixes)))
+	for _, fix := range fixes {
+		t.writeRecord(&body, fix, alarm)
+	}
+	body.WriteByte(byte(len(fixes)))
+
+	var packet bytes.Buffer
+	_ = binary.Write(&packet, binary.BigEndian, uint32(0)) // preamble
+	_ = binary.Write(&packet, binary.BigEndian, uint32(body.Len()))
+	packet.Write(body.Bytes())
+	_ = binary.Write(&packet, binary.BigEndian, uint32(crc.CrcTeltonika(body.Bytes())))
+
+	want := uint32(len(fixes))
+	return packet.Bytes(), func(r *bufio.Reader) error {
+		var got uint32
+		if err := binary.Read(r, binary.BigEndian, &got); err != nil {
+			return err
+		}
+		if got != want {
+			return fmt.Errorf("%w: %d records acked, sent %d", ErrBadAck, got, want)
+		}
+		return nil
+	}
+}
+
+type ioElement struct {
+	id    uint16
+	value uint64
+}
+
+func (t *teltonika) writeRecord(w *bytes.Buffer, fix Fix, alarm bool) {
+	_ = binary.Write(w, binary.BigEndian, uint64(fix.Time.UnixMilli()))
+	priority, eventID := byte(0), uint16(0)
+	if alarm {
+		priority, eventID = 2, ioOverspeeding // panic priority
+	}
+	w.WriteByte(priority)
+
+	_ = binary.Write(w, binary.BigEndian, int32(math.Round(fix.Lon*1e7)))
+	_ = binary.Write(w, binary.BigEndian, int32(math.Round(fix.Lat*1e7)))
+	_ = binary.Write(w, binary.BigEndian, uint16(fix.Altitude))
+	_ = binary.Write(w, binary.BigEndian, uint16(fix.Course))
+	w.WriteByte(byte(fix.Satellites))
+	_ = binary.Write(w, binary.BigEndian, uint16(fix.SpeedKmh))
+
+	moving := uint64(0)
+	if fix.SpeedKmh > 0 {
+		moving = 1
+	}
+	oneByte := []ioElement{{ioIgnition, 1}, {ioMovement, moving}, {ioGsmSignal, 4}}
+	if alarm {
+		oneByte = append(oneByte, ioElement{ioOverspeeding, 1})
+	}
+	groups := [][]ioElement{
+		oneByte,
+		{{ioExternalVoltage, 12600}},
+		{{ioOdometer, uint64(fix.Odometer)}},
+		nil, // 8 byte elements
+	}
+	sizes := []int{1, 2, 4, 8}
+
+	total := 0
+	for _, g := range groups {
+		total += len(g)
+	}
+	t.writeCount(w, eventID)
+	t.writeCount(w, uint16(total))
+	for i, g := range groups {
+		t.writeCount(w, uint16(len(g)))
+		for _, e := range g {
+			t.writeCount(w, e.id)
+			var value [8]byte
+			binary.BigEndian.PutUint64(value[:], e.value)
+			w.Write(value[8-sizes[i]:])
+		}
+	}
+	if t.codec == codec8E {
+		t.writeCount(w, 0) // variable length elements
+	}
+}
+
+// writeCount writes counts and ids, a byte in codec 8 and two in codec 8E
+func (t *teltonika) writeCount(w io.Writer, n uint16) {
+	if t.codec == codec8E {
+		_ = binary.Write(w, binary.BigEndian, n)
+		return
+	}
+	_ = binary.Write(w, binary.BigEndian, uint8(n))
+}
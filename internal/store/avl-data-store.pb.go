@@ -11,6 +11,7 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -22,6 +23,229 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type CommandDeliveryStatus int32
+
+const (
+	CommandDeliveryStatus_DELIVERY_UNKNOWN   CommandDeliveryStatus = 0
+	CommandDeliveryStatus_DELIVERY_ACK       CommandDeliveryStatus = 1
+	CommandDeliveryStatus_DELIVERY_NACK      CommandDeliveryStatus = 2
+	CommandDeliveryStatus_DELIVERY_NO_REPLY  CommandDeliveryStatus = 3 // sent, but the device didn't reply in time
+	CommandDeliveryStatus_DELIVERY_EXPIRED   CommandDeliveryStatus = 4 // the ttl ran out before the device came online
+	CommandDeliveryStatus_DELIVERY_CANCELLED CommandDeliveryStatus = 5
+	CommandDeliveryStatus_DELIVERY_FAILED    CommandDeliveryStatus = 6 // could not be written to the device after all attempts
+)
+
+// Enum value maps for CommandDeliveryStatus.
+var (
+	CommandDeliveryStatus_name = map[int32]string{
+		0: "DELIVERY_UNKNOWN",
+		1: "DELIVERY_ACK",
+		2: "DELIVERY_NACK",
+		3: "DELIVERY_NO_REPLY",
+		4: "DELIVERY_EXPIRED",
+		5: "DELIVERY_CANCELLED",
+		6: "DELIVERY_FAILED",
+	}
+	CommandDeliveryStatus_value = map[string]int32{
+		"DELIVERY_UNKNOWN":   0,
+		"DELIVERY_ACK":       1,
+		"DELIVERY_NACK":      2,
+		"DELIVERY_NO_REPLY":  3,
+		"DELIVERY_EXPIRED":   4,
+		"DELIVERY_CANCELLED": 5,
+		"DELIVERY_FAILED":    6,
+	}
+)
+
+func (x CommandDeliveryStatus) Enum() *CommandDeliveryStatus {
+	p := new(CommandDeliveryStatus)
+	*p = x
+	return p
+}
+
+func (x CommandDeliveryStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CommandDeliveryStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_avl_data_store_proto_enumTypes[0].Descriptor()
+}
+
+func (CommandDeliveryStatus) Type() protoreflect.EnumType {
+	return &file_avl_data_store_proto_enumTypes[0]
+}
+
+func (x CommandDeliveryStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CommandDeliveryStatus.Descriptor instead.
+func (CommandDeliveryStatus) EnumDescriptor() ([]byte, []int) {
+	return file_avl_data_store_proto_rawDescGZIP(), []int{0}
+}
+
+type DeviceLimitReason int32
+
+const (
+	DeviceLimitReason_LIMIT_UNKNOWN         DeviceLimitReason = 0
+	DeviceLimitReason_LIMIT_RECORD_RATE     DeviceLimitReason = 1 // more records a second than the per imei limit, the extra ones were dropped
+	DeviceLimitReason_LIMIT_CONNECTION_RATE DeviceLimitReason = 2 // reconnecting faster than the per ip limit
+	DeviceLimitReason_LIMIT_BANNED          DeviceLimitReason = 3 // turned away by a ban on its imei or ip
+)
+
+// Enum value maps for DeviceLimitReason.
+var (
+	DeviceLimitReason_name = map[int32]string{
+		0: "LIMIT_UNKNOWN",
+		1: "LIMIT_RECORD_RATE",
+		2: "LIMIT_CONNECTION_RATE",
+		3: "LIMIT_BANNED",
+	}
+	DeviceLimitReason_value = map[string]int32{
+		"LIMIT_UNKNOWN":         0,
+		"LIMIT_RECORD_RATE":     1,
+		"LIMIT_CONNECTION_RATE": 2,
+		"LIMIT_BANNED":          3,
+	}
+)
+
+func (x DeviceLimitReason) Enum() *DeviceLimitReason {
+	p := new(DeviceLimitReason)
+	*p = x
+	return p
+}
+
+func (x DeviceLimitReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (DeviceLimitReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_avl_data_store_proto_enumTypes[1].Descriptor()
+}
+
+func (DeviceLimitReason) Type() protoreflect.EnumType {
+	return &file_avl_data_store_proto_enumTypes[1]
+}
+
+func (x DeviceLimitReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use DeviceLimitReason.Descriptor instead.
+func (DeviceLimitReason) EnumDescriptor() ([]byte, []int) {
+	return file_avl_data_store_proto_rawDescGZIP(), []int{1}
+}
+
+type SessionEventType int32
+
+const (
+	SessionEventType_SESSION_EVENT_UNKNOWN SessionEventType = 0
+	SessionEventType_SESSION_CONNECTED     SessionEventType = 1 // the device logged in
+	SessionEventType_SESSION_DISCONNECTED  SessionEventType = 2 // its connection ended, or its login was rejected
+)
+
+// Enum value maps for SessionEventType.
+var (
+	SessionEventType_name = map[int32]string{
+		0: "SESSION_EVENT_UNKNOWN",
+		1: "SESSION_CONNECTED",
+		2: "SESSION_DISCONNECTED",
+	}
+	SessionEventType_value = map[string]int32{
+		"SESSION_EVENT_UNKNOWN": 0,
+		"SESSION_CONNECTED":     1,
+		"SESSION_DISCONNECTED":  2,
+	}
+)
+
+func (x SessionEventType) Enum() *SessionEventType {
+	p := new(SessionEventType)
+	*p = x
+	return p
+}
+
+func (x SessionEventType) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SessionEventType) Descriptor() protoreflect.EnumDescriptor {
+	return file_avl_data_store_proto_enumTypes[2].Descriptor()
+}
+
+func (SessionEventType) Type() protoreflect.EnumType {
+	return &file_avl_data_store_proto_enumTypes[2]
+}
+
+func (x SessionEventType) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SessionEventType.Descriptor instead.
+func (SessionEventType) EnumDescriptor() ([]byte, []int) {
+	return file_avl_data_store_proto_rawDescGZIP(), []int{2}
+}
+
+type SessionEndReason int32
+
+const (
+	SessionEndReason_END_UNKNOWN     SessionEndReason = 0
+	SessionEndReason_END_EOF         SessionEndReason = 1 // the device closed the connection
+	SessionEndReason_END_TIMEOUT     SessionEndReason = 2 // nothing read from the device before the read deadline
+	SessionEndReason_END_PARSE_ERROR SessionEndReason = 3 // a packet that couldn't be decoded, a bad crc or a frame too large
+	SessionEndReason_END_AUTH_FAILED SessionEndReason = 4 // the login was rejected, the device is unauthorized or banned
+	SessionEndReason_END_CLOSED      SessionEndReason = 5 // closed by the receiver, for a newer connection of the device, a ban or a disconnect request
+	SessionEndReason_END_ERROR       SessionEndReason = 6 // any other read or write error
+)
+
+// Enum value maps for SessionEndReason.
+var (
+	SessionEndReason_name = map[int32]string{
+		0: "END_UNKNOWN",
+		1: "END_EOF",
+		2: "END_TIMEOUT",
+		3: "END_PARSE_ERROR",
+		4: "END_AUTH_FAILED",
+		5: "END_CLOSED",
+		6: "END_ERROR",
+	}
+	SessionEndReason_value = map[string]int32{
+		"END_UNKNOWN":     0,
+		"END_EOF":         1,
+		"END_TIMEOUT":     2,
+		"END_PARSE_ERROR": 3,
+		"END_AUTH_FAILED": 4,
+		"END_CLOSED":      5,
+		"END_ERROR":       6,
+	}
+)
+
+func (x SessionEndReason) Enum() *SessionEndReason {
+	p := new(SessionEndReason)
+	*p = x
+	return p
+}
+
+func (x SessionEndReason) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SessionEndReason) Descriptor() protoreflect.EnumDescriptor {
+	return file_avl_data_store_proto_enumTypes[3].Descriptor()
+}
+
+func (SessionEndReason) Type() protoreflect.EnumType {
+	return &file_avl_data_store_proto_enumTypes[3]
+}
+
+func (x SessionEndReason) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SessionEndReason.Descriptor instead.
+func (SessionEndReason) EnumDescriptor() ([]byte, []int) {
+	return file_avl_data_store_proto_rawDescGZIP(), []int{3}
+}
+
 type FetchDeviceModelRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Imei          string                 `protobuf:"bytes,1,opt,name=imei,proto3" json:"imei,omitempty"`
@@ -206,6 +430,334 @@ func (x *VerifyDeviceReply) GetDeviceType() types.DeviceType {
 	return types.DeviceType(0)
 }
 
+type CommandDelivery struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Imei          string                 `protobuf:"bytes,1,opt,name=imei,proto3" json:"imei,omitempty"`
+	CommandId     string                 `protobuf:"bytes,2,opt,name=command_id,json=commandId,proto3" json:"command_id,omitempty"`
+	Command       string                 `protobuf:"bytes,3,opt,name=command,proto3" json:"command,omitempty"`
+	Status        CommandDeliveryStatus  `protobuf:"varint,4,opt,name=status,proto3,enum=store.CommandDeliveryStatus" json:"status,omitempty"`
+	Response      string                 `protobuf:"bytes,5,opt,name=response,proto3" json:"response,omitempty"`
+	EnqueuedAt    *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=enqueued_at,json=enqueuedAt,proto3" json:"enqueued_at,omitempty"`
+	ReportedAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=reported_at,json=reportedAt,proto3" json:"reported_at,omitempty"`
+	Attempts      uint32                 `protobuf:"varint,8,opt,name=attempts,proto3" json:"attempts,omitempty"`
+	LatencyMs     uint32                 `protobuf:"varint,9,opt,name=latency_ms,json=latencyMs,proto3" json:"latency_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommandDelivery) Reset() {
+	*x = CommandDelivery{}
+	mi := &file_avl_data_store_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommandDelivery) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommandDelivery) ProtoMessage() {}
+
+func (x *CommandDelivery) ProtoReflect() protoreflect.Message {
+	mi := &file_avl_data_store_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommandDelivery.ProtoReflect.Descriptor instead.
+func (*CommandDelivery) Descriptor() ([]byte, []int) {
+	return file_avl_data_store_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CommandDelivery) GetImei() string {
+	if x != nil {
+		return x.Imei
+	}
+	return ""
+}
+
+func (x *CommandDelivery) GetCommandId() string {
+	if x != nil {
+		return x.CommandId
+	}
+	return ""
+}
+
+func (x *CommandDelivery) GetCommand() string {
+	if x != nil {
+		return x.Command
+	}
+	return ""
+}
+
+func (x *CommandDelivery) GetStatus() CommandDeliveryStatus {
+	if x != nil {
+		return x.Status
+	}
+	return CommandDeliveryStatus_DELIVERY_UNKNOWN
+}
+
+func (x *CommandDelivery) GetResponse() string {
+	if x != nil {
+		return x.Response
+	}
+	return ""
+}
+
+func (x *CommandDelivery) GetEnqueuedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EnqueuedAt
+	}
+	return nil
+}
+
+func (x *CommandDelivery) GetReportedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReportedAt
+	}
+	return nil
+}
+
+func (x *CommandDelivery) GetAttempts() uint32 {
+	if x != nil {
+		return x.Attempts
+	}
+	return 0
+}
+
+func (x *CommandDelivery) GetLatencyMs() uint32 {
+	if x != nil {
+		return x.LatencyMs
+	}
+	return 0
+}
+
+// a device the receiver limited, sent at most once a minute per device and reason so the devices
+// can be configured properly
+type DeviceLimited struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Imei           string                 `protobuf:"bytes,1,opt,name=imei,proto3" json:"imei,omitempty"` // empty for connections limited before their login
+	RemoteIp       string                 `protobuf:"bytes,2,opt,name=remote_ip,json=remoteIp,proto3" json:"remote_ip,omitempty"`
+	Reason         DeviceLimitReason      `protobuf:"varint,3,opt,name=reason,proto3,enum=store.DeviceLimitReason" json:"reason,omitempty"`
+	Dropped        uint32                 `protobuf:"varint,4,opt,name=dropped,proto3" json:"dropped,omitempty"` // records or connections dropped since the last report
+	FirstDroppedAt *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=first_dropped_at,json=firstDroppedAt,proto3" json:"first_dropped_at,omitempty"`
+	ReportedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=reported_at,json=reportedAt,proto3" json:"reported_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *DeviceLimited) Reset() {
+	*x = DeviceLimited{}
+	mi := &file_avl_data_store_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceLimited) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceLimited) ProtoMessage() {}
+
+func (x *DeviceLimited) ProtoReflect() protoreflect.Message {
+	mi := &file_avl_data_store_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceLimited.ProtoReflect.Descriptor instead.
+func (*DeviceLimited) Descriptor() ([]byte, []int) {
+	return file_avl_data_store_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DeviceLimited) GetImei() string {
+	if x != nil {
+		return x.Imei
+	}
+	return ""
+}
+
+func (x *DeviceLimited) GetRemoteIp() string {
+	if x != nil {
+		return x.RemoteIp
+	}
+	return ""
+}
+
+func (x *DeviceLimited) GetReason() DeviceLimitReason {
+	if x != nil {
+		return x.Reason
+	}
+	return DeviceLimitReason_LIMIT_UNKNOWN
+}
+
+func (x *DeviceLimited) GetDropped() uint32 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+func (x *DeviceLimited) GetFirstDroppedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstDroppedAt
+	}
+	return nil
+}
+
+func (x *DeviceLimited) GetReportedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ReportedAt
+	}
+	return nil
+}
+
+// a device connecting or disconnecting, for its connectivity history. The two are sent
+// separately so a disconnect carries everything about the session.
+type DeviceSessionEvent struct {
+	state           protoimpl.MessageState   `protogen:"open.v1"`
+	Event           SessionEventType         `protobuf:"varint,1,opt,name=event,proto3,enum=store.SessionEventType" json:"event,omitempty"`
+	Imei            string                   `protobuf:"bytes,2,opt,name=imei,proto3" json:"imei,omitempty"` // empty for the howen websocket, which carries all its devices
+	RemoteAddr      string                   `protobuf:"bytes,3,opt,name=remote_addr,json=remoteAddr,proto3" json:"remote_addr,omitempty"`
+	Protocol        types.DeviceProtocolType `protobuf:"varint,4,opt,name=protocol,proto3,enum=types.DeviceProtocolType" json:"protocol,omitempty"`
+	DeviceType      types.DeviceType         `protobuf:"varint,5,opt,name=device_type,json=deviceType,proto3,enum=types.DeviceType" json:"device_type,omitempty"`
+	ConnectedAt     *timestamppb.Timestamp   `protobuf:"bytes,6,opt,name=connected_at,json=connectedAt,proto3" json:"connected_at,omitempty"`
+	DisconnectedAt  *timestamppb.Timestamp   `protobuf:"bytes,7,opt,name=disconnected_at,json=disconnectedAt,proto3" json:"disconnected_at,omitempty"` // the rest is only set on disconnects
+	DurationMs      uint64                   `protobuf:"varint,8,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	BytesReceived   uint64                   `protobuf:"varint,9,opt,name=bytes_received,json=bytesReceived,proto3" json:"bytes_received,omitempty"`
+	PacketsReceived uint64                   `protobuf:"varint,10,opt,name=packets_received,json=packetsReceived,proto3" json:"packets_received,omitempty"` // decoded device statuses
+	Reason          SessionEndReason         `protobuf:"varint,11,opt,name=reason,proto3,enum=store.SessionEndReason" json:"reason,omitempty"`
+	Error           string                   `protobuf:"bytes,12,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DeviceSessionEvent) Reset() {
+	*x = DeviceSessionEvent{}
+	mi := &file_avl_data_store_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceSessionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceSessionEvent) ProtoMessage() {}
+
+func (x *DeviceSessionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_avl_data_store_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceSessionEvent.ProtoReflect.Descriptor instead.
+func (*DeviceSessionEvent) Descriptor() ([]byte, []int) {
+	return file_avl_data_store_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeviceSessionEvent) GetEvent() SessionEventType {
+	if x != nil {
+		return x.Event
+	}
+	return SessionEventType_SESSION_EVENT_UNKNOWN
+}
+
+func (x *DeviceSessionEvent) GetImei() string {
+	if x != nil {
+		return x.Imei
+	}
+	return ""
+}
+
+func (x *DeviceSessionEvent) GetRemoteAddr() string {
+	if x != nil {
+		return x.RemoteAddr
+	}
+	return ""
+}
+
+func (x *DeviceSessionEvent) GetProtocol() types.DeviceProtocolType {
+	if x != nil {
+		return x.Protocol
+	}
+	return types.DeviceProtocolType(0)
+}
+
+func (x *DeviceSessionEvent) GetDeviceType() types.DeviceType {
+	if x != nil {
+		return x.DeviceType
+	}
+	return types.DeviceType(0)
+}
+
+func (x *DeviceSessionEvent) GetConnectedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ConnectedAt
+	}
+	return nil
+}
+
+func (x *DeviceSessionEvent) GetDisconnectedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DisconnectedAt
+	}
+	return nil
+}
+
+func (x *DeviceSessionEvent) GetDurationMs() uint64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *DeviceSessionEvent) GetBytesReceived() uint64 {
+	if x != nil {
+		return x.BytesReceived
+	}
+	return 0
+}
+
+func (x *DeviceSessionEvent) GetPacketsReceived() uint64 {
+	if x != nil {
+		return x.PacketsReceived
+	}
+	return 0
+}
+
+func (x *DeviceSessionEvent) GetReason() SessionEndReason {
+	if x != nil {
+		return x.Reason
+	}
+	return SessionEndReason_END_UNKNOWN
+}
+
+func (x *DeviceSessionEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 var File_avl_data_store_proto protoreflect.FileDescriptor
 
 var file_avl_data_store_proto_rawDesc = []byte{
@@ -213,46 +765,167 @@ var file_avl_data_store_proto_rawDesc = []byte{
 	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x05, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x1a, 0x1b, 0x67,
 	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65,
 	0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x12, 0x63, 0x6f, 0x6d, 0x6d,
-	0x6f, 0x6e, 0x2d, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x2d,
-	0x0a, 0x17, 0x46, 0x65, 0x74, 0x63, 0x68, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4d, 0x6f, 0x64,
-	0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x6d, 0x65,
-	0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x69, 0x6d, 0x65, 0x69, 0x22, 0x30, 0x0a,
-	0x18, 0x46, 0x65, 0x74, 0x63, 0x68, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4d, 0x6f, 0x64, 0x65,
-	0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f, 0x64,
-	0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x22,
-	0x29, 0x0a, 0x13, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x6d, 0x65, 0x69, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x69, 0x6d, 0x65, 0x69, 0x22, 0x5a, 0x0a, 0x11, 0x56, 0x65,
-	0x72, 0x69, 0x66, 0x79, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x12,
-	0x12, 0x0a, 0x04, 0x69, 0x6d, 0x65, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x69,
-	0x6d, 0x65, 0x69, 0x12, 0x31, 0x0a, 0x0a, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x54, 0x79, 0x70,
-	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e,
-	0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x64, 0x65, 0x76, 0x69,
-	0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x32, 0xb7, 0x02, 0x0a, 0x0c, 0x41, 0x76, 0x6c, 0x44, 0x61,
-	0x74, 0x61, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x46, 0x0a, 0x0c, 0x56, 0x65, 0x72, 0x69, 0x66,
-	0x79, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x1a, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e,
-	0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75,
-	0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x56, 0x65, 0x72, 0x69,
-	0x66, 0x79, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12,
-	0x41, 0x0a, 0x10, 0x53, 0x61, 0x76, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61,
-	0x74, 0x75, 0x73, 0x12, 0x13, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x44, 0x65, 0x76, 0x69,
-	0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
-	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79,
-	0x22, 0x00, 0x12, 0x45, 0x0a, 0x12, 0x53, 0x61, 0x76, 0x65, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x15, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73,
-	0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x1a,
+	0x6f, 0x6e, 0x2d, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f,
+	0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
+	0x2d, 0x0a, 0x17, 0x46, 0x65, 0x74, 0x63, 0x68, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4d, 0x6f,
+	0x64, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x6d,
+	0x65, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x69, 0x6d, 0x65, 0x69, 0x22, 0x30,
+	0x0a, 0x18, 0x46, 0x65, 0x74, 0x63, 0x68, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4d, 0x6f, 0x64,
+	0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x6f,
+	0x64, 0x65, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6d, 0x6f, 0x64, 0x65, 0x6c,
+	0x22, 0x29, 0x0a, 0x13, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x6d, 0x65, 0x69, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x69, 0x6d, 0x65, 0x69, 0x22, 0x5a, 0x0a, 0x11, 0x56,
+	0x65, 0x72, 0x69, 0x66, 0x79, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x65, 0x70, 0x6c, 0x79,
+	0x12, 0x12, 0x0a, 0x04, 0x69, 0x6d, 0x65, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04,
+	0x69, 0x6d, 0x65, 0x69, 0x12, 0x31, 0x0a, 0x0a, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x54, 0x79,
+	0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73,
+	0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x22, 0xe5, 0x02, 0x0a, 0x0f, 0x43, 0x6f, 0x6d, 0x6d,
+	0x61, 0x6e, 0x64, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x12, 0x12, 0x0a, 0x04, 0x69,
+	0x6d, 0x65, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x69, 0x6d, 0x65, 0x69, 0x12,
+	0x1d, 0x0a, 0x0a, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x49, 0x64, 0x12, 0x18,
+	0x0a, 0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x63, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x12, 0x34, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1c, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x65,
+	0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x1a,
+	0x0a, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x08, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x3b, 0x0a, 0x0b, 0x65, 0x6e,
+	0x71, 0x75, 0x65, 0x75, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x65, 0x6e, 0x71,
+	0x75, 0x65, 0x75, 0x65, 0x64, 0x41, 0x74, 0x12, 0x3b, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6f, 0x72,
+	0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74,
+	0x65, 0x64, 0x41, 0x74, 0x12, 0x1a, 0x0a, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73,
+	0x18, 0x08, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x08, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73,
+	0x12, 0x1d, 0x0a, 0x0a, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x5f, 0x6d, 0x73, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x0d, 0x52, 0x09, 0x6c, 0x61, 0x74, 0x65, 0x6e, 0x63, 0x79, 0x4d, 0x73, 0x22,
+	0x8f, 0x02, 0x0a, 0x0d, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x65,
+	0x64, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x6d, 0x65, 0x69, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x04, 0x69, 0x6d, 0x65, 0x69, 0x12, 0x1b, 0x0a, 0x09, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f,
+	0x69, 0x70, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65,
+	0x49, 0x70, 0x12, 0x30, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x0e, 0x32, 0x18, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63,
+	0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x06, 0x72, 0x65,
+	0x61, 0x73, 0x6f, 0x6e, 0x12, 0x18, 0x0a, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x07, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x12, 0x44,
+	0x0a, 0x10, 0x66, 0x69, 0x72, 0x73, 0x74, 0x5f, 0x64, 0x72, 0x6f, 0x70, 0x70, 0x65, 0x64, 0x5f,
+	0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c,
+	0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73,
+	0x74, 0x61, 0x6d, 0x70, 0x52, 0x0e, 0x66, 0x69, 0x72, 0x73, 0x74, 0x44, 0x72, 0x6f, 0x70, 0x70,
+	0x65, 0x64, 0x41, 0x74, 0x12, 0x3b, 0x0a, 0x0b, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64,
+	0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0a, 0x72, 0x65, 0x70, 0x6f, 0x72, 0x74, 0x65, 0x64, 0x41,
+	0x74, 0x22, 0xa1, 0x04, 0x0a, 0x12, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x53, 0x65, 0x73, 0x73,
+	0x69, 0x6f, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x2d, 0x0a, 0x05, 0x65, 0x76, 0x65, 0x6e,
+	0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e,
+	0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70, 0x65,
+	0x52, 0x05, 0x65, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x69, 0x6d, 0x65, 0x69, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x69, 0x6d, 0x65, 0x69, 0x12, 0x1f, 0x0a, 0x0b, 0x72,
+	0x65, 0x6d, 0x6f, 0x74, 0x65, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x72, 0x65, 0x6d, 0x6f, 0x74, 0x65, 0x41, 0x64, 0x64, 0x72, 0x12, 0x35, 0x0a, 0x08,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x19,
+	0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x50, 0x72, 0x6f,
+	0x74, 0x6f, 0x63, 0x6f, 0x6c, 0x54, 0x79, 0x70, 0x65, 0x52, 0x08, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x63, 0x6f, 0x6c, 0x12, 0x32, 0x0a, 0x0b, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x5f, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73,
+	0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x52, 0x0a, 0x64, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x54, 0x79, 0x70, 0x65, 0x12, 0x3d, 0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x6e, 0x65,
+	0x63, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x43, 0x0a, 0x0f, 0x64, 0x69, 0x73, 0x63, 0x6f, 0x6e,
+	0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
+	0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x0e, 0x64, 0x69, 0x73,
+	0x63, 0x6f, 0x6e, 0x6e, 0x65, 0x63, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x64,
+	0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04,
+	0x52, 0x0a, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x25, 0x0a, 0x0e,
+	0x62, 0x79, 0x74, 0x65, 0x73, 0x5f, 0x72, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x0d, 0x62, 0x79, 0x74, 0x65, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69,
+	0x76, 0x65, 0x64, 0x12, 0x29, 0x0a, 0x10, 0x70, 0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x5f, 0x72,
+	0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x04, 0x52, 0x0f, 0x70,
+	0x61, 0x63, 0x6b, 0x65, 0x74, 0x73, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x64, 0x12, 0x2f,
+	0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x17,
+	0x2e, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x45, 0x6e,
+	0x64, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12,
+	0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x2a, 0xac, 0x01, 0x0a, 0x15, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e,
+	0x64, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x14, 0x0a, 0x10, 0x44, 0x45, 0x4c, 0x49, 0x56, 0x45, 0x52, 0x59, 0x5f, 0x55, 0x4e, 0x4b, 0x4e,
+	0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x10, 0x0a, 0x0c, 0x44, 0x45, 0x4c, 0x49, 0x56, 0x45, 0x52,
+	0x59, 0x5f, 0x41, 0x43, 0x4b, 0x10, 0x01, 0x12, 0x11, 0x0a, 0x0d, 0x44, 0x45, 0x4c, 0x49, 0x56,
+	0x45, 0x52, 0x59, 0x5f, 0x4e, 0x41, 0x43, 0x4b, 0x10, 0x02, 0x12, 0x15, 0x0a, 0x11, 0x44, 0x45,
+	0x4c, 0x49, 0x56, 0x45, 0x52, 0x59, 0x5f, 0x4e, 0x4f, 0x5f, 0x52, 0x45, 0x50, 0x4c, 0x59, 0x10,
+	0x03, 0x12, 0x14, 0x0a, 0x10, 0x44, 0x45, 0x4c, 0x49, 0x56, 0x45, 0x52, 0x59, 0x5f, 0x45, 0x58,
+	0x50, 0x49, 0x52, 0x45, 0x44, 0x10, 0x04, 0x12, 0x16, 0x0a, 0x12, 0x44, 0x45, 0x4c, 0x49, 0x56,
+	0x45, 0x52, 0x59, 0x5f, 0x43, 0x41, 0x4e, 0x43, 0x45, 0x4c, 0x4c, 0x45, 0x44, 0x10, 0x05, 0x12,
+	0x13, 0x0a, 0x0f, 0x44, 0x45, 0x4c, 0x49, 0x56, 0x45, 0x52, 0x59, 0x5f, 0x46, 0x41, 0x49, 0x4c,
+	0x45, 0x44, 0x10, 0x06, 0x2a, 0x6a, 0x0a, 0x11, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4c, 0x69,
+	0x6d, 0x69, 0x74, 0x52, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x11, 0x0a, 0x0d, 0x4c, 0x49, 0x4d,
+	0x49, 0x54, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x15, 0x0a, 0x11,
+	0x4c, 0x49, 0x4d, 0x49, 0x54, 0x5f, 0x52, 0x45, 0x43, 0x4f, 0x52, 0x44, 0x5f, 0x52, 0x41, 0x54,
+	0x45, 0x10, 0x01, 0x12, 0x19, 0x0a, 0x15, 0x4c, 0x49, 0x4d, 0x49, 0x54, 0x5f, 0x43, 0x4f, 0x4e,
+	0x4e, 0x45, 0x43, 0x54, 0x49, 0x4f, 0x4e, 0x5f, 0x52, 0x41, 0x54, 0x45, 0x10, 0x02, 0x12, 0x10,
+	0x0a, 0x0c, 0x4c, 0x49, 0x4d, 0x49, 0x54, 0x5f, 0x42, 0x41, 0x4e, 0x4e, 0x45, 0x44, 0x10, 0x03,
+	0x2a, 0x5e, 0x0a, 0x10, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x45, 0x76, 0x65, 0x6e, 0x74,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x19, 0x0a, 0x15, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f,
+	0x45, 0x56, 0x45, 0x4e, 0x54, 0x5f, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12,
+	0x15, 0x0a, 0x11, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f, 0x4e, 0x5f, 0x43, 0x4f, 0x4e, 0x4e, 0x45,
+	0x43, 0x54, 0x45, 0x44, 0x10, 0x01, 0x12, 0x18, 0x0a, 0x14, 0x53, 0x45, 0x53, 0x53, 0x49, 0x4f,
+	0x4e, 0x5f, 0x44, 0x49, 0x53, 0x43, 0x4f, 0x4e, 0x4e, 0x45, 0x43, 0x54, 0x45, 0x44, 0x10, 0x02,
+	0x2a, 0x8a, 0x01, 0x0a, 0x10, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x45, 0x6e, 0x64, 0x52,
+	0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x0f, 0x0a, 0x0b, 0x45, 0x4e, 0x44, 0x5f, 0x55, 0x4e, 0x4b,
+	0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00, 0x12, 0x0b, 0x0a, 0x07, 0x45, 0x4e, 0x44, 0x5f, 0x45, 0x4f,
+	0x46, 0x10, 0x01, 0x12, 0x0f, 0x0a, 0x0b, 0x45, 0x4e, 0x44, 0x5f, 0x54, 0x49, 0x4d, 0x45, 0x4f,
+	0x55, 0x54, 0x10, 0x02, 0x12, 0x13, 0x0a, 0x0f, 0x45, 0x4e, 0x44, 0x5f, 0x50, 0x41, 0x52, 0x53,
+	0x45, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x03, 0x12, 0x13, 0x0a, 0x0f, 0x45, 0x4e, 0x44,
+	0x5f, 0x41, 0x55, 0x54, 0x48, 0x5f, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x04, 0x12, 0x0e,
+	0x0a, 0x0a, 0x45, 0x4e, 0x44, 0x5f, 0x43, 0x4c, 0x4f, 0x53, 0x45, 0x44, 0x10, 0x05, 0x12, 0x0d,
+	0x0a, 0x09, 0x45, 0x4e, 0x44, 0x5f, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x10, 0x06, 0x32, 0x8f, 0x04,
+	0x0a, 0x0c, 0x41, 0x76, 0x6c, 0x44, 0x61, 0x74, 0x61, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x46,
+	0x0a, 0x0c, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x12, 0x1a,
+	0x2e, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x44, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x73, 0x74, 0x6f,
+	0x72, 0x65, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52,
+	0x65, 0x70, 0x6c, 0x79, 0x22, 0x00, 0x12, 0x41, 0x0a, 0x10, 0x53, 0x61, 0x76, 0x65, 0x44, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x13, 0x2e, 0x74, 0x79, 0x70,
+	0x65, 0x73, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x1a,
 	0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75,
-	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x55, 0x0a, 0x10, 0x46, 0x65, 0x74,
-	0x63, 0x68, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x12, 0x1e, 0x2e,
-	0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x46, 0x65, 0x74, 0x63, 0x68, 0x44, 0x65, 0x76, 0x69, 0x63,
-	0x65, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e,
-	0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x46, 0x65, 0x74, 0x63, 0x68, 0x44, 0x65, 0x76, 0x69, 0x63,
-	0x65, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
-	0x42, 0x37, 0x5a, 0x35, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x34,
-	0x30, 0x34, 0x6d, 0x69, 0x6e, 0x64, 0x73, 0x2f, 0x61, 0x76, 0x6c, 0x2d, 0x72, 0x65, 0x63, 0x65,
-	0x69, 0x76, 0x65, 0x72, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x73, 0x74,
-	0x6f, 0x72, 0x65, 0x3b, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x33,
+	0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x45, 0x0a, 0x12, 0x53, 0x61, 0x76,
+	0x65, 0x64, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x15, 0x2e, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00,
+	0x12, 0x55, 0x0a, 0x10, 0x46, 0x65, 0x74, 0x63, 0x68, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4d,
+	0x6f, 0x64, 0x65, 0x6c, 0x12, 0x1e, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x46, 0x65, 0x74,
+	0x63, 0x68, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x46, 0x65, 0x74,
+	0x63, 0x68, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4d, 0x6f, 0x64, 0x65, 0x6c, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x47, 0x0a, 0x13, 0x53, 0x61, 0x76, 0x65, 0x43,
+	0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x44, 0x65, 0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x12, 0x16,
+	0x2e, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x44, 0x65,
+	0x6c, 0x69, 0x76, 0x65, 0x72, 0x79, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00,
+	0x12, 0x43, 0x0a, 0x11, 0x53, 0x61, 0x76, 0x65, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x4c, 0x69,
+	0x6d, 0x69, 0x74, 0x65, 0x64, 0x12, 0x14, 0x2e, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x2e, 0x44, 0x65,
+	0x76, 0x69, 0x63, 0x65, 0x4c, 0x69, 0x6d, 0x69, 0x74, 0x65, 0x64, 0x1a, 0x16, 0x2e, 0x67, 0x6f,
+	0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d,
+	0x70, 0x74, 0x79, 0x22, 0x00, 0x12, 0x48, 0x0a, 0x11, 0x53, 0x61, 0x76, 0x65, 0x44, 0x65, 0x76,
+	0x69, 0x63, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x19, 0x2e, 0x73, 0x74, 0x6f,
+	0x72, 0x65, 0x2e, 0x44, 0x65, 0x76, 0x69, 0x63, 0x65, 0x53, 0x65, 0x73, 0x73, 0x69, 0x6f, 0x6e,
+	0x45, 0x76, 0x65, 0x6e, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x22, 0x00, 0x42,
+	0x37, 0x5a, 0x35, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x34, 0x30,
+	0x34, 0x6d, 0x69, 0x6e, 0x64, 0x73, 0x2f, 0x61, 0x76, 0x6c, 0x2d, 0x72, 0x65, 0x63, 0x65, 0x69,
+	0x76, 0x65, 0x72, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x73, 0x74, 0x6f,
+	0x72, 0x65, 0x3b, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -267,32 +940,60 @@ func file_avl_data_store_proto_rawDescGZIP() []byte {
 	return file_avl_data_store_proto_rawDescData
 }
 
-var file_avl_data_store_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_avl_data_store_proto_enumTypes = make([]protoimpl.EnumInfo, 4)
+var file_avl_data_store_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_avl_data_store_proto_goTypes = []any{
-	(*FetchDeviceModelRequest)(nil),  // 0: store.FetchDeviceModelRequest
-	(*FetchDeviceModelResponse)(nil), // 1: store.FetchDeviceModelResponse
-	(*VerifyDeviceRequest)(nil),      // 2: store.VerifyDeviceRequest
-	(*VerifyDeviceReply)(nil),        // 3: store.VerifyDeviceReply
-	(types.DeviceType)(0),            // 4: types.DeviceType
-	(*types.DeviceStatus)(nil),       // 5: types.DeviceStatus
-	(*types.DeviceResponse)(nil),     // 6: types.DeviceResponse
-	(*emptypb.Empty)(nil),            // 7: google.protobuf.Empty
+	(CommandDeliveryStatus)(0),       // 0: store.CommandDeliveryStatus
+	(DeviceLimitReason)(0),           // 1: store.DeviceLimitReason
+	(SessionEventType)(0),            // 2: store.SessionEventType
+	(SessionEndReason)(0),            // 3: store.SessionEndReason
+	(*FetchDeviceModelRequest)(nil),  // 4: store.FetchDeviceModelRequest
+	(*FetchDeviceModelResponse)(nil), // 5: store.FetchDeviceModelResponse
+	(*VerifyDeviceRequest)(nil),      // 6: store.VerifyDeviceRequest
+	(*VerifyDeviceReply)(nil),        // 7: store.VerifyDeviceReply
+	(*CommandDelivery)(nil),          // 8: store.CommandDelivery
+	(*DeviceLimited)(nil),            // 9: store.DeviceLimited
+	(*DeviceSessionEvent)(nil),       // 10: store.DeviceSessionEvent
+	(types.DeviceType)(0),            // 11: types.DeviceType
+	(*timestamppb.Timestamp)(nil),    // 12: google.protobuf.Timestamp
+	(types.DeviceProtocolType)(0),    // 13: types.DeviceProtocolType
+	(*types.DeviceStatus)(nil),       // 14: types.DeviceStatus
+	(*types.DeviceResponse)(nil),     // 15: types.DeviceResponse
+	(*emptypb.Empty)(nil),            // 16: google.protobuf.Empty
 }
 var file_avl_data_store_proto_depIdxs = []int32{
-	4, // 0: store.VerifyDeviceReply.deviceType:type_name -> types.DeviceType
-	2, // 1: store.AvlDataStore.VerifyDevice:input_type -> store.VerifyDeviceRequest
-	5, // 2: store.AvlDataStore.SaveDeviceStatus:input_type -> types.DeviceStatus
-	6, // 3: store.AvlDataStore.SavedeviceResponse:input_type -> types.DeviceResponse
-	0, // 4: store.AvlDataStore.FetchDeviceModel:input_type -> store.FetchDeviceModelRequest
-	3, // 5: store.AvlDataStore.VerifyDevice:output_type -> store.VerifyDeviceReply
-	7, // 6: store.AvlDataStore.SaveDeviceStatus:output_type -> google.protobuf.Empty
-	7, // 7: store.AvlDataStore.SavedeviceResponse:output_type -> google.protobuf.Empty
-	1, // 8: store.AvlDataStore.FetchDeviceModel:output_type -> store.FetchDeviceModelResponse
-	5, // [5:9] is the sub-list for method output_type
-	1, // [1:5] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	11, // 0: store.VerifyDeviceReply.deviceType:type_name -> types.DeviceType
+	0,  // 1: store.CommandDelivery.status:type_name -> store.CommandDeliveryStatus
+	12, // 2: store.CommandDelivery.enqueued_at:type_name -> google.protobuf.Timestamp
+	12, // 3: store.CommandDelivery.reported_at:type_name -> google.protobuf.Timestamp
+	1,  // 4: store.DeviceLimited.reason:type_name -> store.DeviceLimitReason
+	12, // 5: store.DeviceLimited.first_dropped_at:type_name -> google.protobuf.Timestamp
+	12, // 6: store.DeviceLimited.reported_at:type_name -> google.protobuf.Timestamp
+	2,  // 7: store.DeviceSessionEvent.event:type_name -> store.SessionEventType
+	13, // 8: store.DeviceSessionEvent.protocol:type_name -> types.DeviceProtocolType
+	11, // 9: store.DeviceSessionEvent.device_type:type_name -> types.DeviceType
+	12, // 10: store.DeviceSessionEvent.connected_at:type_name -> google.protobuf.Timestamp
+	12, // 11: store.DeviceSessionEvent.disconnected_at:type_name -> google.protobuf.Timestamp
+	3,  // 12: store.DeviceSessionEvent.reason:type_name -> store.SessionEndReason
+	6,  // 13: store.AvlDataStore.VerifyDevice:input_type -> store.VerifyDeviceRequest
+	14, // 14: store.AvlDataStore.SaveDeviceStatus:input_type -> types.DeviceStatus
+	15, // 15: store.AvlDataStore.SavedeviceResponse:input_type -> types.DeviceResponse
+	4,  // 16: store.AvlDataStore.FetchDeviceModel:input_type -> store.FetchDeviceModelRequest
+	8,  // 17: store.AvlDataStore.SaveCommandDelivery:input_type -> store.CommandDelivery
+	9,  // 18: store.AvlDataStore.SaveDeviceLimited:input_type -> store.DeviceLimited
+	10, // 19: store.AvlDataStore.SaveDeviceSession:input_type -> store.DeviceSessionEvent
+	7,  // 20: store.AvlDataStore.VerifyDevice:output_type -> store.VerifyDeviceReply
+	16, // 21: store.AvlDataStore.SaveDeviceStatus:output_type -> google.protobuf.Empty
+	16, // 22: store.AvlDataStore.SavedeviceResponse:output_type -> google.protobuf.Empty
+	5,  // 23: store.AvlDataStore.FetchDeviceModel:output_type -> store.FetchDeviceModelResponse
+	16, // 24: store.AvlDataStore.SaveCommandDelivery:output_type -> google.protobuf.Empty
+	16, // 25: store.AvlDataStore.SaveDeviceLimited:output_type -> google.protobuf.Empty
+	16, // 26: store.AvlDataStore.SaveDeviceSession:output_type -> google.protobuf.Empty
+	20, // [20:27] is the sub-list for method output_type
+	13, // [13:20] is the sub-list for method input_type
+	13, // [13:13] is the sub-list for extension type_name
+	13, // [13:13] is the sub-list for extension extendee
+	0,  // [0:13] is the sub-list for field type_name
 }
 
 func init() { file_avl_data_store_proto_init() }
@@ -305,13 +1006,14 @@ func file_avl_data_store_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_avl_data_store_proto_rawDesc,
-			NumEnums:      0,
-			NumMessages:   4,
+			NumEnums:      4,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_avl_data_store_proto_goTypes,
 		DependencyIndexes: file_avl_data_store_proto_depIdxs,
+		EnumInfos:         file_avl_data_store_proto_enumTypes,
 		MessageInfos:      file_avl_data_store_proto_msgTypes,
 	}.Build()
 	File_avl_data_store_proto = out.File
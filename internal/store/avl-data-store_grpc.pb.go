@@ -21,10 +21,13 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AvlDataStore_VerifyDevice_FullMethodName       = "/store.AvlDataStore/VerifyDevice"
-	AvlDataStore_SaveDeviceStatus_FullMethodName   = "/store.AvlDataStore/SaveDeviceStatus"
-	AvlDataStore_SavedeviceResponse_FullMethodName = "/store.AvlDataStore/SavedeviceResponse"
-	AvlDataStore_FetchDeviceModel_FullMethodName   = "/store.AvlDataStore/FetchDeviceModel"
+	AvlDataStore_VerifyDevice_FullMethodName        = "/store.AvlDataStore/VerifyDevice"
+	AvlDataStore_SaveDeviceStatus_FullMethodName    = "/store.AvlDataStore/SaveDeviceStatus"
+	AvlDataStore_SavedeviceResponse_FullMethodName  = "/store.AvlDataStore/SavedeviceResponse"
+	AvlDataStore_FetchDeviceModel_FullMethodName    = "/store.AvlDataStore/FetchDeviceModel"
+	AvlDataStore_SaveCommandDelivery_FullMethodName = "/store.AvlDataStore/SaveCommandDelivery"
+	AvlDataStore_SaveDeviceLimited_FullMethodName   = "/store.AvlDataStore/SaveDeviceLimited"
+	AvlDataStore_SaveDeviceSession_FullMethodName   = "/store.AvlDataStore/SaveDeviceSession"
 )
 
 // AvlDataStoreClient is the client API for AvlDataStore service.
@@ -35,6 +38,9 @@ type AvlDataStoreClient interface {
 	SaveDeviceStatus(ctx context.Context, in *types.DeviceStatus, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	SavedeviceResponse(ctx context.Context, in *types.DeviceResponse, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	FetchDeviceModel(ctx context.Context, in *FetchDeviceModelRequest, opts ...grpc.CallOption) (*FetchDeviceModelResponse, error)
+	SaveCommandDelivery(ctx context.Context, in *CommandDelivery, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SaveDeviceLimited(ctx context.Context, in *DeviceLimited, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SaveDeviceSession(ctx context.Context, in *DeviceSessionEvent, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type avlDataStoreClient struct {
@@ -85,6 +91,36 @@ func (c *avlDataStoreClient) FetchDeviceModel(ctx context.Context, in *FetchDevi
 	return out, nil
 }
 
+func (c *avlDataStoreClient) SaveCommandDelivery(ctx context.Context, in *CommandDelivery, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, AvlDataStore_SaveCommandDelivery_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *avlDataStoreClient) SaveDeviceLimited(ctx context.Context, in *DeviceLimited, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, AvlDataStore_SaveDeviceLimited_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *avlDataStoreClient) SaveDeviceSession(ctx context.Context, in *DeviceSessionEvent, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, AvlDataStore_SaveDeviceSession_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AvlDataStoreServer is the server API for AvlDataStore service.
 // All implementations must embed UnimplementedAvlDataStoreServer
 // for forward compatibility.
@@ -93,6 +129,9 @@ type AvlDataStoreServer interface {
 	SaveDeviceStatus(context.Context, *types.DeviceStatus) (*emptypb.Empty, error)
 	SavedeviceResponse(context.Context, *types.DeviceResponse) (*emptypb.Empty, error)
 	FetchDeviceModel(context.Context, *FetchDeviceModelRequest) (*FetchDeviceModelResponse, error)
+	SaveCommandDelivery(context.Context, *CommandDelivery) (*emptypb.Empty, error)
+	SaveDeviceLimited(context.Context, *DeviceLimited) (*emptypb.Empty, error)
+	SaveDeviceSession(context.Context, *DeviceSessionEvent) (*emptypb.Empty, error)
 	mustEmbedUnimplementedAvlDataStoreServer()
 }
 
@@ -115,6 +154,15 @@ func (UnimplementedAvlDataStoreServer) SavedeviceResponse(context.Context, *type
 func (UnimplementedAvlDataStoreServer) FetchDeviceModel(context.Context, *FetchDeviceModelRequest) (*FetchDeviceModelResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method FetchDeviceModel not implemented")
 }
+func (UnimplementedAvlDataStoreServer) SaveCommandDelivery(context.Context, *CommandDelivery) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveCommandDelivery not implemented")
+}
+func (UnimplementedAvlDataStoreServer) SaveDeviceLimited(context.Context, *DeviceLimited) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveDeviceLimited not implemented")
+}
+func (UnimplementedAvlDataStoreServer) SaveDeviceSession(context.Context, *DeviceSessionEvent) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SaveDeviceSession not implemented")
+}
 func (UnimplementedAvlDataStoreServer) mustEmbedUnimplementedAvlDataStoreServer() {}
 func (UnimplementedAvlDataStoreServer) testEmbeddedByValue()                      {}
 
@@ -208,6 +256,60 @@ func _AvlDataStore_FetchDeviceModel_Handler(srv interface{}, ctx context.Context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AvlDataStore_SaveCommandDelivery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommandDelivery)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlDataStoreServer).SaveCommandDelivery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlDataStore_SaveCommandDelivery_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlDataStoreServer).SaveCommandDelivery(ctx, req.(*CommandDelivery))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvlDataStore_SaveDeviceLimited_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeviceLimited)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlDataStoreServer).SaveDeviceLimited(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlDataStore_SaveDeviceLimited_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlDataStoreServer).SaveDeviceLimited(ctx, req.(*DeviceLimited))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvlDataStore_SaveDeviceSession_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeviceSessionEvent)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlDataStoreServer).SaveDeviceSession(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlDataStore_SaveDeviceSession_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlDataStoreServer).SaveDeviceSession(ctx, req.(*DeviceSessionEvent))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AvlDataStore_ServiceDesc is the grpc.ServiceDesc for AvlDataStore service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -231,6 +333,18 @@ var AvlDataStore_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "FetchDeviceModel",
 			Handler:    _AvlDataStore_FetchDeviceModel_Handler,
 		},
+		{
+			MethodName: "SaveCommandDelivery",
+			Handler:    _AvlDataStore_SaveCommandDelivery_Handler,
+		},
+		{
+			MethodName: "SaveDeviceLimited",
+			Handler:    _AvlDataStore_SaveDeviceLimited_Handler,
+		},
+		{
+			MethodName: "SaveDeviceSession",
+			Handler:    _AvlDataStore_SaveDeviceSession_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "avl-data-store.proto",
@@ -8,6 +8,7 @@ package store
 
 import (
 	context "context"
+	types "github.com/404minds/avl-receiver/internal/types"
 	grpc "google.golang.org/grpc"
 	codes "google.golang.org/grpc/codes"
 	status "google.golang.org/grpc/status"
@@ -19,7 +20,21 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AvlReceiverService_SendCommand_FullMethodName = "/store.AvlReceiverService/SendCommand"
+	AvlReceiverService_SendCommand_FullMethodName           = "/store.AvlReceiverService/SendCommand"
+	AvlReceiverService_EnqueueCommand_FullMethodName        = "/store.AvlReceiverService/EnqueueCommand"
+	AvlReceiverService_ListQueuedCommands_FullMethodName    = "/store.AvlReceiverService/ListQueuedCommands"
+	AvlReceiverService_CancelQueuedCommand_FullMethodName   = "/store.AvlReceiverService/CancelQueuedCommand"
+	AvlReceiverService_SubscribePositions_FullMethodName    = "/store.AvlReceiverService/SubscribePositions"
+	AvlReceiverService_ListConnections_FullMethodName       = "/store.AvlReceiverService/ListConnections"
+	AvlReceiverService_GetConnection_FullMethodName         = "/store.AvlReceiverService/GetConnection"
+	AvlReceiverService_DisconnectDevice_FullMethodName      = "/store.AvlReceiverService/DisconnectDevice"
+	AvlReceiverService_InvalidateDeviceCache_FullMethodName = "/store.AvlReceiverService/InvalidateDeviceCache"
+	AvlReceiverService_GetLogSettings_FullMethodName        = "/store.AvlReceiverService/GetLogSettings"
+	AvlReceiverService_SetLogLevel_FullMethodName           = "/store.AvlReceiverService/SetLogLevel"
+	AvlReceiverService_TraceDevice_FullMethodName           = "/store.AvlReceiverService/TraceDevice"
+	AvlReceiverService_AddBan_FullMethodName                = "/store.AvlReceiverService/AddBan"
+	AvlReceiverService_RemoveBan_FullMethodName             = "/store.AvlReceiverService/RemoveBan"
+	AvlReceiverService_ListBans_FullMethodName              = "/store.AvlReceiverService/ListBans"
 )
 
 // AvlReceiverServiceClient is the client API for AvlReceiverService service.
@@ -27,6 +42,30 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type AvlReceiverServiceClient interface {
 	SendCommand(ctx context.Context, in *SendCommandRequestAVL, opts ...grpc.CallOption) (*SendCommandResponseAVL, error)
+	// commands for offline devices, delivered after their next login
+	EnqueueCommand(ctx context.Context, in *EnqueueCommandRequest, opts ...grpc.CallOption) (*QueuedCommand, error)
+	ListQueuedCommands(ctx context.Context, in *ListQueuedCommandsRequest, opts ...grpc.CallOption) (*ListQueuedCommandsResponse, error)
+	CancelQueuedCommand(ctx context.Context, in *CancelQueuedCommandRequest, opts ...grpc.CallOption) (*QueuedCommand, error)
+	// live feed of decoded device statuses, the stream ends with RESOURCE_EXHAUSTED
+	// when the subscriber can't keep up and its buffer fills
+	SubscribePositions(ctx context.Context, in *SubscribePositionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[types.DeviceStatus], error)
+	// devices connected over tcp
+	ListConnections(ctx context.Context, in *ListConnectionsRequest, opts ...grpc.CallOption) (*ListConnectionsResponse, error)
+	GetConnection(ctx context.Context, in *GetConnectionRequest, opts ...grpc.CallOption) (*DeviceConnection, error)
+	// closes the device's session, returns the connection as it was just before
+	DisconnectDevice(ctx context.Context, in *DisconnectDeviceRequest, opts ...grpc.CallOption) (*DeviceConnection, error)
+	// drops cached device verifications so the next login of the devices asks the data store,
+	// call it after provisioning or revoking a device
+	InvalidateDeviceCache(ctx context.Context, in *InvalidateDeviceCacheRequest, opts ...grpc.CallOption) (*InvalidateDeviceCacheResponse, error)
+	// logging, e.g. to trace one device's traffic without debug logs for every other device
+	GetLogSettings(ctx context.Context, in *GetLogSettingsRequest, opts ...grpc.CallOption) (*LogSettings, error)
+	SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*LogSettings, error)
+	TraceDevice(ctx context.Context, in *TraceDeviceRequest, opts ...grpc.CallOption) (*LogSettings, error)
+	// temporary bans of misbehaving devices by imei or ip, their connections are closed and new ones
+	// turned away until the ban expires
+	AddBan(ctx context.Context, in *AddBanRequest, opts ...grpc.CallOption) (*Ban, error)
+	RemoveBan(ctx context.Context, in *RemoveBanRequest, opts ...grpc.CallOption) (*Ban, error)
+	ListBans(ctx context.Context, in *ListBansRequest, opts ...grpc.CallOption) (*ListBansResponse, error)
 }
 
 type avlReceiverServiceClient struct {
@@ -47,11 +86,184 @@ func (c *avlReceiverServiceClient) SendCommand(ctx context.Context, in *SendComm
 	return out, nil
 }
 
+func (c *avlReceiverServiceClient) EnqueueCommand(ctx context.Context, in *EnqueueCommandRequest, opts ...grpc.CallOption) (*QueuedCommand, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueuedCommand)
+	err := c.cc.Invoke(ctx, AvlReceiverService_EnqueueCommand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *avlReceiverServiceClient) ListQueuedCommands(ctx context.Context, in *ListQueuedCommandsRequest, opts ...grpc.CallOption) (*ListQueuedCommandsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListQueuedCommandsResponse)
+	err := c.cc.Invoke(ctx, AvlReceiverService_ListQueuedCommands_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *avlReceiverServiceClient) CancelQueuedCommand(ctx context.Context, in *CancelQueuedCommandRequest, opts ...grpc.CallOption) (*QueuedCommand, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueuedCommand)
+	err := c.cc.Invoke(ctx, AvlReceiverService_CancelQueuedCommand_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *avlReceiverServiceClient) SubscribePositions(ctx context.Context, in *SubscribePositionsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[types.DeviceStatus], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AvlReceiverService_ServiceDesc.Streams[0], AvlReceiverService_SubscribePositions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribePositionsRequest, types.DeviceStatus]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AvlReceiverService_SubscribePositionsClient = grpc.ServerStreamingClient[types.DeviceStatus]
+
+func (c *avlReceiverServiceClient) ListConnections(ctx context.Context, in *ListConnectionsRequest, opts ...grpc.CallOption) (*ListConnectionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListConnectionsResponse)
+	err := c.cc.Invoke(ctx, AvlReceiverService_ListConnections_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *avlReceiverServiceClient) GetConnection(ctx context.Context, in *GetConnectionRequest, opts ...grpc.CallOption) (*DeviceConnection, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeviceConnection)
+	err := c.cc.Invoke(ctx, AvlReceiverService_GetConnection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *avlReceiverServiceClient) DisconnectDevice(ctx context.Context, in *DisconnectDeviceRequest, opts ...grpc.CallOption) (*DeviceConnection, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeviceConnection)
+	err := c.cc.Invoke(ctx, AvlReceiverService_DisconnectDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *avlReceiverServiceClient) InvalidateDeviceCache(ctx context.Context, in *InvalidateDeviceCacheRequest, opts ...grpc.CallOption) (*InvalidateDeviceCacheResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(InvalidateDeviceCacheResponse)
+	err := c.cc.Invoke(ctx, AvlReceiverService_InvalidateDeviceCache_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *avlReceiverServiceClient) GetLogSettings(ctx context.Context, in *GetLogSettingsRequest, opts ...grpc.CallOption) (*LogSettings, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LogSettings)
+	err := c.cc.Invoke(ctx, AvlReceiverService_GetLogSettings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *avlReceiverServiceClient) SetLogLevel(ctx context.Context, in *SetLogLevelRequest, opts ...grpc.CallOption) (*LogSettings, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LogSettings)
+	err := c.cc.Invoke(ctx, AvlReceiverService_SetLogLevel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *avlReceiverServiceClient) TraceDevice(ctx context.Context, in *TraceDeviceRequest, opts ...grpc.CallOption) (*LogSettings, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LogSettings)
+	err := c.cc.Invoke(ctx, AvlReceiverService_TraceDevice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *avlReceiverServiceClient) AddBan(ctx context.Context, in *AddBanRequest, opts ...grpc.CallOption) (*Ban, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ban)
+	err := c.cc.Invoke(ctx, AvlReceiverService_AddBan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *avlReceiverServiceClient) RemoveBan(ctx context.Context, in *RemoveBanRequest, opts ...grpc.CallOption) (*Ban, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Ban)
+	err := c.cc.Invoke(ctx, AvlReceiverService_RemoveBan_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *avlReceiverServiceClient) ListBans(ctx context.Context, in *ListBansRequest, opts ...grpc.CallOption) (*ListBansResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBansResponse)
+	err := c.cc.Invoke(ctx, AvlReceiverService_ListBans_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AvlReceiverServiceServer is the server API for AvlReceiverService service.
 // All implementations must embed UnimplementedAvlReceiverServiceServer
 // for forward compatibility.
 type AvlReceiverServiceServer interface {
 	SendCommand(context.Context, *SendCommandRequestAVL) (*SendCommandResponseAVL, error)
+	// commands for offline devices, delivered after their next login
+	EnqueueCommand(context.Context, *EnqueueCommandRequest) (*QueuedCommand, error)
+	ListQueuedCommands(context.Context, *ListQueuedCommandsRequest) (*ListQueuedCommandsResponse, error)
+	CancelQueuedCommand(context.Context, *CancelQueuedCommandRequest) (*QueuedCommand, error)
+	// live feed of decoded device statuses, the stream ends with RESOURCE_EXHAUSTED
+	// when the subscriber can't keep up and its buffer fills
+	SubscribePositions(*SubscribePositionsRequest, grpc.ServerStreamingServer[types.DeviceStatus]) error
+	// devices connected over tcp
+	ListConnections(context.Context, *ListConnectionsRequest) (*ListConnectionsResponse, error)
+	GetConnection(context.Context, *GetConnectionRequest) (*DeviceConnection, error)
+	// closes the device's session, returns the connection as it was just before
+	DisconnectDevice(context.Context, *DisconnectDeviceRequest) (*DeviceConnection, error)
+	// drops cached device verifications so the next login of the devices asks the data store,
+	// call it after provisioning or revoking a device
+	InvalidateDeviceCache(context.Context, *InvalidateDeviceCacheRequest) (*InvalidateDeviceCacheResponse, error)
+	// logging, e.g. to trace one device's traffic without debug logs for every other device
+	GetLogSettings(context.Context, *GetLogSettingsRequest) (*LogSettings, error)
+	SetLogLevel(context.Context, *SetLogLevelRequest) (*LogSettings, error)
+	TraceDevice(context.Context, *TraceDeviceRequest) (*LogSettings, error)
+	// temporary bans of misbehaving devices by imei or ip, their connections are closed and new ones
+	// turned away until the ban expires
+	AddBan(context.Context, *AddBanRequest) (*Ban, error)
+	RemoveBan(context.Context, *RemoveBanRequest) (*Ban, error)
+	ListBans(context.Context, *ListBansRequest) (*ListBansResponse, error)
 	mustEmbedUnimplementedAvlReceiverServiceServer()
 }
 
@@ -65,6 +277,48 @@ type UnimplementedAvlReceiverServiceServer struct{}
 func (UnimplementedAvlReceiverServiceServer) SendCommand(context.Context, *SendCommandRequestAVL) (*SendCommandResponseAVL, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendCommand not implemented")
 }
+func (UnimplementedAvlReceiverServiceServer) EnqueueCommand(context.Context, *EnqueueCommandRequest) (*QueuedCommand, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnqueueCommand not implemented")
+}
+func (UnimplementedAvlReceiverServiceServer) ListQueuedCommands(context.Context, *ListQueuedCommandsRequest) (*ListQueuedCommandsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListQueuedCommands not implemented")
+}
+func (UnimplementedAvlReceiverServiceServer) CancelQueuedCommand(context.Context, *CancelQueuedCommandRequest) (*QueuedCommand, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelQueuedCommand not implemented")
+}
+func (UnimplementedAvlReceiverServiceServer) SubscribePositions(*SubscribePositionsRequest, grpc.ServerStreamingServer[types.DeviceStatus]) error {
+	return status.Errorf(codes.Unimplemented, "method SubscribePositions not implemented")
+}
+func (UnimplementedAvlReceiverServiceServer) ListConnections(context.Context, *ListConnectionsRequest) (*ListConnectionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListConnections not implemented")
+}
+func (UnimplementedAvlReceiverServiceServer) GetConnection(context.Context, *GetConnectionRequest) (*DeviceConnection, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConnection not implemented")
+}
+func (UnimplementedAvlReceiverServiceServer) DisconnectDevice(context.Context, *DisconnectDeviceRequest) (*DeviceConnection, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisconnectDevice not implemented")
+}
+func (UnimplementedAvlReceiverServiceServer) InvalidateDeviceCache(context.Context, *InvalidateDeviceCacheRequest) (*InvalidateDeviceCacheResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InvalidateDeviceCache not implemented")
+}
+func (UnimplementedAvlReceiverServiceServer) GetLogSettings(context.Context, *GetLogSettingsRequest) (*LogSettings, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetLogSettings not implemented")
+}
+func (UnimplementedAvlReceiverServiceServer) SetLogLevel(context.Context, *SetLogLevelRequest) (*LogSettings, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetLogLevel not implemented")
+}
+func (UnimplementedAvlReceiverServiceServer) TraceDevice(context.Context, *TraceDeviceRequest) (*LogSettings, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TraceDevice not implemented")
+}
+func (UnimplementedAvlReceiverServiceServer) AddBan(context.Context, *AddBanRequest) (*Ban, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AddBan not implemented")
+}
+func (UnimplementedAvlReceiverServiceServer) RemoveBan(context.Context, *RemoveBanRequest) (*Ban, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RemoveBan not implemented")
+}
+func (UnimplementedAvlReceiverServiceServer) ListBans(context.Context, *ListBansRequest) (*ListBansResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListBans not implemented")
+}
 func (UnimplementedAvlReceiverServiceServer) mustEmbedUnimplementedAvlReceiverServiceServer() {}
 func (UnimplementedAvlReceiverServiceServer) testEmbeddedByValue()                            {}
 
@@ -104,6 +358,251 @@ func _AvlReceiverService_SendCommand_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _AvlReceiverService_EnqueueCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnqueueCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlReceiverServiceServer).EnqueueCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlReceiverService_EnqueueCommand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlReceiverServiceServer).EnqueueCommand(ctx, req.(*EnqueueCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvlReceiverService_ListQueuedCommands_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListQueuedCommandsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlReceiverServiceServer).ListQueuedCommands(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlReceiverService_ListQueuedCommands_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlReceiverServiceServer).ListQueuedCommands(ctx, req.(*ListQueuedCommandsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvlReceiverService_CancelQueuedCommand_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelQueuedCommandRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlReceiverServiceServer).CancelQueuedCommand(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlReceiverService_CancelQueuedCommand_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlReceiverServiceServer).CancelQueuedCommand(ctx, req.(*CancelQueuedCommandRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvlReceiverService_SubscribePositions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribePositionsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AvlReceiverServiceServer).SubscribePositions(m, &grpc.GenericServerStream[SubscribePositionsRequest, types.DeviceStatus]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type AvlReceiverService_SubscribePositionsServer = grpc.ServerStreamingServer[types.DeviceStatus]
+
+func _AvlReceiverService_ListConnections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListConnectionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlReceiverServiceServer).ListConnections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlReceiverService_ListConnections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlReceiverServiceServer).ListConnections(ctx, req.(*ListConnectionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvlReceiverService_GetConnection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConnectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlReceiverServiceServer).GetConnection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlReceiverService_GetConnection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlReceiverServiceServer).GetConnection(ctx, req.(*GetConnectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvlReceiverService_DisconnectDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisconnectDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlReceiverServiceServer).DisconnectDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlReceiverService_DisconnectDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlReceiverServiceServer).DisconnectDevice(ctx, req.(*DisconnectDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvlReceiverService_InvalidateDeviceCache_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InvalidateDeviceCacheRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlReceiverServiceServer).InvalidateDeviceCache(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlReceiverService_InvalidateDeviceCache_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlReceiverServiceServer).InvalidateDeviceCache(ctx, req.(*InvalidateDeviceCacheRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvlReceiverService_GetLogSettings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetLogSettingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlReceiverServiceServer).GetLogSettings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlReceiverService_GetLogSettings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlReceiverServiceServer).GetLogSettings(ctx, req.(*GetLogSettingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvlReceiverService_SetLogLevel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetLogLevelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlReceiverServiceServer).SetLogLevel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlReceiverService_SetLogLevel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlReceiverServiceServer).SetLogLevel(ctx, req.(*SetLogLevelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvlReceiverService_TraceDevice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TraceDeviceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlReceiverServiceServer).TraceDevice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlReceiverService_TraceDevice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlReceiverServiceServer).TraceDevice(ctx, req.(*TraceDeviceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvlReceiverService_AddBan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddBanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlReceiverServiceServer).AddBan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlReceiverService_AddBan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlReceiverServiceServer).AddBan(ctx, req.(*AddBanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvlReceiverService_RemoveBan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveBanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlReceiverServiceServer).RemoveBan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlReceiverService_RemoveBan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlReceiverServiceServer).RemoveBan(ctx, req.(*RemoveBanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AvlReceiverService_ListBans_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBansRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AvlReceiverServiceServer).ListBans(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AvlReceiverService_ListBans_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AvlReceiverServiceServer).ListBans(ctx, req.(*ListBansRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // AvlReceiverService_ServiceDesc is the grpc.ServiceDesc for AvlReceiverService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -115,7 +614,65 @@ var AvlReceiverService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SendCommand",
 			Handler:    _AvlReceiverService_SendCommand_Handler,
 		},
+		{
+			MethodName: "EnqueueCommand",
+			Handler:    _AvlReceiverService_EnqueueCommand_Handler,
+		},
+		{
+			MethodName: "ListQueuedCommands",
+			Handler:    _AvlReceiverService_ListQueuedCommands_Handler,
+		},
+		{
+			MethodName: "CancelQueuedCommand",
+			Handler:    _AvlReceiverService_CancelQueuedCommand_Handler,
+		},
+		{
+			MethodName: "ListConnections",
+			Handler:    _AvlReceiverService_ListConnections_Handler,
+		},
+		{
+			MethodName: "GetConnection",
+			Handler:    _AvlReceiverService_GetConnection_Handler,
+		},
+		{
+			MethodName: "DisconnectDevice",
+			Handler:    _AvlReceiverService_DisconnectDevice_Handler,
+		},
+		{
+			MethodName: "InvalidateDeviceCache",
+			Handler:    _AvlReceiverService_InvalidateDeviceCache_Handler,
+		},
+		{
+			MethodName: "GetLogSettings",
+			Handler:    _AvlReceiverService_GetLogSettings_Handler,
+		},
+		{
+			MethodName: "SetLogLevel",
+			Handler:    _AvlReceiverService_SetLogLevel_Handler,
+		},
+		{
+			MethodName: "TraceDevice",
+			Handler:    _AvlReceiverService_TraceDevice_Handler,
+		},
+		{
+			MethodName: "AddBan",
+			Handler:    _AvlReceiverService_AddBan_Handler,
+		},
+		{
+			MethodName: "RemoveBan",
+			Handler:    _AvlReceiverService_RemoveBan_Handler,
+		},
+		{
+			MethodName: "ListBans",
+			Handler:    _AvlReceiverService_ListBans_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribePositions",
+			Handler:       _AvlReceiverService_SubscribePositions_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "avl-service.proto",
 }
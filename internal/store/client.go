@@ -2,27 +2,51 @@ package store
 
 import (
 	"context"
+	"fmt"
+	"time"
 
+	errs "github.com/404minds/avl-receiver/internal/errors"
 	"github.com/404minds/avl-receiver/internal/types"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
 type CustomAvlDataStoreClient struct {
-	cc          grpc.ClientConnInterface
-	serviceName string
+	cc            grpc.ClientConnInterface
+	serviceName   string
+	callTimeout   time.Duration
+	verifyTimeout time.Duration
 }
 
+// withDeadline bounds calls made with a context that has no deadline of its own
+func withDeadline(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// VerifyDevice fails with errs.ErrStoreUnavailable when the store can't be reached in time, devices
+// are waiting on the login ack so it doesn't wait out the regular call timeout
 func (c CustomAvlDataStoreClient) VerifyDevice(ctx context.Context, in *VerifyDeviceRequest, opts ...grpc.CallOption) (*VerifyDeviceReply, error) {
+	ctx, cancel := withDeadline(ctx, c.verifyTimeout)
+	defer cancel()
 	out := new(VerifyDeviceReply)
 	err := c.cc.Invoke(ctx, c.serviceName+"/VerifyDevice", in, out, opts...)
 	if err != nil {
+		if code := status.Code(err); code == codes.Unavailable || code == codes.DeadlineExceeded {
+			return nil, fmt.Errorf("%w: %w", errs.ErrStoreUnavailable, err)
+		}
 		return nil, err
 	}
 	return out, nil
 }
 
 func (c CustomAvlDataStoreClient) SaveDeviceStatus(ctx context.Context, in *types.DeviceStatus, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	ctx, cancel := withDeadline(ctx, c.callTimeout)
+	defer cancel()
 	out := new(emptypb.Empty)
 	err := c.cc.Invoke(ctx, c.serviceName+"/InsertAVL", in, out, opts...)
 	if err != nil {
@@ -32,6 +56,8 @@ func (c CustomAvlDataStoreClient) SaveDeviceStatus(ctx context.Context, in *type
 }
 
 func (c CustomAvlDataStoreClient) SaveDeviceResponse(ctx context.Context, in *types.DeviceResponse, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	ctx, cancel := withDeadline(ctx, c.callTimeout)
+	defer cancel()
 	out := new(emptypb.Empty)
 	err := c.cc.Invoke(ctx, c.serviceName+"/InsertDeviceResponse", in, out, opts...)
 	if err != nil {
@@ -41,6 +67,8 @@ func (c CustomAvlDataStoreClient) SaveDeviceResponse(ctx context.Context, in *ty
 }
 
 func (c CustomAvlDataStoreClient) FetchDeviceModel(ctx context.Context, in *types.FetchDeviceModelRequest, opts ...grpc.CallOption) (*FetchDeviceModelResponse, error) {
+	ctx, cancel := withDeadline(ctx, c.callTimeout)
+	defer cancel()
 	out := new(FetchDeviceModelResponse)
 	err := c.cc.Invoke(ctx, c.serviceName+"/FetchDeviceModel", in, out, opts...)
 	if err != nil {
@@ -49,9 +77,55 @@ func (c CustomAvlDataStoreClient) FetchDeviceModel(ctx context.Context, in *type
 	return out, nil
 }
 
+func (c CustomAvlDataStoreClient) SaveCommandDelivery(ctx context.Context, in *CommandDelivery, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	ctx, cancel := withDeadline(ctx, c.callTimeout)
+	defer cancel()
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, c.serviceName+"/InsertCommandDelivery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c CustomAvlDataStoreClient) SaveDeviceLimited(ctx context.Context, in *DeviceLimited, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	ctx, cancel := withDeadline(ctx, c.callTimeout)
+	defer cancel()
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, c.serviceName+"/InsertDeviceLimited", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c CustomAvlDataStoreClient) SaveDeviceSession(ctx context.Context, in *DeviceSessionEvent, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	ctx, cancel := withDeadline(ctx, c.callTimeout)
+	defer cancel()
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, c.serviceName+"/InsertDeviceSession", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// IsConfigured is false for the zero value, used when running without a remote data store
+func (c CustomAvlDataStoreClient) IsConfigured() bool {
+	return c.cc != nil
+}
+
 func NewCustomAvlDataStoreClient(cc grpc.ClientConnInterface, serviceName string) *CustomAvlDataStoreClient {
 	return &CustomAvlDataStoreClient{
-		cc:          cc,
-		serviceName: serviceName,
+		cc:            cc,
+		serviceName:   serviceName,
+		callTimeout:   DefaultRemoteCallTimeout,
+		verifyTimeout: DefaultRemoteVerifyTimeout,
 	}
 }
+
+// SetTimeouts changes the deadlines of calls made without one, 0 leaves them unbounded
+func (c *CustomAvlDataStoreClient) SetTimeouts(call time.Duration, verify time.Duration) {
+	c.callTimeout = call
+	c.verifyTimeout = verify
+}
@@ -2,24 +2,28 @@ package store
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"os"
+	"sync/atomic"
 
 	configuredLogger "github.com/404minds/avl-receiver/internal/logger"
 	"github.com/404minds/avl-receiver/internal/types"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 )
 
 var logger = configuredLogger.Logger
 
+// JsonLinesStore writes one protojson line per status or response to the device's rotating file
 type JsonLinesStore struct {
-	File              *os.File
+	File              *RotatingFile
 	ProcessChan       chan *types.DeviceStatus
 	ResponseChan      chan *types.DeviceResponse
 	CloseChan         chan bool
 	CloseResponseChan chan bool
 	DeviceID          string
+
+	// Process and Response share the file, the second one to return closes it
+	stopped atomic.Int32
 }
 
 func (s *JsonLinesStore) GetResponseChan() chan *types.DeviceResponse {
@@ -37,33 +41,54 @@ func (s *JsonLinesStore) GetCloseChan() chan bool {
 func (s *JsonLinesStore) GetCloseResponseChan() chan bool { return s.CloseResponseChan }
 
 func (s *JsonLinesStore) Process(ctx context.Context) {
+	defer s.release()
+
 	for {
 		select {
 		case data := <-s.ProcessChan:
-			b, err := json.Marshal(data)
-			if err != nil {
-				logger.Error("failed to write record to file", zap.String("deviceId", s.DeviceID), zap.Error(err))
-			}
-			fmt.Fprintln(s.File, string(b))
-			s.File.Sync()
+			_ = timeStoreCall("local", opStatus, func() error { return s.writeLine(data) })
 		case <-s.CloseChan:
 			return
+		case <-ctx.Done():
+			return
 		}
 	}
 }
 
 func (s *JsonLinesStore) Response(ctx context.Context) {
+	defer s.release()
+
 	for {
 		select {
 		case data := <-s.ResponseChan:
-			b, err := json.Marshal(data)
-			if err != nil {
-				logger.Error("failed to write record to file", zap.String("deviceId", s.DeviceID), zap.Error(err))
-			}
-			fmt.Fprintln(s.File, string(b))
-			s.File.Sync()
-		case <-s.CloseChan:
+			_ = timeStoreCall("local", opResponse, func() error { return s.writeLine(data) })
+		case <-s.CloseResponseChan:
+			return
+		case <-ctx.Done():
 			return
 		}
 	}
 }
+
+// writeLine logs its errors, they're returned for the store metrics
+func (s *JsonLinesStore) writeLine(m proto.Message) error {
+	b, err := protojson.Marshal(m)
+	if err != nil {
+		logger.Error("failed to marshal record", zap.String("deviceId", s.DeviceID), zap.Error(err))
+		return err
+	}
+	if err := s.File.WriteLine(b); err != nil {
+		logger.Error("failed to write record to file", zap.String("deviceId", s.DeviceID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (s *JsonLinesStore) release() {
+	if s.stopped.Add(1) < 2 {
+		return
+	}
+	if err := s.File.Close(); err != nil {
+		logger.Error("failed to close file", zap.String("deviceId", s.DeviceID), zap.Error(err))
+	}
+}
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestJsonLinesStoreWritesProtojson(t *testing.T) {
+	dir := t.TempDir()
+	file, err := OpenRotatingFile("350424063817363", RotationConfig{Dir: dir})
+	require.NoError(t, err)
+
+	s := &JsonLinesStore{
+		File:              file,
+		ProcessChan:       make(chan *types.DeviceStatus, 10),
+		ResponseChan:      make(chan *types.DeviceResponse, 10),
+		CloseChan:         make(chan bool, 1),
+		CloseResponseChan: make(chan bool, 1),
+		DeviceID:          "350424063817363",
+	}
+	processDone, responseDone := make(chan struct{}), make(chan struct{})
+	go func() { s.Process(context.Background()); close(processDone) }()
+	go func() { s.Response(context.Background()); close(responseDone) }()
+
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	s.ProcessChan <- &types.DeviceStatus{
+		Imei:      "350424063817363",
+		Timestamp: timestamppb.New(ts),
+		RawData:   &types.DeviceStatus_TeltonikaPacket{TeltonikaPacket: &types.TeltonikaPacket{}},
+	}
+	// invalid utf-8 can't be marshalled and must not leave a broken line behind
+	s.ProcessChan <- &types.DeviceStatus{Imei: "\xff"}
+	s.ResponseChan <- &types.DeviceResponse{Imei: "350424063817363", Response: "OK"}
+
+	require.Eventually(t, func() bool { return len(s.ProcessChan) == 0 && len(s.ResponseChan) == 0 }, time.Second, 5*time.Millisecond)
+	s.CloseChan <- true
+	s.CloseResponseChan <- true
+	<-processDone
+	<-responseDone
+
+	b, err := os.ReadFile(filepath.Join(dir, "350424063817363.json"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 2)
+
+	// the status and response loops race, so the two lines can come in either order
+	statusLine, responseLine := lines[0], lines[1]
+	if strings.Contains(statusLine, `"response"`) {
+		statusLine, responseLine = responseLine, statusLine
+	}
+
+	var status types.DeviceStatus
+	require.NoError(t, protojson.Unmarshal([]byte(statusLine), &status))
+	assert.Equal(t, ts, status.Timestamp.AsTime())
+	assert.NotNil(t, status.GetTeltonikaPacket(), "oneof survives the round trip")
+
+	var response types.DeviceResponse
+	require.NoError(t, protojson.Unmarshal([]byte(responseLine), &response))
+	assert.Equal(t, "OK", response.Response)
+
+	assert.ErrorIs(t, file.WriteLine([]byte("late")), ErrFileClosed, "closed once both loops returned")
+}
@@ -0,0 +1,168 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/kafka"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	KafkaPayloadProtobuf = "protobuf"
+	KafkaPayloadJson     = "json"
+
+	DefaultKafkaStatusTopic = "avl.device-status"
+
+	kafkaCloseTimeout = 10 * time.Second
+)
+
+type KafkaPublisherConfig struct {
+	Producer      kafka.ProducerConfig
+	PayloadFormat string // KafkaPayloadProtobuf or KafkaPayloadJson
+	StatusTopic   string
+	ResponseTopic string // device responses are only published when set
+}
+
+// KafkaPublisher is the producer shared by all the per-connection KafkaStores.
+// Records are keyed by imei so every device's records stay ordered within one partition.
+type KafkaPublisher struct {
+	client *kgo.Client
+	Config KafkaPublisherConfig
+}
+
+func NewKafkaPublisher(config KafkaPublisherConfig) (*KafkaPublisher, error) {
+	if config.PayloadFormat == "" {
+		config.PayloadFormat = KafkaPayloadProtobuf
+	}
+	if config.PayloadFormat != KafkaPayloadProtobuf && config.PayloadFormat != KafkaPayloadJson {
+		return nil, fmt.Errorf("invalid kafka payload format %s", config.PayloadFormat)
+	}
+	if config.StatusTopic == "" {
+		config.StatusTopic = DefaultKafkaStatusTopic
+	}
+
+	client, err := kafka.NewClient(config.Producer)
+	if err != nil {
+		return nil, err
+	}
+	return &KafkaPublisher{client: client, Config: config}, nil
+}
+
+// Close flushes the records still being batched
+func (p *KafkaPublisher) Close() {
+	ctx, cancel := context.WithTimeout(context.Background(), kafkaCloseTimeout)
+	defer cancel()
+	if err := p.client.Flush(ctx); err != nil {
+		logger.Error("failed to flush kafka records", zap.Error(err))
+	}
+	p.client.Close()
+}
+
+func (p *KafkaPublisher) Ping(ctx context.Context) error {
+	return p.client.Ping(ctx)
+}
+
+// produce queues the record, blocking while the client's buffer is full. The store call is only
+// recorded once the record is delivered, or has failed after franz-go's retries.
+func (p *KafkaPublisher) produce(op string, topic string, imei string, value []byte, start time.Time) {
+	p.client.Produce(context.Background(), &kgo.Record{Topic: topic, Key: []byte(imei), Value: value}, func(record *kgo.Record, err error) {
+		observeStoreCall("kafka", op, start, err)
+		if err != nil {
+			logger.Error("failed to produce kafka record", zap.String("topic", record.Topic), zap.String("imei", imei), zap.Error(err))
+		}
+	})
+}
+
+func (p *KafkaPublisher) marshal(m proto.Message) ([]byte, error) {
+	if p.Config.PayloadFormat == KafkaPayloadJson {
+		return protojson.Marshal(m)
+	}
+	return proto.Marshal(m)
+}
+
+func (p *KafkaPublisher) PublishDeviceStatus(status *types.DeviceStatus) error {
+	start := time.Now()
+	value, err := p.marshal(status)
+	if err != nil {
+		observeStoreCall("kafka", opStatus, start, err)
+		return err
+	}
+	p.produce(opStatus, p.Config.StatusTopic, status.Imei, value, start)
+	return nil
+}
+
+func (p *KafkaPublisher) PublishDeviceResponse(response *types.DeviceResponse) error {
+	if p.Config.ResponseTopic == "" {
+		return nil
+	}
+	start := time.Now()
+	value, err := p.marshal(response)
+	if err != nil {
+		observeStoreCall("kafka", opResponse, start, err)
+		return err
+	}
+	p.produce(opResponse, p.Config.ResponseTopic, response.Imei, value, start)
+	return nil
+}
+
+type KafkaStore struct {
+	ProcessChan       chan *types.DeviceStatus
+	ResponseChan      chan *types.DeviceResponse
+	CloseChan         chan bool
+	CloseResponseChan chan bool
+	Publisher         *KafkaPublisher
+}
+
+func (s *KafkaStore) GetProcessChan() chan *types.DeviceStatus {
+	return s.ProcessChan
+}
+
+func (s *KafkaStore) GetResponseChan() chan *types.DeviceResponse {
+	return s.ResponseChan
+}
+
+func (s *KafkaStore) GetCloseChan() chan bool {
+	return s.CloseChan
+}
+
+func (s *KafkaStore) GetCloseResponseChan() chan bool {
+	return s.CloseResponseChan
+}
+
+func (s *KafkaStore) Process(ctx context.Context) {
+	for {
+		select {
+		case deviceStatus := <-s.ProcessChan:
+			if err := s.Publisher.PublishDeviceStatus(deviceStatus); err != nil {
+				logger.Error("failed to publish device status to kafka", zap.String("imei", deviceStatus.Imei), zap.Error(err))
+			}
+		case <-s.CloseChan:
+			logger.Sugar().Info("async kafka store shutting down for device")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *KafkaStore) Response(ctx context.Context) {
+	for {
+		select {
+		case deviceResponse := <-s.ResponseChan:
+			if err := s.Publisher.PublishDeviceResponse(deviceResponse); err != nil {
+				logger.Error("failed to publish device response to kafka", zap.String("imei", deviceResponse.Imei), zap.Error(err))
+			}
+		case <-s.CloseResponseChan:
+			logger.Sugar().Info("async kafka store shutting down for device")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
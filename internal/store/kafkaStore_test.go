@@ -0,0 +1,217 @@
+package store
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/kafka"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twmb/franz-go/pkg/kfake"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/kmsg"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// startKafka runs an in-process fake cluster for the test
+func startKafka(t *testing.T, opts ...kfake.Opt) *kfake.Cluster {
+	cluster, err := kfake.NewCluster(append([]kfake.Opt{kfake.NumBrokers(1)}, opts...)...)
+	require.NoError(t, err)
+	t.Cleanup(cluster.Close)
+	return cluster
+}
+
+// recordBatches keeps the record batches the cluster is sent, to check how the producer built them
+func recordBatches(cluster *kfake.Cluster) func() []kmsg.RecordBatch {
+	var mu sync.Mutex
+	var batches []kmsg.RecordBatch
+	cluster.ControlKey(int16(kmsg.Produce), func(req kmsg.Request) (kmsg.Response, error, bool) {
+		cluster.KeepControl()
+		for _, topic := range req.(*kmsg.ProduceRequest).Topics {
+			for _, partition := range topic.Partitions {
+				var batch kmsg.RecordBatch
+				if batch.ReadFrom(partition.Records) == nil {
+					mu.Lock()
+					batches = append(batches, batch)
+					mu.Unlock()
+				}
+			}
+		}
+		return nil, nil, false // the cluster handles it as usual
+	})
+	return func() []kmsg.RecordBatch {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]kmsg.RecordBatch(nil), batches...)
+	}
+}
+
+// consume reads n records of the topic from the start
+func consume(t *testing.T, cluster *kfake.Cluster, topic string, n int) []*kgo.Record {
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(cluster.ListenAddrs()...),
+		kgo.ConsumeTopics(topic),
+		kgo.ConsumeResetOffset(kgo.NewOffset().AtStart()),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	var records []*kgo.Record
+	for len(records) < n {
+		fetches := client.PollFetches(ctx)
+		require.NoError(t, ctx.Err(), "got %d of %d records", len(records), n)
+		fetches.EachError(func(topic string, partition int32, err error) {
+			t.Fatalf("consuming %s/%d: %v", topic, partition, err)
+		})
+		records = append(records, fetches.Records()...)
+	}
+	return records
+}
+
+func makeTestKafkaStore(publisher *KafkaPublisher) *KafkaStore {
+	return &KafkaStore{
+		ProcessChan:       make(chan *types.DeviceStatus),
+		ResponseChan:      make(chan *types.DeviceResponse),
+		CloseChan:         make(chan bool, 1),
+		CloseResponseChan: make(chan bool, 1),
+		Publisher:         publisher,
+	}
+}
+
+func TestKafkaStoreKeepsEveryImeiInOrderOnOnePartition(t *testing.T) {
+	cluster := startKafka(t, kfake.SeedTopics(4, DefaultKafkaStatusTopic, "avl.device-response"))
+	batches := recordBatches(cluster)
+	publisher, err := NewKafkaPublisher(KafkaPublisherConfig{
+		Producer: kafka.ProducerConfig{
+			Brokers:      cluster.ListenAddrs(),
+			Compression:  "gzip",
+			Idempotent:   true,
+			BatchTimeout: 50 * time.Millisecond,
+		},
+		ResponseTopic: "avl.device-response",
+	})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	s := makeTestKafkaStore(publisher)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Process(ctx)
+	go s.Response(ctx)
+
+	imeis := []string{"350424063817363", "356307043721579", "752533678900242", "868683024566327"}
+	const perImei = 25
+	for i := 0; i < perImei; i++ {
+		for _, imei := range imeis {
+			s.GetProcessChan() <- &types.DeviceStatus{Imei: imei, MessageType: strconv.Itoa(i)}
+		}
+	}
+	s.GetResponseChan() <- &types.DeviceResponse{Imei: imeis[0], Response: "OK"}
+
+	partitions := map[string]int32{}
+	next := map[string]int{}
+	for _, record := range consume(t, cluster, DefaultKafkaStatusTopic, len(imeis)*perImei) {
+		imei := string(record.Key)
+		if partition, seen := partitions[imei]; seen {
+			assert.Equal(t, partition, record.Partition, "%s moved partitions", imei)
+		}
+		partitions[imei] = record.Partition
+
+		status := &types.DeviceStatus{}
+		require.NoError(t, proto.Unmarshal(record.Value, status))
+		assert.Equal(t, imei, status.Imei)
+		assert.Equal(t, strconv.Itoa(next[imei]), status.MessageType, "%s out of order", imei)
+		next[imei]++
+	}
+	assert.Len(t, partitions, len(imeis))
+
+	responses := consume(t, cluster, "avl.device-response", 1)
+	assert.Equal(t, imeis[0], string(responses[0].Key))
+
+	produced := batches()
+	require.NotEmpty(t, produced)
+	batched := false
+	for _, batch := range produced {
+		assert.GreaterOrEqual(t, batch.ProducerID, int64(0), "the producer is idempotent")
+		// franz-go sends a batch as is when gzip doesn't make it any smaller, a lone record say
+		if batch.NumRecords > 1 {
+			batched = true
+			assert.Equal(t, int16(1), batch.Attributes&0x07, "batches are gzipped")
+		}
+	}
+	assert.True(t, batched, "records linger to share a batch")
+}
+
+func TestKafkaPublisherJsonWithoutResponseTopic(t *testing.T) {
+	cluster := startKafka(t, kfake.SeedTopics(1, "positions"))
+	publisher, err := NewKafkaPublisher(KafkaPublisherConfig{
+		Producer:      kafka.ProducerConfig{Brokers: cluster.ListenAddrs()},
+		PayloadFormat: KafkaPayloadJson,
+		StatusTopic:   "positions",
+	})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	require.NoError(t, publisher.PublishDeviceResponse(&types.DeviceResponse{Imei: "350424063817363"}), "responses aren't published without a topic")
+	require.NoError(t, publisher.PublishDeviceStatus(&types.DeviceStatus{Imei: "350424063817363"}))
+
+	records := consume(t, cluster, "positions", 1)
+	decoded := &types.DeviceStatus{}
+	require.NoError(t, protojson.Unmarshal(records[0].Value, decoded))
+	assert.Equal(t, "350424063817363", decoded.Imei)
+}
+
+func TestKafkaDeliveryFailuresCounted(t *testing.T) {
+	cluster := startKafka(t, kfake.SeedTopics(1, "positions"))
+	// the cluster turns every produce away
+	cluster.ControlKey(int16(kmsg.Produce), func(req kmsg.Request) (kmsg.Response, error, bool) {
+		cluster.KeepControl()
+		produce := req.(*kmsg.ProduceRequest)
+		resp := produce.ResponseKind().(*kmsg.ProduceResponse)
+		for _, topic := range produce.Topics {
+			respTopic := kmsg.NewProduceResponseTopic()
+			respTopic.Topic = topic.Topic
+			for _, partition := range topic.Partitions {
+				respPartition := kmsg.NewProduceResponseTopicPartition()
+				respPartition.Partition = partition.Partition
+				respPartition.ErrorCode = 87 // INVALID_RECORD, not retried
+				respTopic.Partitions = append(respTopic.Partitions, respPartition)
+			}
+			resp.Topics = append(resp.Topics, respTopic)
+		}
+		return resp, nil, true
+	})
+
+	publisher, err := NewKafkaPublisher(KafkaPublisherConfig{
+		Producer:    kafka.ProducerConfig{Brokers: cluster.ListenAddrs()},
+		StatusTopic: "positions",
+	})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	errors := storeErrorsTotal.WithLabelValues("kafka", opStatus)
+	before := testutil.ToFloat64(errors)
+	require.NoError(t, publisher.PublishDeviceStatus(&types.DeviceStatus{Imei: "350424063817363"}), "the record is only queued")
+	assert.Eventually(t, func() bool { return testutil.ToFloat64(errors) == before+1 }, 10*time.Second, 10*time.Millisecond,
+		"the failed delivery counts as a store error")
+}
+
+func TestKafkaPublisherRejectsInvalidConfig(t *testing.T) {
+	_, err := NewKafkaPublisher(KafkaPublisherConfig{Producer: kafka.ProducerConfig{Brokers: []string{"localhost:9092"}}, PayloadFormat: "xml"})
+	assert.Error(t, err)
+
+	_, err = NewKafkaPublisher(KafkaPublisherConfig{Producer: kafka.ProducerConfig{Brokers: []string{"localhost:9092"}, Compression: "brotli"}})
+	assert.Error(t, err)
+	_, err = NewKafkaPublisher(KafkaPublisherConfig{Producer: kafka.ProducerConfig{Brokers: []string{"localhost:9092"}, SaslMechanism: "kerberos"}})
+	assert.Error(t, err)
+	_, err = NewKafkaPublisher(KafkaPublisherConfig{Producer: kafka.ProducerConfig{}})
+	assert.Error(t, err, "brokers are required")
+}
@@ -0,0 +1,50 @@
+package store
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// store write kinds, the op label of the store metrics
+const (
+	opStatus   = "status"
+	opResponse = "response"
+)
+
+var (
+	storeCallSeconds = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "avl_store_call_seconds",
+		Help:    "Time taken to hand a device status or response to a store, up to its delivery for kafka, by store and op.",
+		Buckets: []float64{.0005, .001, .005, .01, .05, .1, .25, .5, 1, 2.5, 5, 10},
+	}, []string{"store", "op"})
+	storeErrorsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "avl_store_errors_total",
+		Help: "Device statuses and responses a store failed to take, by store and op.",
+	}, []string{"store", "op"})
+	storeRowsDroppedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "avl_store_rows_dropped_total",
+		Help: "Device statuses and responses a store took but gave up writing, by store and op.",
+	}, []string{"store", "op"})
+)
+
+func init() {
+	prometheus.MustRegister(storeCallSeconds, storeErrorsTotal, storeRowsDroppedTotal)
+}
+
+// timeStoreCall runs one store write and records how long it took and whether it failed
+func timeStoreCall(storeName string, op string, call func() error) error {
+	start := time.Now()
+	err := call()
+	observeStoreCall(storeName, op, start, err)
+	return err
+}
+
+// observeStoreCall records a store write that started at start, for the stores that only learn how
+// it went later on
+func observeStoreCall(storeName string, op string, start time.Time, err error) {
+	storeCallSeconds.WithLabelValues(storeName, op).Observe(time.Since(start).Seconds())
+	if err != nil {
+		storeErrorsTotal.WithLabelValues(storeName, op).Inc()
+	}
+}
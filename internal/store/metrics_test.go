@@ -0,0 +1,19 @@
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeStoreCallCountsErrors(t *testing.T) {
+	before := testutil.ToFloat64(storeErrorsTotal.WithLabelValues("mqtt", opStatus))
+
+	assert.NoError(t, timeStoreCall("mqtt", opStatus, func() error { return nil }))
+	failed := errors.New("not connected")
+	assert.ErrorIs(t, timeStoreCall("mqtt", opStatus, func() error { return failed }), failed)
+
+	assert.Equal(t, before+1, testutil.ToFloat64(storeErrorsTotal.WithLabelValues("mqtt", opStatus)))
+}
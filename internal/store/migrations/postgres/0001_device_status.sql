@@ -0,0 +1,53 @@
+-- no surrogate keys or unique constraints so the tables can be turned into timescale hypertables
+CREATE TABLE IF NOT EXISTS device_status (
+    imei                   text             NOT NULL,
+    device_type            text             NOT NULL,
+    ts                     timestamptz      NOT NULL,
+    received_at            timestamptz      NOT NULL DEFAULT now(),
+    message_type           text,
+
+    latitude               double precision,
+    longitude              double precision,
+    altitude               real,
+    speed                  real,
+    course                 real,
+    satellites             integer,
+
+    ignition               boolean,
+    vehicle_status         jsonb,
+
+    battery_level          integer,
+    temperature            real,
+    odometer               integer,
+    fuel_ltr               integer,
+    fuel_pct               integer,
+    fuel_gps               integer,
+    identification_id      text,
+    vin                    text,
+    rpm                    integer,
+    coolant_temperature    real,
+    engine_load            real,
+    intake_air_temp        real,
+    run_time               bigint,
+    distance_mil_on        bigint,
+    fuel_level_input       real,
+    maf_air_flow           real,
+    fuel_rail_pressure     real,
+    warmups_since_clear    bigint,
+    distance_since_clear   bigint,
+    barometric_pressure    integer,
+    control_module_voltage real,
+    absolute_load_value    real,
+    engine_oil_temp        real,
+    gsm_network            integer
+);
+
+CREATE INDEX IF NOT EXISTS device_status_imei_ts_idx ON device_status (imei, ts DESC);
+
+CREATE TABLE IF NOT EXISTS device_responses (
+    imei        text        NOT NULL,
+    received_at timestamptz NOT NULL DEFAULT now(),
+    response    text        NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS device_responses_imei_received_at_idx ON device_responses (imei, received_at DESC);
@@ -0,0 +1,139 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sync/atomic"
+
+	"github.com/eclipse/paho.golang/autopaho"
+	"github.com/eclipse/paho.golang/paho"
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"go.uber.org/zap"
+)
+
+// mqttClient is what the publisher needs from a broker connection, paho.mqtt.golang only speaks 3.1
+// and 3.1.1 while paho.golang only speaks 5, so the version picks the library
+type mqttClient interface {
+	Publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error
+	Connected() bool
+	Disconnect()
+}
+
+// mqttV3Client is a 3.1 or 3.1.1 connection
+type mqttV3Client struct {
+	client mqtt.Client
+}
+
+func newMqttV3Client(config MqttPublisherConfig) (*mqttV3Client, error) {
+	opts := mqtt.NewClientOptions().
+		AddBroker(config.BrokerURL).
+		SetClientID(config.ClientID).
+		SetUsername(config.Username).
+		SetPassword(config.Password).
+		SetProtocolVersion(config.Version).
+		SetCleanSession(true).
+		SetAutoReconnect(true).
+		SetConnectRetry(true).
+		SetOrderMatters(false).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			logger.Error("lost connection to mqtt broker", zap.String("broker", config.BrokerURL), zap.Error(err))
+		}).
+		SetOnConnectHandler(func(_ mqtt.Client) {
+			logger.Sugar().Infof("Connected to mqtt broker %s", config.BrokerURL)
+		})
+
+	client := mqtt.NewClient(opts)
+	// with ConnectRetry the token only completes once connected, so don't block startup on it
+	token := client.Connect()
+	if token.WaitTimeout(mqttPublishTimeout) && token.Error() != nil {
+		return nil, token.Error()
+	}
+	return &mqttV3Client{client: client}, nil
+}
+
+func (c *mqttV3Client) Publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error {
+	token := c.client.Publish(topic, qos, retained, payload)
+	select {
+	case <-token.Done():
+		return token.Error()
+	case <-ctx.Done():
+		return fmt.Errorf("timed out publishing to %s", topic)
+	}
+}
+
+func (c *mqttV3Client) Connected() bool {
+	return c.client.IsConnectionOpen()
+}
+
+func (c *mqttV3Client) Disconnect() {
+	c.client.Disconnect(250)
+}
+
+// mqttV5Client is a 5 connection, autopaho reconnects it in the background like paho.mqtt.golang's
+// auto reconnect does for 3.1.1
+type mqttV5Client struct {
+	manager   *autopaho.ConnectionManager
+	connected atomic.Bool
+}
+
+func newMqttV5Client(config MqttPublisherConfig) (*mqttV5Client, error) {
+	brokerURL, err := url.Parse(config.BrokerURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mqtt broker url %s: %w", config.BrokerURL, err)
+	}
+
+	c := &mqttV5Client{}
+	lost := func(err error) {
+		if c.connected.Swap(false) {
+			logger.Error("lost connection to mqtt broker", zap.String("broker", config.BrokerURL), zap.Error(err))
+		}
+	}
+	clientConfig := autopaho.ClientConfig{
+		ServerUrls:                    []*url.URL{brokerURL},
+		KeepAlive:                     30,
+		CleanStartOnInitialConnection: true,
+		ConnectUsername:               config.Username,
+		ConnectPassword:               []byte(config.Password),
+		OnConnectionUp: func(_ *autopaho.ConnectionManager, _ *paho.Connack) {
+			c.connected.Store(true)
+			logger.Sugar().Infof("Connected to mqtt broker %s", config.BrokerURL)
+		},
+		OnConnectError: func(err error) {
+			logger.Error("failed to connect to mqtt broker", zap.String("broker", config.BrokerURL), zap.Error(err))
+		},
+		ClientConfig: paho.ClientConfig{
+			ClientID:      config.ClientID,
+			OnClientError: lost,
+			OnServerDisconnect: func(d *paho.Disconnect) {
+				lost(fmt.Errorf("disconnected by the broker, reason code %d", d.ReasonCode))
+			},
+		},
+	}
+
+	// the manager lives until Disconnect, not for the startup timeout below
+	c.manager, err = autopaho.NewConnection(context.Background(), clientConfig)
+	if err != nil {
+		return nil, err
+	}
+	// retried in the background like the 3.1.1 client, so don't block startup on it
+	ctx, cancel := context.WithTimeout(context.Background(), mqttPublishTimeout)
+	defer cancel()
+	_ = c.manager.AwaitConnection(ctx)
+	return c, nil
+}
+
+func (c *mqttV5Client) Publish(ctx context.Context, topic string, qos byte, retained bool, payload []byte) error {
+	_, err := c.manager.Publish(ctx, &paho.Publish{Topic: topic, QoS: qos, Retain: retained, Payload: payload})
+	return err
+}
+
+func (c *mqttV5Client) Connected() bool {
+	return c.connected.Load()
+}
+
+func (c *mqttV5Client) Disconnect() {
+	ctx, cancel := context.WithTimeout(context.Background(), mqttPublishTimeout)
+	defer cancel()
+	_ = c.manager.Disconnect(ctx)
+}
@@ -0,0 +1,195 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/types"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	MqttPayloadProtobuf = "protobuf"
+	MqttPayloadJson     = "json"
+
+	DefaultMqttStatusTopic   = "avl/{device_type}/{imei}/status"
+	DefaultMqttResponseTopic = "avl/{device_type}/{imei}/response"
+
+	mqttPublishTimeout = 10 * time.Second
+)
+
+type MqttPublisherConfig struct {
+	BrokerURL     string // tcp://host:1883, ssl://host:8883 or ws://host/mqtt
+	ClientID      string
+	Username      string
+	Password      string
+	Version       uint // paho's protocol version, 3 for 3.1, 4 for 3.1.1 or 5, 3.1.1 if 0
+	QoS           byte
+	RetainStatus  bool   // keep the last position of every device on the broker
+	PayloadFormat string // MqttPayloadProtobuf or MqttPayloadJson
+	StatusTopic   string // supports {imei} and {device_type} placeholders
+	ResponseTopic string
+}
+
+// MqttPublisher is the broker connection shared by all the per-connection MqttStores
+type MqttPublisher struct {
+	client mqttClient
+	Config MqttPublisherConfig
+}
+
+// MqttProtocolVersion is paho's protocol version of an mqtt version
+func MqttProtocolVersion(version string) (uint, error) {
+	switch version {
+	case "3.1":
+		return 3, nil
+	case "3.1.1", "":
+		return 4, nil
+	case "5", "5.0":
+		return 5, nil
+	default:
+		return 0, fmt.Errorf("invalid mqtt version %s", version)
+	}
+}
+
+func NewMqttPublisher(config MqttPublisherConfig) (*MqttPublisher, error) {
+	if config.Version == 0 {
+		config.Version = 4
+	}
+	if config.Version < 3 || config.Version > 5 {
+		return nil, fmt.Errorf("invalid mqtt protocol version %d", config.Version)
+	}
+	if config.QoS > 2 {
+		return nil, fmt.Errorf("invalid mqtt qos %d", config.QoS)
+	}
+	if config.PayloadFormat == "" {
+		config.PayloadFormat = MqttPayloadProtobuf
+	}
+	if config.PayloadFormat != MqttPayloadProtobuf && config.PayloadFormat != MqttPayloadJson {
+		return nil, fmt.Errorf("invalid mqtt payload format %s", config.PayloadFormat)
+	}
+	if config.StatusTopic == "" {
+		config.StatusTopic = DefaultMqttStatusTopic
+	}
+	if config.ResponseTopic == "" {
+		config.ResponseTopic = DefaultMqttResponseTopic
+	}
+
+	var client mqttClient
+	var err error
+	if config.Version == 5 {
+		client, err = newMqttV5Client(config)
+	} else {
+		client, err = newMqttV3Client(config)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &MqttPublisher{client: client, Config: config}, nil
+}
+
+func (p *MqttPublisher) Close() {
+	p.client.Disconnect()
+}
+
+func (p *MqttPublisher) Ping(ctx context.Context) error {
+	if !p.client.Connected() {
+		return fmt.Errorf("not connected to mqtt broker %s", p.Config.BrokerURL)
+	}
+	return nil
+}
+
+func (p *MqttPublisher) topic(template string, imei string, deviceType types.DeviceType) string {
+	return strings.NewReplacer(
+		"{imei}", imei,
+		"{device_type}", strings.ToLower(deviceType.String()),
+	).Replace(template)
+}
+
+func (p *MqttPublisher) marshal(m proto.Message) ([]byte, error) {
+	if p.Config.PayloadFormat == MqttPayloadJson {
+		return protojson.Marshal(m)
+	}
+	return proto.Marshal(m)
+}
+
+func (p *MqttPublisher) publish(topic string, retained bool, m proto.Message) error {
+	payload, err := p.marshal(m)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), mqttPublishTimeout)
+	defer cancel()
+	return p.client.Publish(ctx, topic, p.Config.QoS, retained, payload)
+}
+
+func (p *MqttPublisher) PublishDeviceStatus(status *types.DeviceStatus) error {
+	topic := p.topic(p.Config.StatusTopic, status.Imei, status.DeviceType)
+	return p.publish(topic, p.Config.RetainStatus, status)
+}
+
+func (p *MqttPublisher) PublishDeviceResponse(response *types.DeviceResponse, deviceType types.DeviceType) error {
+	topic := p.topic(p.Config.ResponseTopic, response.Imei, deviceType)
+	return p.publish(topic, false, response)
+}
+
+type MqttStore struct {
+	ProcessChan       chan *types.DeviceStatus
+	ResponseChan      chan *types.DeviceResponse
+	CloseChan         chan bool
+	CloseResponseChan chan bool
+	Publisher         *MqttPublisher
+	DeviceType        types.DeviceType // device responses don't carry the device type
+}
+
+func (s *MqttStore) GetProcessChan() chan *types.DeviceStatus {
+	return s.ProcessChan
+}
+
+func (s *MqttStore) GetResponseChan() chan *types.DeviceResponse {
+	return s.ResponseChan
+}
+
+func (s *MqttStore) GetCloseChan() chan bool {
+	return s.CloseChan
+}
+
+func (s *MqttStore) GetCloseResponseChan() chan bool {
+	return s.CloseResponseChan
+}
+
+func (s *MqttStore) Process(ctx context.Context) {
+	for {
+		select {
+		case deviceStatus := <-s.ProcessChan:
+			if err := timeStoreCall("mqtt", opStatus, func() error { return s.Publisher.PublishDeviceStatus(deviceStatus) }); err != nil {
+				logger.Error("failed to publish device status", zap.String("imei", deviceStatus.Imei), zap.Error(err))
+			}
+		case <-s.CloseChan:
+			logger.Sugar().Info("async mqtt store shutting down for device")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *MqttStore) Response(ctx context.Context) {
+	for {
+		select {
+		case deviceResponse := <-s.ResponseChan:
+			if err := timeStoreCall("mqtt", opResponse, func() error { return s.Publisher.PublishDeviceResponse(deviceResponse, s.DeviceType) }); err != nil {
+				logger.Error("failed to publish device response", zap.String("imei", deviceResponse.Imei), zap.Error(err))
+			}
+		case <-s.CloseResponseChan:
+			logger.Sugar().Info("async mqtt store shutting down for device")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
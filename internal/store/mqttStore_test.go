@@ -0,0 +1,223 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/types"
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// published is a publish the embedded broker took from a client
+type published struct {
+	protocolVersion byte
+	topic           string
+	qos             byte
+	retain          bool
+	payload         []byte
+}
+
+// recordingHook records every publish the broker takes
+type recordingHook struct {
+	mochi.HookBase
+	published chan published
+}
+
+func (h *recordingHook) ID() string {
+	return "recording"
+}
+
+func (h *recordingHook) Provides(b byte) bool {
+	return b == mochi.OnPublished
+}
+
+func (h *recordingHook) OnPublished(cl *mochi.Client, pk packets.Packet) {
+	h.published <- published{
+		protocolVersion: cl.Properties.ProtocolVersion,
+		topic:           pk.TopicName,
+		qos:             pk.FixedHeader.Qos,
+		retain:          pk.FixedHeader.Retain,
+		payload:         pk.Payload,
+	}
+}
+
+type testMqttBroker struct {
+	server   *mochi.Server
+	url      string
+	recorder *recordingHook
+	stopped  sync.Once
+}
+
+// startTestMqttBroker runs an embedded broker that takes 3.1, 3.1.1 and 5 clients
+func startTestMqttBroker(t *testing.T) *testMqttBroker {
+	server := mochi.New(&mochi.Options{Logger: slog.New(slog.NewTextHandler(io.Discard, nil))})
+	require.NoError(t, server.AddHook(new(auth.AllowHook), nil))
+	recorder := &recordingHook{published: make(chan published, 10)}
+	require.NoError(t, server.AddHook(recorder, nil))
+
+	listener := listeners.NewTCP(listeners.Config{ID: "test", Address: "127.0.0.1:0"})
+	require.NoError(t, server.AddListener(listener))
+	require.NoError(t, server.Serve())
+	broker := &testMqttBroker{server: server, url: "tcp://" + listener.Address(), recorder: recorder}
+	t.Cleanup(broker.stop)
+	return broker
+}
+
+func (b *testMqttBroker) stop() {
+	b.stopped.Do(func() { b.server.Close() })
+}
+
+func (b *testMqttBroker) nextPublish(t *testing.T) published {
+	select {
+	case p := <-b.recorder.published:
+		return p
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a publish")
+		return published{}
+	}
+}
+
+func makeTestMqttStore(publisher *MqttPublisher) *MqttStore {
+	return &MqttStore{
+		ProcessChan:       make(chan *types.DeviceStatus, 10),
+		ResponseChan:      make(chan *types.DeviceResponse, 10),
+		CloseChan:         make(chan bool, 1),
+		CloseResponseChan: make(chan bool, 1),
+		Publisher:         publisher,
+		DeviceType:        types.DeviceType_CONCOX,
+	}
+}
+
+func TestMqttStorePublishesJson(t *testing.T) {
+	for _, version := range []uint{3, 4, 5} {
+		t.Run(fmt.Sprintf("version %d", version), func(t *testing.T) {
+			testMqttStorePublishesJson(t, version)
+		})
+	}
+}
+
+func testMqttStorePublishesJson(t *testing.T, version uint) {
+	broker := startTestMqttBroker(t)
+	publisher, err := NewMqttPublisher(MqttPublisherConfig{
+		BrokerURL:     broker.url,
+		ClientID:      "test",
+		Version:       version,
+		QoS:           1,
+		RetainStatus:  true,
+		PayloadFormat: MqttPayloadJson,
+	})
+	require.NoError(t, err)
+	defer publisher.Close()
+	assert.NoError(t, publisher.Ping(context.Background()))
+
+	s := makeTestMqttStore(publisher)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Process(ctx)
+	go s.Response(ctx)
+
+	s.GetProcessChan() <- &types.DeviceStatus{
+		Imei:       "356307043721579",
+		DeviceType: types.DeviceType_TELTONIKA,
+		Position:   &types.GPSPosition{Latitude: 28.61, Longitude: 77.2},
+	}
+	published := broker.nextPublish(t)
+	assert.Equal(t, byte(version), published.protocolVersion)
+	assert.Equal(t, "avl/teltonika/356307043721579/status", published.topic)
+	assert.Equal(t, byte(1), published.qos)
+	assert.True(t, published.retain, "device status should be retained")
+
+	var status types.DeviceStatus
+	require.NoError(t, protojson.Unmarshal(published.payload, &status))
+	assert.Equal(t, "356307043721579", status.Imei)
+	assert.InDelta(t, 28.61, status.Position.Latitude, 0.001)
+
+	s.GetResponseChan() <- &types.DeviceResponse{Imei: "356307043721579", Response: "DOUT1:1"}
+	published = broker.nextPublish(t)
+	assert.Equal(t, "avl/concox/356307043721579/response", published.topic)
+	assert.False(t, published.retain, "device responses should not be retained")
+
+	var response types.DeviceResponse
+	require.NoError(t, protojson.Unmarshal(published.payload, &response))
+	assert.Equal(t, "DOUT1:1", response.Response)
+}
+
+func TestMqttStorePublishesProtobuf(t *testing.T) {
+	broker := startTestMqttBroker(t)
+	publisher, err := NewMqttPublisher(MqttPublisherConfig{
+		BrokerURL:   broker.url,
+		ClientID:    "test",
+		Version:     5,
+		StatusTopic: "fleet/{imei}",
+	})
+	require.NoError(t, err)
+	defer publisher.Close()
+
+	s := makeTestMqttStore(publisher)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.Process(ctx)
+
+	s.GetProcessChan() <- &types.DeviceStatus{Imei: "752533678900242", MessageType: "alarm"}
+	published := broker.nextPublish(t)
+	assert.Equal(t, "fleet/752533678900242", published.topic)
+	assert.Equal(t, byte(0), published.qos)
+	assert.False(t, published.retain)
+
+	var status types.DeviceStatus
+	require.NoError(t, proto.Unmarshal(published.payload, &status))
+	assert.Equal(t, "alarm", status.MessageType)
+
+	s.GetCloseChan() <- true
+}
+
+func TestMqttPingFailsOnceTheBrokerIsGone(t *testing.T) {
+	for _, version := range []uint{4, 5} {
+		broker := startTestMqttBroker(t)
+		publisher, err := NewMqttPublisher(MqttPublisherConfig{BrokerURL: broker.url, ClientID: "test", Version: version})
+		require.NoError(t, err)
+		assert.NoError(t, publisher.Ping(context.Background()), "version %d", version)
+
+		broker.stop()
+		assert.Eventually(t, func() bool { return publisher.Ping(context.Background()) != nil }, 5*time.Second, 10*time.Millisecond, "version %d", version)
+		publisher.Close()
+	}
+}
+
+func TestMqttPublisherRejectsInvalidConfig(t *testing.T) {
+	_, err := NewMqttPublisher(MqttPublisherConfig{BrokerURL: "tcp://127.0.0.1:1", QoS: 3})
+	assert.Error(t, err)
+
+	_, err = NewMqttPublisher(MqttPublisherConfig{BrokerURL: "tcp://127.0.0.1:1", PayloadFormat: "xml"})
+	assert.Error(t, err)
+
+	_, err = NewMqttPublisher(MqttPublisherConfig{BrokerURL: "tcp://127.0.0.1:1", Version: 6})
+	assert.Error(t, err)
+}
+
+func TestMqttProtocolVersion(t *testing.T) {
+	version, err := MqttProtocolVersion("3.1.1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(4), version)
+	version, err = MqttProtocolVersion("3.1")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(3), version)
+
+	version, err = MqttProtocolVersion("5")
+	assert.NoError(t, err)
+	assert.Equal(t, uint(5), version)
+	_, err = MqttProtocolVersion("4")
+	assert.Error(t, err)
+}
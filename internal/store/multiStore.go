@@ -0,0 +1,85 @@
+package store
+
+import (
+	"context"
+
+	"github.com/404minds/avl-receiver/internal/types"
+)
+
+// MultiStore fans every device status and response out to several stores,
+// e.g. storeType "remote,mqtt" saves to the data store and publishes to mqtt
+type MultiStore struct {
+	ProcessChan       chan *types.DeviceStatus
+	ResponseChan      chan *types.DeviceResponse
+	CloseChan         chan bool
+	CloseResponseChan chan bool
+	Stores            []Store
+}
+
+func (s *MultiStore) GetProcessChan() chan *types.DeviceStatus {
+	return s.ProcessChan
+}
+
+func (s *MultiStore) GetResponseChan() chan *types.DeviceResponse {
+	return s.ResponseChan
+}
+
+func (s *MultiStore) GetCloseChan() chan bool {
+	return s.CloseChan
+}
+
+func (s *MultiStore) GetCloseResponseChan() chan bool {
+	return s.CloseResponseChan
+}
+
+func (s *MultiStore) Process(ctx context.Context) {
+	for _, child := range s.Stores {
+		go child.Process(ctx)
+	}
+
+	for {
+		select {
+		case deviceStatus := <-s.ProcessChan:
+			for _, child := range s.Stores {
+				select {
+				case child.GetProcessChan() <- deviceStatus:
+				case <-ctx.Done():
+					return
+				}
+			}
+		case <-s.CloseChan:
+			for _, child := range s.Stores {
+				child.GetCloseChan() <- true
+			}
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *MultiStore) Response(ctx context.Context) {
+	for _, child := range s.Stores {
+		go child.Response(ctx)
+	}
+
+	for {
+		select {
+		case deviceResponse := <-s.ResponseChan:
+			for _, child := range s.Stores {
+				select {
+				case child.GetResponseChan() <- deviceResponse:
+				case <-ctx.Done():
+					return
+				}
+			}
+		case <-s.CloseResponseChan:
+			for _, child := range s.Stores {
+				child.GetCloseResponseChan() <- true
+			}
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
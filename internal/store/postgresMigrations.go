@@ -0,0 +1,113 @@
+package store
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed migrations/postgres/*.sql
+var postgresMigrationFiles embed.FS
+
+type postgresMigration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// postgresMigrations returns the embedded migrations ordered by the version prefix of their file name
+func postgresMigrations() ([]postgresMigration, error) {
+	entries, err := fs.ReadDir(postgresMigrationFiles, "migrations/postgres")
+	if err != nil {
+		return nil, err
+	}
+
+	var migrations []postgresMigration
+	for _, entry := range entries {
+		prefix, _, _ := strings.Cut(entry.Name(), "_")
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has no version prefix", entry.Name())
+		}
+		sql, err := postgresMigrationFiles.ReadFile(path.Join("migrations/postgres", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, postgresMigration{version: version, name: entry.Name(), sql: string(sql)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].version == migrations[i-1].version {
+			return nil, fmt.Errorf("duplicate migration version %d", migrations[i].version)
+		}
+	}
+	return migrations, nil
+}
+
+// MigratePostgres applies the migrations that haven't been applied yet, each one in its own transaction
+func MigratePostgres(ctx context.Context, pool *pgxpool.Pool) error {
+	migrations, err := postgresMigrations()
+	if err != nil {
+		return err
+	}
+
+	_, err = pool.Exec(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    integer     PRIMARY KEY,
+		applied_at timestamptz NOT NULL DEFAULT now()
+	)`)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		err := pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+			// serializes concurrent migrators, the lock is released with the transaction
+			if _, err := tx.Exec(ctx, "LOCK TABLE schema_migrations IN EXCLUSIVE MODE"); err != nil {
+				return err
+			}
+
+			var applied bool
+			err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)", m.version).Scan(&applied)
+			if err != nil || applied {
+				return err
+			}
+
+			if _, err := tx.Exec(ctx, m.sql); err != nil {
+				return err
+			}
+			_, err = tx.Exec(ctx, "INSERT INTO schema_migrations (version) VALUES ($1)", m.version)
+			if err == nil {
+				logger.Sugar().Infof("applied postgres migration %s", m.name)
+			}
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", m.name, err)
+		}
+	}
+	return nil
+}
+
+// EnableTimescale turns the tables into hypertables partitioned by time, it is safe to run more than once
+func EnableTimescale(ctx context.Context, pool *pgxpool.Pool) error {
+	statements := []string{
+		"CREATE EXTENSION IF NOT EXISTS timescaledb",
+		"SELECT create_hypertable('device_status', 'ts', if_not_exists => TRUE, migrate_data => TRUE)",
+		"SELECT create_hypertable('device_responses', 'received_at', if_not_exists => TRUE, migrate_data => TRUE)",
+	}
+	for _, statement := range statements {
+		if _, err := pool.Exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
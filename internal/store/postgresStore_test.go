@@ -0,0 +1,195 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func TestDeviceStatusRow(t *testing.T) {
+	speed := float32(42.5)
+	ignition := true
+	ts := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	receivedAt := ts.Add(time.Second)
+
+	row := deviceStatusRow(&types.DeviceStatus{
+		Imei:          "350424063817363",
+		DeviceType:    types.DeviceType_TELTONIKA,
+		Timestamp:     timestamppb.New(ts),
+		Position:      &types.GPSPosition{Latitude: 28.6, Longitude: 77.2, Speed: &speed, Satellites: 9},
+		VehicleStatus: &types.VehicleStatus{Ignition: &ignition, OverSpeeding: true},
+		Rpm:           1800,
+		RunTime:       3600,
+	}, receivedAt)
+	require.Len(t, row, len(deviceStatusColumns))
+
+	values := make(map[string]any)
+	for i, column := range deviceStatusColumns {
+		values[column] = row[i]
+	}
+	assert.Equal(t, "TELTONIKA", values["device_type"])
+	assert.Equal(t, ts, values["ts"])
+	assert.Equal(t, receivedAt, values["received_at"])
+	assert.InDelta(t, 28.6, *values["latitude"].(*float64), 0.0001)
+	assert.Equal(t, &speed, values["speed"])
+	assert.Equal(t, int32(9), *values["satellites"].(*int32))
+	assert.Equal(t, &ignition, values["ignition"])
+	assert.JSONEq(t, `{"ignition":true,"overSpeeding":true}`, string(values["vehicle_status"].([]byte)))
+	assert.Equal(t, int32(1800), values["rpm"])
+	assert.Equal(t, int64(3600), values["run_time"])
+}
+
+func TestDeviceStatusRowWithoutPosition(t *testing.T) {
+	receivedAt := time.Now()
+	row := deviceStatusRow(&types.DeviceStatus{Imei: "350424063817363", MessageType: "heartbeat"}, receivedAt)
+
+	values := make(map[string]any)
+	for i, column := range deviceStatusColumns {
+		values[column] = row[i]
+	}
+	assert.Equal(t, receivedAt, values["ts"], "falls back to the receive time")
+	assert.Nil(t, values["latitude"])
+	assert.Nil(t, values["speed"])
+	assert.Nil(t, values["ignition"])
+	assert.Nil(t, values["vehicle_status"])
+}
+
+func TestPostgresMigrationsAreOrdered(t *testing.T) {
+	migrations, err := postgresMigrations()
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.version, m.name)
+	}
+}
+
+func TestPostgresWriterKeepsFailedBatches(t *testing.T) {
+	var copies int
+	var copied [][]any
+	failing := true
+	w := newPostgresWriter(PostgresWriterConfig{BatchSize: 2, FlushInterval: time.Second, MaxPending: 3}, func(ctx context.Context, table string, columns []string, rows [][]any) error {
+		copies++
+		if failing {
+			return errors.New("connection refused")
+		}
+		copied = append(copied, rows...)
+		return nil
+	})
+	p := &pendingRows{table: "device_status", op: opStatus, columns: deviceStatusColumns}
+	dropped := testutil.ToFloat64(storeRowsDroppedTotal.WithLabelValues("postgres", opStatus))
+
+	w.add(p, []any{1})
+	w.add(p, []any{2})
+	assert.Equal(t, 1, copies)
+	assert.Len(t, p.rows, 2, "a failed batch is kept")
+
+	w.add(p, []any{3})
+	assert.Equal(t, 1, copies, "no copies while backing off")
+	w.add(p, []any{4})
+	assert.Equal(t, [][]any{{2}, {3}, {4}}, p.rows, "the oldest rows go past MaxPending")
+	assert.Equal(t, dropped+1, testutil.ToFloat64(storeRowsDroppedTotal.WithLabelValues("postgres", opStatus)))
+
+	w.flush(p, p.retryAt)
+	assert.Equal(t, 2, copies)
+	assert.Equal(t, 2*time.Second, p.backoff, "the backoff doubles")
+
+	failing = false
+	w.flush(p, p.retryAt.Add(-time.Millisecond))
+	assert.Equal(t, 2, copies)
+	w.flush(p, p.retryAt)
+	assert.Equal(t, [][]any{{2}, {3}, {4}}, copied)
+	assert.Empty(t, p.rows)
+	assert.Zero(t, p.backoff)
+}
+
+func TestPostgresWriterDropsOnlyBadRows(t *testing.T) {
+	var copied [][]any
+	transient := false
+	// rows holding a negative number are ones postgres won't take
+	w := newPostgresWriter(PostgresWriterConfig{BatchSize: 8, FlushInterval: time.Second, MaxPending: 100}, func(ctx context.Context, table string, columns []string, rows [][]any) error {
+		if transient && len(rows) == 1 {
+			return errors.New("connection refused")
+		}
+		for _, row := range rows {
+			if row[0].(int) < 0 {
+				return &pgconn.PgError{Code: "22021", Message: "invalid byte sequence for encoding \"UTF8\": 0x00"}
+			}
+		}
+		copied = append(copied, rows...)
+		return nil
+	})
+	p := &pendingRows{table: "device_status", op: opStatus, columns: deviceStatusColumns}
+	dropped := storeRowsDroppedTotal.WithLabelValues("postgres", opStatus)
+	before := testutil.ToFloat64(dropped)
+
+	for _, v := range []int{1, -2, 3, 4, 5, 6, -7, 8} {
+		w.add(p, []any{v})
+	}
+	assert.Equal(t, [][]any{{1}, {3}, {4}, {5}, {6}, {8}}, copied, "the good rows get through in order")
+	assert.Empty(t, p.rows)
+	assert.Zero(t, p.backoff)
+	assert.Equal(t, before+2, testutil.ToFloat64(dropped), "only the bad rows are dropped")
+
+	// postgres going away halfway through keeps the rows that weren't copied yet
+	copied = nil
+	transient = true
+	for _, v := range []int{1, 2, -3, 4, 5, 6, 7, 8} {
+		w.add(p, []any{v})
+	}
+	assert.Equal(t, [][]any{{1}, {2}}, copied)
+	assert.Equal(t, [][]any{{-3}, {4}, {5}, {6}, {7}, {8}}, p.rows)
+	assert.Equal(t, time.Second, p.backoff)
+
+	transient = false
+	w.flush(p, p.retryAt)
+	assert.Equal(t, [][]any{{1}, {2}, {4}, {5}, {6}, {7}, {8}}, copied)
+	assert.Empty(t, p.rows)
+	assert.Equal(t, before+3, testutil.ToFloat64(dropped))
+
+	assert.False(t, isBadRowError(errors.New("connection refused")))
+	assert.False(t, isBadRowError(&pgconn.PgError{Code: "57P01"}), "an admin shutdown is worth retrying")
+	assert.True(t, isBadRowError(fmt.Errorf("copy: %w", &pgconn.PgError{Code: "23505"})))
+}
+
+// runs against a real database when AVL_TEST_POSTGRES_URL is set
+func TestPostgresWriter(t *testing.T) {
+	url := os.Getenv("AVL_TEST_POSTGRES_URL")
+	if url == "" {
+		t.Skip("AVL_TEST_POSTGRES_URL not set")
+	}
+
+	ctx := context.Background()
+	writer, err := NewPostgresWriter(ctx, PostgresWriterConfig{URL: url, BatchSize: 2, FlushInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	require.NoError(t, MigratePostgres(ctx, writer.Pool))
+	require.NoError(t, MigratePostgres(ctx, writer.Pool), "migrations are idempotent")
+
+	imei := "test-" + time.Now().Format("150405.000000")
+	for i := 0; i < 5; i++ {
+		require.NoError(t, writer.WriteDeviceStatus(&types.DeviceStatus{Imei: imei, Rpm: int32(i)}))
+	}
+	require.NoError(t, writer.WriteDeviceResponse(&types.DeviceResponse{Imei: imei, Response: "OK"}))
+
+	writer.Close()
+
+	pool, err := pgxpool.New(ctx, url)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	var statuses, responses int
+	require.NoError(t, pool.QueryRow(ctx, "SELECT count(*) FROM device_status WHERE imei = $1", imei).Scan(&statuses))
+	require.NoError(t, pool.QueryRow(ctx, "SELECT count(*) FROM device_responses WHERE imei = $1", imei).Scan(&responses))
+	assert.Equal(t, 5, statuses)
+	assert.Equal(t, 1, responses)
+}
@@ -0,0 +1,133 @@
+package store
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+)
+
+const (
+	DefaultRemoteCallTimeout   = 10 * time.Second
+	DefaultRemoteVerifyTimeout = 3 * time.Second
+	// grpc servers by default drop clients pinging more often than every 5 minutes
+	DefaultRemoteKeepalive = 5 * time.Minute
+)
+
+// RemoteStoreConfig is how the receiver connects to the AvlDataStore
+type RemoteStoreConfig struct {
+	// Addrs are the data store endpoints, calls are balanced round robin across them. A single
+	// address can also be a dns:/// target resolving to several endpoints.
+	Addrs       []string
+	TLS         *tls.Config // plaintext if nil
+	MaxAttempts int         // per call, including the first one, for calls failing with UNAVAILABLE
+	Keepalive   time.Duration
+}
+
+// serviceConfig balances across all endpoints and retries calls that never reached a healthy store.
+// Only UNAVAILABLE is retried, the store didn't process those calls so saving statuses twice isn't a concern.
+func (c RemoteStoreConfig) serviceConfig() string {
+	maxAttempts := c.MaxAttempts
+	if maxAttempts < 2 {
+		return `{"loadBalancingConfig": [{"round_robin": {}}]}`
+	}
+	return fmt.Sprintf(`{
+  "loadBalancingConfig": [{"round_robin": {}}],
+  "methodConfig": [{
+    "name": [{}],
+    "retryPolicy": {
+      "maxAttempts": %d,
+      "initialBackoff": "0.1s",
+      "maxBackoff": "2s",
+      "backoffMultiplier": 2,
+      "retryableStatusCodes": ["UNAVAILABLE"]
+    }
+  }]
+}`, maxAttempts)
+}
+
+// DialRemoteStore connects to the data store endpoints. The connection is made lazily, calls
+// made while no endpoint is reachable fail right away with UNAVAILABLE instead of waiting.
+func DialRemoteStore(config RemoteStoreConfig) (*grpc.ClientConn, error) {
+	if len(config.Addrs) == 0 {
+		return nil, errors.New("no remote store address")
+	}
+
+	creds := insecure.NewCredentials()
+	if config.TLS != nil {
+		creds = credentials.NewTLS(config.TLS)
+	}
+	opts := []grpc.DialOption{
+		grpc.WithTransportCredentials(creds),
+		grpc.WithDefaultServiceConfig(config.serviceConfig()),
+	}
+	if config.Keepalive > 0 {
+		opts = append(opts, grpc.WithKeepaliveParams(keepalive.ClientParameters{
+			Time:    config.Keepalive,
+			Timeout: 20 * time.Second,
+		}))
+	}
+
+	target := config.Addrs[0]
+	if len(config.Addrs) > 1 {
+		// a fixed list of endpoints, resolved by a resolver local to this connection
+		r := manual.NewBuilderWithScheme("avlstore")
+		state := resolver.State{}
+		for _, addr := range config.Addrs {
+			state.Addresses = append(state.Addresses, resolver.Address{Addr: addr})
+		}
+		r.InitialState(state)
+		opts = append(opts, grpc.WithResolvers(r))
+		target = r.Scheme() + ":///datastore"
+	}
+	return grpc.NewClient(target, opts...)
+}
+
+// SplitAddrs parses a comma separated list of endpoints
+func SplitAddrs(addrs string) []string {
+	var res []string
+	for _, addr := range strings.Split(addrs, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			res = append(res, addr)
+		}
+	}
+	return res
+}
+
+// RemoteStoreTLSConfig verifies the data store against caFile, or the system roots if empty. With
+// certFile and keyFile the receiver also presents a client certificate for mTLS.
+func RemoteStoreTLSConfig(caFile, certFile, keyFile, serverName string) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName: serverName,
+		MinVersion: tls.VersionTLS12,
+	}
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", caFile)
+		}
+		config.RootCAs = pool
+	}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	errs "github.com/404minds/avl-receiver/internal/errors"
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeDataStore struct {
+	UnimplementedAvlDataStoreServer
+	verified    atomic.Int32
+	unavailable atomic.Int32 // calls to fail with UNAVAILABLE before answering
+}
+
+func (f *fakeDataStore) VerifyDevice(ctx context.Context, req *VerifyDeviceRequest) (*VerifyDeviceReply, error) {
+	if f.unavailable.Add(-1) >= 0 {
+		return nil, status.Error(codes.Unavailable, "warming up")
+	}
+	f.verified.Add(1)
+	return &VerifyDeviceReply{Imei: req.Imei, DeviceType: types.DeviceType_TELTONIKA}, nil
+}
+
+func (f *fakeDataStore) FetchDeviceModel(ctx context.Context, req *FetchDeviceModelRequest) (*FetchDeviceModelResponse, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func startFakeDataStore(t *testing.T) (*fakeDataStore, string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	fake := &fakeDataStore{}
+	s := grpc.NewServer()
+	RegisterAvlDataStoreServer(s, fake)
+	go func() { _ = s.Serve(listener) }()
+	t.Cleanup(s.Stop)
+	return fake, listener.Addr().String()
+}
+
+func dialFakeDataStore(t *testing.T, config RemoteStoreConfig) *CustomAvlDataStoreClient {
+	conn, err := DialRemoteStore(config)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return NewCustomAvlDataStoreClient(conn, "/store.AvlDataStore")
+}
+
+func TestRemoteStoreBalancesAcrossEndpoints(t *testing.T) {
+	first, firstAddr := startFakeDataStore(t)
+	second, secondAddr := startFakeDataStore(t)
+	client := dialFakeDataStore(t, RemoteStoreConfig{Addrs: SplitAddrs(firstAddr + ", " + secondAddr)})
+
+	for i := 0; i < 20; i++ {
+		_, err := client.VerifyDevice(context.Background(), &VerifyDeviceRequest{Imei: "357454075177072"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(20), first.verified.Load()+second.verified.Load())
+	// round robin only kicks in once both subchannels are ready, the first calls may all go to one
+	assert.NotZero(t, first.verified.Load())
+	assert.NotZero(t, second.verified.Load())
+}
+
+func TestRemoteStoreRetriesUnavailable(t *testing.T) {
+	fake, addr := startFakeDataStore(t)
+	fake.unavailable.Store(2)
+	client := dialFakeDataStore(t, RemoteStoreConfig{Addrs: []string{addr}, MaxAttempts: 3})
+
+	reply, err := client.VerifyDevice(context.Background(), &VerifyDeviceRequest{Imei: "357454075177072"})
+	require.NoError(t, err)
+	assert.Equal(t, "357454075177072", reply.Imei)
+
+	fake.unavailable.Store(3)
+	_, err = client.VerifyDevice(context.Background(), &VerifyDeviceRequest{Imei: "357454075177072"})
+	assert.ErrorIs(t, err, errs.ErrStoreUnavailable)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestRemoteStoreCallDeadline(t *testing.T) {
+	_, addr := startFakeDataStore(t)
+	client := dialFakeDataStore(t, RemoteStoreConfig{Addrs: []string{addr}})
+	client.SetTimeouts(100*time.Millisecond, DefaultRemoteVerifyTimeout)
+
+	start := time.Now()
+	_, err := client.FetchDeviceModel(context.Background(), &types.FetchDeviceModelRequest{})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestVerifyDeviceFailsFastWhenStoreIsDown(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	client := dialFakeDataStore(t, RemoteStoreConfig{Addrs: []string{addr}, MaxAttempts: 3})
+
+	start := time.Now()
+	_, err = client.VerifyDevice(context.Background(), &VerifyDeviceRequest{Imei: "357454075177072"})
+	assert.ErrorIs(t, err, errs.ErrStoreUnavailable)
+	assert.Less(t, time.Since(start), DefaultRemoteVerifyTimeout)
+}
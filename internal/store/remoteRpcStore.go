@@ -35,9 +35,12 @@ func (s *RemoteRpcStore) Process(ctx context.Context) {
 	for {
 		select {
 		case deviceStatus := <-s.ProcessChan:
-			logger.Sugar().Infoln(deviceStatus.String())
+			logger.Debug("saving device status", zap.String("imei", deviceStatus.Imei), zap.Stringer("status", deviceStatus))
 			ctx := context.Background()
-			_, err := s.RemoteStoreClient.SaveDeviceStatus(ctx, deviceStatus)
+			err := timeStoreCall("remote", opStatus, func() error {
+				_, err := s.RemoteStoreClient.SaveDeviceStatus(ctx, deviceStatus)
+				return err
+			})
 			if err != nil {
 				logger.Error("failed to save device status", zap.String("imei", deviceStatus.Imei), zap.Error(err))
 			}
@@ -56,9 +59,12 @@ func (s *RemoteRpcStore) Response(ctx context.Context) {
 	for {
 		select {
 		case deviceResponse := <-s.ResponseChan:
-			logger.Sugar().Info(deviceResponse.String())
+			logger.Debug("saving device response", zap.String("imei", deviceResponse.Imei), zap.Stringer("response", deviceResponse))
 
-			_, err := s.RemoteStoreClient.SaveDeviceResponse(ctx, deviceResponse)
+			err := timeStoreCall("remote", opResponse, func() error {
+				_, err := s.RemoteStoreClient.SaveDeviceResponse(ctx, deviceResponse)
+				return err
+			})
 			if err != nil {
 				logger.Error("failed to save device status", zap.String("imei", deviceResponse.Imei), zap.Error(err))
 			}
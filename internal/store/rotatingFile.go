@@ -0,0 +1,293 @@
+package store
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const segmentTimeFormat = "20060102T150405"
+
+var ErrFileClosed = errors.New("file is closed")
+
+type RotationConfig struct {
+	Dir            string
+	RotateInterval time.Duration // start a new segment on every interval boundary (UTC), e.g. 24h for daily files; 0 disables
+	MaxSize        int64         // start a new segment once the current one would grow past this many bytes; 0 disables
+	Compress       bool          // gzip closed segments
+	Retention      time.Duration // delete closed segments older than this; 0 keeps them forever
+	FlushInterval  time.Duration // buffered lines are flushed and fsynced this often
+}
+
+// RotatingFile appends lines to <dir>/<name>.json and moves it aside to <name>-<segment start>.json
+// (gzipped if configured) when it rotates. Writes are buffered, a background goroutine flushes
+// and fsyncs them every FlushInterval.
+type RotatingFile struct {
+	config RotationConfig
+	name   string
+
+	mu           sync.Mutex
+	file         *os.File
+	buf          *bufio.Writer
+	size         int64
+	segmentStart time.Time
+	dirty        bool
+	closed       bool
+
+	stop       chan struct{}
+	background sync.WaitGroup
+	now        func() time.Time
+}
+
+func OpenRotatingFile(name string, config RotationConfig) (*RotatingFile, error) {
+	return openRotatingFile(name, config, time.Now)
+}
+
+func openRotatingFile(name string, config RotationConfig, now func() time.Time) (*RotatingFile, error) {
+	if config.Dir == "" {
+		config.Dir = "."
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if err := os.MkdirAll(config.Dir, 0755); err != nil {
+		return nil, err
+	}
+
+	f := &RotatingFile{config: config, name: name, stop: make(chan struct{}), now: now}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	// a file left over from an earlier period goes straight into its own segment
+	if f.size > 0 && f.shouldRotate(0) {
+		if err := f.rotate(); err != nil {
+			return nil, err
+		}
+	}
+	f.removeExpiredSegments()
+
+	f.background.Add(1)
+	go f.flushLoop()
+	return f, nil
+}
+
+func (f *RotatingFile) activePath() string {
+	return filepath.Join(f.config.Dir, f.name+".json")
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.activePath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.buf = bufio.NewWriterSize(file, 64*1024)
+	f.size = info.Size()
+	f.segmentStart = f.now()
+	if f.size > 0 {
+		f.segmentStart = info.ModTime()
+	}
+	if f.config.RotateInterval > 0 {
+		f.segmentStart = f.segmentStart.UTC().Truncate(f.config.RotateInterval)
+	}
+	return nil
+}
+
+func (f *RotatingFile) shouldRotate(nextWrite int) bool {
+	if f.config.RotateInterval > 0 && !f.now().UTC().Before(f.segmentStart.Add(f.config.RotateInterval)) {
+		return true
+	}
+	return f.config.MaxSize > 0 && f.size > 0 && f.size+int64(nextWrite) > f.config.MaxSize
+}
+
+// WriteLine appends the line and a newline
+func (f *RotatingFile) WriteLine(line []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return ErrFileClosed
+	}
+	if f.shouldRotate(len(line) + 1) {
+		if err := f.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := f.buf.Write(line)
+	if err == nil {
+		err = f.buf.WriteByte('\n')
+		n++
+	}
+	f.size += int64(n)
+	f.dirty = true
+	return err
+}
+
+// rotate closes the active file, moves it aside and opens a fresh one. Called with mu held.
+func (f *RotatingFile) rotate() error {
+	if err := f.syncLocked(); err != nil {
+		return err
+	}
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+
+	segment := f.segmentPath(f.segmentStart)
+	if err := os.Rename(f.activePath(), segment); err != nil {
+		return err
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.background.Add(1)
+	go func() {
+		defer f.background.Done()
+		if f.config.Compress {
+			if err := compressSegment(segment); err != nil {
+				logger.Error("failed to compress segment", zap.String("segment", segment), zap.Error(err))
+			}
+		}
+		f.removeExpiredSegments()
+	}()
+	return nil
+}
+
+// segmentPath names the segment after its start, with a counter when size based rotation
+// produces several segments within the same second
+func (f *RotatingFile) segmentPath(start time.Time) string {
+	base := filepath.Join(f.config.Dir, fmt.Sprintf("%s-%s", f.name, start.UTC().Format(segmentTimeFormat)))
+	path := base + ".json"
+	for i := 1; fileExists(path) || fileExists(path+".gz"); i++ {
+		path = fmt.Sprintf("%s.%d.json", base, i)
+	}
+	return path
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func compressSegment(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	tmp := path + ".gz.tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = dst.Sync()
+	}
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, path+".gz"); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+func (f *RotatingFile) removeExpiredSegments() {
+	if f.config.Retention <= 0 {
+		return
+	}
+
+	segments, err := filepath.Glob(filepath.Join(f.config.Dir, f.name+"-*.json*"))
+	if err != nil {
+		return
+	}
+	cutoff := f.now().Add(-f.config.Retention)
+	for _, segment := range segments {
+		if strings.HasSuffix(segment, ".tmp") {
+			continue
+		}
+		info, err := os.Stat(segment)
+		if err != nil || info.ModTime().After(cutoff) {
+			continue
+		}
+		if err := os.Remove(segment); err != nil {
+			logger.Error("failed to remove expired segment", zap.String("segment", segment), zap.Error(err))
+		}
+	}
+}
+
+func (f *RotatingFile) flushLoop() {
+	defer f.background.Done()
+
+	ticker := time.NewTicker(f.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.mu.Lock()
+			if err := f.syncLocked(); err != nil {
+				logger.Error("failed to flush file", zap.String("file", f.activePath()), zap.Error(err))
+			}
+			f.mu.Unlock()
+		case <-f.stop:
+			return
+		}
+	}
+}
+
+func (f *RotatingFile) syncLocked() error {
+	if !f.dirty {
+		return nil
+	}
+	if err := f.buf.Flush(); err != nil {
+		return err
+	}
+	f.dirty = false
+	return f.file.Sync()
+}
+
+// Close flushes pending lines and waits for compressions still running
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	if f.closed {
+		f.mu.Unlock()
+		return nil
+	}
+	f.closed = true
+	err := f.syncLocked()
+	if closeErr := f.file.Close(); err == nil {
+		err = closeErr
+	}
+	f.mu.Unlock()
+
+	close(f.stop)
+	f.background.Wait()
+	return err
+}
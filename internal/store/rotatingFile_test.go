@@ -0,0 +1,128 @@
+package store
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	mu sync.Mutex
+	t  time.Time
+}
+
+func (c *fakeClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.t
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(d)
+}
+
+func readSegment(t *testing.T, path string) string {
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer f.Close()
+
+	var r io.Reader = f
+	if filepath.Ext(path) == ".gz" {
+		zr, err := gzip.NewReader(f)
+		require.NoError(t, err)
+		r = zr
+	}
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(b)
+}
+
+func TestRotatingFileRotatesDaily(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2024, 5, 1, 23, 59, 0, 0, time.UTC)}
+	f, err := openRotatingFile("350424063817363", RotationConfig{Dir: dir, RotateInterval: 24 * time.Hour, Compress: true}, clock.now)
+	require.NoError(t, err)
+
+	require.NoError(t, f.WriteLine([]byte(`{"n":1}`)))
+	clock.advance(2 * time.Minute)
+	require.NoError(t, f.WriteLine([]byte(`{"n":2}`)))
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, "{\"n\":1}\n", readSegment(t, filepath.Join(dir, "350424063817363-20240501T000000.json.gz")))
+	assert.Equal(t, "{\"n\":2}\n", readSegment(t, filepath.Join(dir, "350424063817363.json")))
+	assert.NoFileExists(t, filepath.Join(dir, "350424063817363-20240501T000000.json"))
+}
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	dir := t.TempDir()
+	clock := &fakeClock{t: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	f, err := openRotatingFile("dev", RotationConfig{Dir: dir, MaxSize: 10}, clock.now)
+	require.NoError(t, err)
+
+	for _, line := range []string{"aaaa", "bbbb", "cccc"} {
+		require.NoError(t, f.WriteLine([]byte(line)))
+	}
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, "aaaa\nbbbb\n", readSegment(t, filepath.Join(dir, "dev-20240501T100000.json")))
+	assert.Equal(t, "cccc\n", readSegment(t, filepath.Join(dir, "dev.json")))
+}
+
+func TestRotatingFileRetention(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "dev-20240101T000000.json.gz")
+	recent := filepath.Join(dir, "dev-20240430T000000.json.gz")
+	other := filepath.Join(dir, "other-20240101T000000.json.gz")
+	for _, path := range []string{old, recent, other} {
+		require.NoError(t, os.WriteFile(path, nil, 0644))
+	}
+	now := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(old, now.Add(-30*24*time.Hour), now.Add(-30*24*time.Hour)))
+	require.NoError(t, os.Chtimes(recent, now.Add(-time.Hour), now.Add(-time.Hour)))
+	require.NoError(t, os.Chtimes(other, now.Add(-30*24*time.Hour), now.Add(-30*24*time.Hour)))
+
+	f, err := openRotatingFile("dev", RotationConfig{Dir: dir, Retention: 7 * 24 * time.Hour}, func() time.Time { return now })
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	assert.NoFileExists(t, old)
+	assert.FileExists(t, recent)
+	assert.FileExists(t, other, "segments of other devices are left alone")
+}
+
+func TestRotatingFileFlushesPeriodically(t *testing.T) {
+	dir := t.TempDir()
+	f, err := OpenRotatingFile("dev", RotationConfig{Dir: dir, FlushInterval: 10 * time.Millisecond})
+	require.NoError(t, err)
+	defer f.Close()
+
+	require.NoError(t, f.WriteLine([]byte("buffered")))
+	assert.Equal(t, "", readSegment(t, filepath.Join(dir, "dev.json")), "writes are buffered")
+	assert.Eventually(t, func() bool {
+		return readSegment(t, filepath.Join(dir, "dev.json")) == "buffered\n"
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestRotatingFileRotatesLeftoverFileOnOpen(t *testing.T) {
+	dir := t.TempDir()
+	active := filepath.Join(dir, "dev.json")
+	require.NoError(t, os.WriteFile(active, []byte("yesterday\n"), 0644))
+	yesterday := time.Date(2024, 4, 30, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(active, yesterday, yesterday))
+
+	clock := &fakeClock{t: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)}
+	f, err := openRotatingFile("dev", RotationConfig{Dir: dir, RotateInterval: 24 * time.Hour}, clock.now)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	assert.Equal(t, "yesterday\n", readSegment(t, filepath.Join(dir, "dev-20240430T000000.json")))
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/types"
+)
+
+const (
+	defaultQueryLimit = 1000
+	maxQueryLimit     = 10000
+)
+
+// NewSqliteApiHandler serves a small json api over the embedded database:
+//
+//	GET    /api/devices
+//	GET    /api/devices/{imei}
+//	PUT    /api/devices/{imei}            {"device_type": "TELTONIKA", "name": "truck 12"}
+//	DELETE /api/devices/{imei}
+//	GET    /api/devices/{imei}/positions  ?from=<rfc3339>&to=<rfc3339>&limit=<n>
+//	GET    /api/devices/{imei}/responses  ?limit=<n>
+//	GET    /api/devices/{imei}/sessions   ?limit=<n>
+//	GET    /api/positions/latest
+func NewSqliteApiHandler(db *SqliteDB) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /api/devices", func(w http.ResponseWriter, r *http.Request) {
+		devices, err := db.ListDevices(r.Context())
+		writeJson(w, devices, err)
+	})
+
+	mux.HandleFunc("GET /api/devices/{imei}", func(w http.ResponseWriter, r *http.Request) {
+		device, err := db.GetDevice(r.Context(), r.PathValue("imei"))
+		writeJson(w, device, err)
+	})
+
+	mux.HandleFunc("PUT /api/devices/{imei}", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			DeviceType string `json:"device_type"`
+			Name       string `json:"name"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "invalid body: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		deviceType, ok := types.DeviceType_value[body.DeviceType]
+		if !ok {
+			http.Error(w, "unknown device_type "+body.DeviceType, http.StatusBadRequest)
+			return
+		}
+
+		imei := r.PathValue("imei")
+		if err := db.UpsertDevice(r.Context(), imei, types.DeviceType(deviceType), body.Name); err != nil {
+			writeJson(w, nil, err)
+			return
+		}
+		device, err := db.GetDevice(r.Context(), imei)
+		writeJson(w, device, err)
+	})
+
+	mux.HandleFunc("DELETE /api/devices/{imei}", func(w http.ResponseWriter, r *http.Request) {
+		if err := db.DeleteDevice(r.Context(), r.PathValue("imei")); err != nil {
+			writeJson(w, nil, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("GET /api/devices/{imei}/positions", func(w http.ResponseWriter, r *http.Request) {
+		limit, err := queryLimit(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		// the last day by default
+		to := time.Now()
+		from := to.Add(-24 * time.Hour)
+		if v := r.URL.Query().Get("from"); v != "" {
+			if from, err = time.Parse(time.RFC3339, v); err != nil {
+				http.Error(w, "invalid from: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if v := r.URL.Query().Get("to"); v != "" {
+			if to, err = time.Parse(time.RFC3339, v); err != nil {
+				http.Error(w, "invalid to: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+
+		positions, err := db.Positions(r.Context(), r.PathValue("imei"), from, to, limit)
+		writeJson(w, positions, err)
+	})
+
+	mux.HandleFunc("GET /api/devices/{imei}/responses", func(w http.ResponseWriter, r *http.Request) {
+		limit, err := queryLimit(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		responses, err := db.Responses(r.Context(), r.PathValue("imei"), limit)
+		writeJson(w, responses, err)
+	})
+
+	mux.HandleFunc("GET /api/devices/{imei}/sessions", func(w http.ResponseWriter, r *http.Request) {
+		limit, err := queryLimit(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		sessions, err := db.Sessions(r.Context(), r.PathValue("imei"), limit)
+		writeJson(w, sessions, err)
+	})
+
+	mux.HandleFunc("GET /api/positions/latest", func(w http.ResponseWriter, r *http.Request) {
+		positions, err := db.LatestPositions(r.Context())
+		writeJson(w, positions, err)
+	})
+
+	return mux
+}
+
+func queryLimit(r *http.Request) (int, error) {
+	v := r.URL.Query().Get("limit")
+	if v == "" {
+		return defaultQueryLimit, nil
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil || limit <= 0 {
+		return 0, errors.New("invalid limit " + v)
+	}
+	return min(limit, maxQueryLimit), nil
+}
+
+func writeJson(w http.ResponseWriter, v any, err error) {
+	if errors.Is(err, ErrDeviceNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	} else if err != nil {
+		logger.Sugar().Errorf("sqlite api query failed: %v", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Sugar().Errorf("failed to write sqlite api response: %v", err)
+	}
+}
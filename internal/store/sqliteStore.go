@@ -0,0 +1,420 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/types"
+	_ "github.com/mattn/go-sqlite3"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var ErrDeviceNotFound = errors.New("device not found")
+
+var sqliteSchema = []string{
+	`CREATE TABLE IF NOT EXISTS devices (
+		imei        TEXT    PRIMARY KEY,
+		device_type TEXT    NOT NULL,
+		name        TEXT    NOT NULL DEFAULT '',
+		created_at  INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS positions (
+		id           INTEGER PRIMARY KEY AUTOINCREMENT,
+		imei         TEXT    NOT NULL,
+		device_type  TEXT    NOT NULL,
+		ts           INTEGER NOT NULL,
+		received_at  INTEGER NOT NULL,
+		message_type TEXT    NOT NULL,
+		latitude     REAL,
+		longitude    REAL,
+		speed        REAL,
+		course       REAL,
+		ignition     INTEGER,
+		status       TEXT    NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS positions_imei_ts_idx ON positions (imei, ts)`,
+	`CREATE TABLE IF NOT EXISTS responses (
+		id          INTEGER PRIMARY KEY AUTOINCREMENT,
+		imei        TEXT    NOT NULL,
+		received_at INTEGER NOT NULL,
+		response    TEXT    NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS responses_imei_received_at_idx ON responses (imei, received_at)`,
+	`CREATE TABLE IF NOT EXISTS device_sessions (
+		id              INTEGER PRIMARY KEY AUTOINCREMENT,
+		imei            TEXT    NOT NULL,
+		device_type     TEXT    NOT NULL,
+		protocol        TEXT    NOT NULL,
+		connected_at    INTEGER NOT NULL,
+		disconnected_at INTEGER,
+		records         INTEGER NOT NULL DEFAULT 0
+	)`,
+	`CREATE INDEX IF NOT EXISTS device_sessions_imei_connected_at_idx ON device_sessions (imei, connected_at)`,
+}
+
+// times are stored as unix milliseconds
+func toMillis(t time.Time) int64 {
+	return t.UnixMilli()
+}
+
+func fromMillis(ms int64) time.Time {
+	return time.UnixMilli(ms).UTC()
+}
+
+type Device struct {
+	Imei       string           `json:"imei"`
+	DeviceType types.DeviceType `json:"-"`
+	Name       string           `json:"name"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+func (d Device) MarshalJSON() ([]byte, error) {
+	type device Device
+	return json.Marshal(struct {
+		device
+		DeviceType string `json:"device_type"`
+	}{device(d), d.DeviceType.String()})
+}
+
+type StoredPosition struct {
+	Imei        string          `json:"imei"`
+	Timestamp   time.Time       `json:"timestamp"`
+	ReceivedAt  time.Time       `json:"received_at"`
+	MessageType string          `json:"message_type"`
+	Latitude    *float64        `json:"latitude,omitempty"`
+	Longitude   *float64        `json:"longitude,omitempty"`
+	Speed       *float64        `json:"speed,omitempty"`
+	Course      *float64        `json:"course,omitempty"`
+	Ignition    *bool           `json:"ignition,omitempty"`
+	Status      json.RawMessage `json:"status"` // the full DeviceStatus as protojson
+}
+
+type StoredResponse struct {
+	Imei       string    `json:"imei"`
+	ReceivedAt time.Time `json:"received_at"`
+	Response   string    `json:"response"`
+}
+
+type DeviceSession struct {
+	Id             int64      `json:"id"`
+	Imei           string     `json:"imei"`
+	DeviceType     string     `json:"device_type"`
+	Protocol       string     `json:"protocol"`
+	ConnectedAt    time.Time  `json:"connected_at"`
+	DisconnectedAt *time.Time `json:"disconnected_at,omitempty"`
+	Records        int64      `json:"records"`
+}
+
+// SqliteDB is the embedded database shared by all the per-connection SqliteStores,
+// it also keeps the device registry used to verify devices when there is no remote store
+type SqliteDB struct {
+	DB           *sql.DB
+	AutoRegister bool // accept unknown devices on login and add them to the registry
+}
+
+func OpenSqlite(path string) (*SqliteDB, error) {
+	// WAL lets the query api read while connections are writing
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_journal_mode=WAL&_busy_timeout=5000&_synchronous=NORMAL", path))
+	if err != nil {
+		return nil, err
+	}
+	for _, statement := range sqliteSchema {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return &SqliteDB{DB: db}, nil
+}
+
+func (s *SqliteDB) Ping(ctx context.Context) error {
+	return s.DB.PingContext(ctx)
+}
+
+func (s *SqliteDB) Close() error {
+	return s.DB.Close()
+}
+
+func (s *SqliteDB) InsertDeviceStatus(ctx context.Context, status *types.DeviceStatus, receivedAt time.Time) error {
+	statusJson, err := protojson.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	ts := receivedAt
+	if status.Timestamp != nil {
+		ts = status.Timestamp.AsTime()
+	}
+
+	var latitude, longitude, speed, course, ignition any
+	if p := status.Position; p != nil {
+		latitude, longitude, course = float64(p.Latitude), float64(p.Longitude), float64(p.Course)
+		if p.Speed != nil {
+			speed = float64(*p.Speed)
+		}
+	}
+	if vs := status.VehicleStatus; vs != nil && vs.Ignition != nil {
+		ignition = *vs.Ignition
+	}
+
+	_, err = s.DB.ExecContext(ctx,
+		`INSERT INTO positions (imei, device_type, ts, received_at, message_type, latitude, longitude, speed, course, ignition, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		status.Imei, status.DeviceType.String(), toMillis(ts), toMillis(receivedAt), status.MessageType,
+		latitude, longitude, speed, course, ignition, string(statusJson))
+	return err
+}
+
+func (s *SqliteDB) InsertDeviceResponse(ctx context.Context, response *types.DeviceResponse, receivedAt time.Time) error {
+	_, err := s.DB.ExecContext(ctx, "INSERT INTO responses (imei, received_at, response) VALUES (?, ?, ?)",
+		response.Imei, toMillis(receivedAt), response.Response)
+	return err
+}
+
+func (s *SqliteDB) StartSession(ctx context.Context, imei string, deviceType types.DeviceType, protocol types.DeviceProtocolType, connectedAt time.Time) (int64, error) {
+	result, err := s.DB.ExecContext(ctx, "INSERT INTO device_sessions (imei, device_type, protocol, connected_at) VALUES (?, ?, ?, ?)",
+		imei, deviceType.String(), protocol.String(), toMillis(connectedAt))
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+func (s *SqliteDB) EndSession(ctx context.Context, sessionId int64, disconnectedAt time.Time, records int64) error {
+	_, err := s.DB.ExecContext(ctx, "UPDATE device_sessions SET disconnected_at = ?, records = ? WHERE id = ?",
+		toMillis(disconnectedAt), records, sessionId)
+	return err
+}
+
+func (s *SqliteDB) GetDevice(ctx context.Context, imei string) (Device, error) {
+	var device Device
+	var deviceType string
+	var createdAt int64
+	err := s.DB.QueryRowContext(ctx, "SELECT imei, device_type, name, created_at FROM devices WHERE imei = ?", imei).
+		Scan(&device.Imei, &deviceType, &device.Name, &createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return device, ErrDeviceNotFound
+	} else if err != nil {
+		return device, err
+	}
+	device.DeviceType = types.DeviceType(types.DeviceType_value[deviceType])
+	device.CreatedAt = fromMillis(createdAt)
+	return device, nil
+}
+
+func (s *SqliteDB) ListDevices(ctx context.Context) ([]Device, error) {
+	rows, err := s.DB.QueryContext(ctx, "SELECT imei, device_type, name, created_at FROM devices ORDER BY imei")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	devices := []Device{}
+	for rows.Next() {
+		var device Device
+		var deviceType string
+		var createdAt int64
+		if err := rows.Scan(&device.Imei, &deviceType, &device.Name, &createdAt); err != nil {
+			return nil, err
+		}
+		device.DeviceType = types.DeviceType(types.DeviceType_value[deviceType])
+		device.CreatedAt = fromMillis(createdAt)
+		devices = append(devices, device)
+	}
+	return devices, rows.Err()
+}
+
+// UpsertDevice registers the device or updates its type and name
+func (s *SqliteDB) UpsertDevice(ctx context.Context, imei string, deviceType types.DeviceType, name string) error {
+	_, err := s.DB.ExecContext(ctx,
+		`INSERT INTO devices (imei, device_type, name, created_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (imei) DO UPDATE SET device_type = excluded.device_type, name = excluded.name`,
+		imei, deviceType.String(), name, toMillis(time.Now()))
+	return err
+}
+
+func (s *SqliteDB) DeleteDevice(ctx context.Context, imei string) error {
+	result, err := s.DB.ExecContext(ctx, "DELETE FROM devices WHERE imei = ?", imei)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrDeviceNotFound
+	}
+	return nil
+}
+
+func scanPositions(rows *sql.Rows) ([]StoredPosition, error) {
+	defer rows.Close()
+
+	positions := []StoredPosition{}
+	for rows.Next() {
+		var p StoredPosition
+		var ts, receivedAt int64
+		var status string
+		if err := rows.Scan(&p.Imei, &ts, &receivedAt, &p.MessageType, &p.Latitude, &p.Longitude, &p.Speed, &p.Course, &p.Ignition, &status); err != nil {
+			return nil, err
+		}
+		p.Timestamp, p.ReceivedAt = fromMillis(ts), fromMillis(receivedAt)
+		p.Status = json.RawMessage(status)
+		positions = append(positions, p)
+	}
+	return positions, rows.Err()
+}
+
+const positionColumns = "imei, ts, received_at, message_type, latitude, longitude, speed, course, ignition, status"
+
+// Positions returns the device's records between from and to, oldest first
+func (s *SqliteDB) Positions(ctx context.Context, imei string, from time.Time, to time.Time, limit int) ([]StoredPosition, error) {
+	rows, err := s.DB.QueryContext(ctx,
+		"SELECT "+positionColumns+" FROM positions WHERE imei = ? AND ts >= ? AND ts <= ? ORDER BY ts LIMIT ?",
+		imei, toMillis(from), toMillis(to), limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanPositions(rows)
+}
+
+// LatestPositions returns the most recent record of every device that has a position
+func (s *SqliteDB) LatestPositions(ctx context.Context) ([]StoredPosition, error) {
+	rows, err := s.DB.QueryContext(ctx,
+		"SELECT "+positionColumns+` FROM positions p WHERE id = (
+			SELECT id FROM positions WHERE imei = p.imei AND latitude IS NOT NULL ORDER BY ts DESC, id DESC LIMIT 1
+		) ORDER BY imei`)
+	if err != nil {
+		return nil, err
+	}
+	return scanPositions(rows)
+}
+
+func (s *SqliteDB) Responses(ctx context.Context, imei string, limit int) ([]StoredResponse, error) {
+	rows, err := s.DB.QueryContext(ctx,
+		"SELECT imei, received_at, response FROM responses WHERE imei = ? ORDER BY received_at DESC, id DESC LIMIT ?", imei, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	responses := []StoredResponse{}
+	for rows.Next() {
+		var r StoredResponse
+		var receivedAt int64
+		if err := rows.Scan(&r.Imei, &receivedAt, &r.Response); err != nil {
+			return nil, err
+		}
+		r.ReceivedAt = fromMillis(receivedAt)
+		responses = append(responses, r)
+	}
+	return responses, rows.Err()
+}
+
+func (s *SqliteDB) Sessions(ctx context.Context, imei string, limit int) ([]DeviceSession, error) {
+	rows, err := s.DB.QueryContext(ctx,
+		`SELECT id, imei, device_type, protocol, connected_at, disconnected_at, records FROM device_sessions
+		WHERE imei = ? ORDER BY connected_at DESC, id DESC LIMIT ?`, imei, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []DeviceSession{}
+	for rows.Next() {
+		var session DeviceSession
+		var connectedAt int64
+		var disconnectedAt sql.NullInt64
+		if err := rows.Scan(&session.Id, &session.Imei, &session.DeviceType, &session.Protocol, &connectedAt, &disconnectedAt, &session.Records); err != nil {
+			return nil, err
+		}
+		session.ConnectedAt = fromMillis(connectedAt)
+		if disconnectedAt.Valid {
+			t := fromMillis(disconnectedAt.Int64)
+			session.DisconnectedAt = &t
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// SqliteStore writes a device's records and keeps a session row open for as long as the connection lives
+type SqliteStore struct {
+	ProcessChan       chan *types.DeviceStatus
+	ResponseChan      chan *types.DeviceResponse
+	CloseChan         chan bool
+	CloseResponseChan chan bool
+	DB                *SqliteDB
+	Imei              string
+	DeviceType        types.DeviceType
+	Protocol          types.DeviceProtocolType
+
+	records atomic.Int64
+}
+
+func (s *SqliteStore) GetProcessChan() chan *types.DeviceStatus {
+	return s.ProcessChan
+}
+
+func (s *SqliteStore) GetResponseChan() chan *types.DeviceResponse {
+	return s.ResponseChan
+}
+
+func (s *SqliteStore) GetCloseChan() chan bool {
+	return s.CloseChan
+}
+
+func (s *SqliteStore) GetCloseResponseChan() chan bool {
+	return s.CloseResponseChan
+}
+
+func (s *SqliteStore) Process(ctx context.Context) {
+	sessionId, err := s.DB.StartSession(ctx, s.Imei, s.DeviceType, s.Protocol, time.Now())
+	if err != nil {
+		logger.Error("failed to record device session", zap.String("imei", s.Imei), zap.Error(err))
+	}
+	defer func() {
+		if sessionId == 0 {
+			return
+		}
+		// the connection context may already be cancelled
+		if err := s.DB.EndSession(context.Background(), sessionId, time.Now(), s.records.Load()); err != nil {
+			logger.Error("failed to close device session", zap.String("imei", s.Imei), zap.Error(err))
+		}
+	}()
+
+	for {
+		select {
+		case deviceStatus := <-s.ProcessChan:
+			if err := timeStoreCall("sqlite", opStatus, func() error { return s.DB.InsertDeviceStatus(ctx, deviceStatus, time.Now()) }); err != nil {
+				logger.Error("failed to write device status to sqlite", zap.String("imei", deviceStatus.Imei), zap.Error(err))
+				continue
+			}
+			s.records.Add(1)
+		case <-s.CloseChan:
+			logger.Sugar().Info("async sqlite store shutting down for device")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *SqliteStore) Response(ctx context.Context) {
+	for {
+		select {
+		case deviceResponse := <-s.ResponseChan:
+			if err := timeStoreCall("sqlite", opResponse, func() error { return s.DB.InsertDeviceResponse(ctx, deviceResponse, time.Now()) }); err != nil {
+				logger.Error("failed to write device response to sqlite", zap.String("imei", deviceResponse.Imei), zap.Error(err))
+			}
+		case <-s.CloseResponseChan:
+			logger.Sugar().Info("async sqlite store shutting down for device")
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
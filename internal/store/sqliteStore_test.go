@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func openTestSqlite(t *testing.T) *SqliteDB {
+	db, err := OpenSqlite(filepath.Join(t.TempDir(), "avl.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestSqliteStoreRecordsPositionsAndSession(t *testing.T) {
+	db := openTestSqlite(t)
+	s := &SqliteStore{
+		ProcessChan:       make(chan *types.DeviceStatus, 10),
+		ResponseChan:      make(chan *types.DeviceResponse, 10),
+		CloseChan:         make(chan bool, 1),
+		CloseResponseChan: make(chan bool, 1),
+		DB:                db,
+		Imei:              "350424063817363",
+		DeviceType:        types.DeviceType_TELTONIKA,
+		Protocol:          types.DeviceProtocolType_FM1200,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.Process(context.Background())
+		close(done)
+	}()
+	go s.Response(context.Background())
+
+	start := time.Now().Add(-time.Minute).Truncate(time.Millisecond)
+	speed := float32(61)
+	for i := 0; i < 3; i++ {
+		s.ProcessChan <- &types.DeviceStatus{
+			Imei:       s.Imei,
+			DeviceType: s.DeviceType,
+			Timestamp:  timestamppb.New(start.Add(time.Duration(i) * time.Second)),
+			Position:   &types.GPSPosition{Latitude: 28.6, Longitude: 77.2 + float32(i), Speed: &speed},
+		}
+	}
+	s.ResponseChan <- &types.DeviceResponse{Imei: s.Imei, Response: "DOUT1:1 Timeout:30s"}
+
+	ctx := context.Background()
+	require.Eventually(t, func() bool {
+		positions, _ := db.Positions(ctx, s.Imei, start, time.Now(), 10)
+		responses, _ := db.Responses(ctx, s.Imei, 10)
+		return len(positions) == 3 && len(responses) == 1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	s.CloseChan <- true
+	s.CloseResponseChan <- true
+	<-done
+
+	positions, err := db.Positions(ctx, s.Imei, start, time.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, start, positions[0].Timestamp.Local())
+	assert.InDelta(t, 79.2, *positions[2].Longitude, 0.001)
+	assert.InDelta(t, 61, *positions[2].Speed, 0.001)
+	assert.Contains(t, string(positions[0].Status), `"imei":"350424063817363"`)
+
+	latest, err := db.LatestPositions(ctx)
+	require.NoError(t, err)
+	require.Len(t, latest, 1)
+	assert.InDelta(t, 79.2, *latest[0].Longitude, 0.001)
+
+	sessions, err := db.Sessions(ctx, s.Imei, 10)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "FM1200", sessions[0].Protocol)
+	assert.Equal(t, int64(3), sessions[0].Records)
+	assert.NotNil(t, sessions[0].DisconnectedAt)
+}
+
+func TestSqliteDevices(t *testing.T) {
+	db := openTestSqlite(t)
+	ctx := context.Background()
+
+	_, err := db.GetDevice(ctx, "350424063817363")
+	assert.ErrorIs(t, err, ErrDeviceNotFound)
+
+	require.NoError(t, db.UpsertDevice(ctx, "350424063817363", types.DeviceType_TELTONIKA, "truck 12"))
+	require.NoError(t, db.UpsertDevice(ctx, "350424063817363", types.DeviceType_TELTONIKA, "truck 14"))
+	device, err := db.GetDevice(ctx, "350424063817363")
+	require.NoError(t, err)
+	assert.Equal(t, types.DeviceType_TELTONIKA, device.DeviceType)
+	assert.Equal(t, "truck 14", device.Name)
+
+	require.NoError(t, db.DeleteDevice(ctx, "350424063817363"))
+	assert.ErrorIs(t, db.DeleteDevice(ctx, "350424063817363"), ErrDeviceNotFound)
+}
+
+func TestSqliteApi(t *testing.T) {
+	db := openTestSqlite(t)
+	server := httptest.NewServer(NewSqliteApiHandler(db))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodPut, server.URL+"/api/devices/350424063817363", strings.NewReader(`{"device_type":"TELTONIKA","name":"truck 12"}`))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodPut, server.URL+"/api/devices/350424063817363", strings.NewReader(`{"device_type":"NOPE"}`))
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/api/devices")
+	require.NoError(t, err)
+	var devices []map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&devices))
+	resp.Body.Close()
+	require.Len(t, devices, 1)
+	assert.Equal(t, "TELTONIKA", devices[0]["device_type"])
+	assert.Equal(t, "truck 12", devices[0]["name"])
+
+	resp, err = http.Get(server.URL + "/api/devices/000/positions?from=yesterday")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + "/api/devices/000")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+
+	"github.com/404minds/avl-receiver/internal/types"
+)
+
+// TapStore shows every device response to OnResponse before handing it to the wrapped store,
+// the tcp handler uses it to match device replies to the commands waiting for them.
+// With a ProcessChan set, device statuses are shown to OnStatus the same way, e.g. for the live feed,
+// unless Filter drops them
+type TapStore struct {
+	Store             Store
+	ProcessChan       chan *types.DeviceStatus
+	CloseChan         chan bool
+	ResponseChan      chan *types.DeviceResponse
+	CloseResponseChan chan bool
+	Filter            func(*types.DeviceStatus) bool // false drops the status, nil keeps them all
+	OnStatus          func(*types.DeviceStatus)
+	OnResponse        func(*types.DeviceResponse)
+}
+
+func (s *TapStore) GetProcessChan() chan *types.DeviceStatus {
+	if s.ProcessChan == nil {
+		return s.Store.GetProcessChan()
+	}
+	return s.ProcessChan
+}
+
+func (s *TapStore) GetResponseChan() chan *types.DeviceResponse {
+	return s.ResponseChan
+}
+
+func (s *TapStore) GetCloseChan() chan bool {
+	if s.CloseChan == nil {
+		return s.Store.GetCloseChan()
+	}
+	return s.CloseChan
+}
+
+func (s *TapStore) GetCloseResponseChan() chan bool {
+	return s.CloseResponseChan
+}
+
+func (s *TapStore) Process(ctx context.Context) {
+	if s.ProcessChan == nil {
+		s.Store.Process(ctx)
+		return
+	}
+	go s.Store.Process(ctx)
+
+	for {
+		select {
+		case deviceStatus := <-s.ProcessChan:
+			if s.Filter != nil && !s.Filter(deviceStatus) {
+				continue
+			}
+			s.OnStatus(deviceStatus)
+			select {
+			case s.Store.GetProcessChan() <- deviceStatus:
+			case <-ctx.Done():
+				return
+			}
+		case <-s.CloseChan:
+			s.Store.GetCloseChan() <- true
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *TapStore) Response(ctx context.Context) {
+	go s.Store.Response(ctx)
+
+	for {
+		select {
+		case deviceResponse := <-s.ResponseChan:
+			if s.OnResponse != nil {
+				s.OnResponse(deviceResponse)
+			}
+			select {
+			case s.Store.GetResponseChan() <- deviceResponse:
+			case <-ctx.Done():
+				return
+			}
+		case <-s.CloseResponseChan:
+			s.Store.GetCloseResponseChan() <- true
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
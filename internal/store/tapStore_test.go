@@ -0,0 +1,65 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/404minds/avl-receiver/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTapStoreShowsStatuses(t *testing.T) {
+	inner := &MultiStore{
+		ProcessChan: make(chan *types.DeviceStatus, 1),
+		CloseChan:   make(chan bool, 1),
+	}
+	seen := make(chan *types.DeviceStatus, 1)
+	tap := &TapStore{
+		Store:       inner,
+		ProcessChan: make(chan *types.DeviceStatus, 1),
+		CloseChan:   make(chan bool, 1),
+		OnStatus:    func(s *types.DeviceStatus) { seen <- s },
+	}
+	done := make(chan bool)
+	go func() {
+		tap.Process(context.Background())
+		close(done)
+	}()
+
+	deviceStatus := &types.DeviceStatus{Imei: "861234567890123"}
+	tap.GetProcessChan() <- deviceStatus
+	assert.Same(t, deviceStatus, <-seen)
+
+	tap.GetCloseChan() <- true
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		require.Fail(t, "tap store didn't stop on close")
+	}
+}
+
+func TestTapStoreFilterDropsStatuses(t *testing.T) {
+	inner := &MultiStore{
+		ProcessChan: make(chan *types.DeviceStatus, 2),
+		CloseChan:   make(chan bool, 1),
+	}
+	seen := make(chan *types.DeviceStatus, 2)
+	tap := &TapStore{
+		Store:       inner,
+		ProcessChan: make(chan *types.DeviceStatus, 2),
+		CloseChan:   make(chan bool, 1),
+		Filter:      func(s *types.DeviceStatus) bool { return s.Imei != "dropped" },
+		OnStatus:    func(s *types.DeviceStatus) { seen <- s },
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go tap.Process(ctx)
+
+	tap.GetProcessChan() <- &types.DeviceStatus{Imei: "dropped"}
+	kept := &types.DeviceStatus{Imei: "861234567890123"}
+	tap.GetProcessChan() <- kept
+	assert.Same(t, kept, <-seen, "the dropped status isn't shown")
+	assert.Empty(t, seen)
+}